@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"mamonolitmvp/config"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/internal/repository"
+	"mamonolitmvp/internal/services"
+	"mamonolitmvp/internal/storage/timescale"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// Прогон стратегии по свечам из базы, недостающие свечи докачиваются из Tinkoff:
+// go run ./cmd/backtest -instrument <uid> -from 2024-01-01T00:00:00Z -to 2025-01-01T00:00:00Z
+func main() {
+	var req models.BacktestRequest
+	flag.StringVar(&req.InstrumentId, "instrument", "", "instrument uid")
+	flag.StringVar(&req.Interval, "interval", "CANDLE_INTERVAL_DAY", "candle interval")
+	flag.StringVar(&req.From, "from", "", "start of the period, RFC 3339")
+	flag.StringVar(&req.To, "to", time.Now().UTC().Format(time.RFC3339), "end of the period, RFC 3339")
+	flag.Float64Var(&req.InitialCash, "cash", 0, "initial cash, 0 for the default")
+	flag.Float64Var(&req.Commission, "commission", 0.0005, "commission as a fraction of turnover")
+	flag.IntVar(&req.SlippageTicks, "slippage", 1, "slippage in price increments")
+	flag.IntVar(&req.Lookback, "lookback", 0, "candles per signal, 0 for the default")
+	flag.IntVar(&req.Step, "step", 0, "recalculate the signal every step candles, 0 for every candle")
+	flag.Float64Var(&req.TrendThreshold, "trend", 0, "trend factor threshold, 0 for the default")
+	flag.Float64Var(&req.RSIOverbought, "rsi-overbought", 0, "RSI level to exit, 0 for the default")
+	flag.Float64Var(&req.MinHurst, "min-hurst", 0, "minimum Hurst exponent to enter, 0 for the default")
+	flag.Float64Var(&req.RiskFreeRate, "risk-free", 0, "annual risk-free rate")
+	output := flag.String("json", "", "write the full result with equity curve to this file")
+	flag.Parse()
+
+	if err := req.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.LoadConfig()
+	db, err := timescale.InitDB(cfg.PostgresHost, cfg.PostgresUser, cfg.PostgresPassword, cfg.PostgresDatabase, cfg.PostgresPort)
+	if err != nil {
+		log.Fatal(err)
+	}
+	service := services.NewTinkoffService(cfg, repository.NewInstrumentRepository(db))
+
+	resp, err := service.RunBacktest(ctx, req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	printResult(resp)
+
+	if *output != "" {
+		data, err := json.MarshalIndent(resp, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*output, data, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func printResult(resp models.BacktestResponse) {
+	s := resp.Summary
+	fmt.Printf("%s %s, lot %d, price increment %v\n", resp.Ticker, resp.Interval, resp.Lot, resp.MinPriceIncrement)
+	fmt.Printf("bars %d, signals %d, exposure %.1f%%\n", s.Bars, s.Signals, s.Exposure*100)
+	fmt.Printf("equity %.2f -> %.2f (%+.2f%%), commission %.2f\n", s.InitialCash, s.FinalEquity, s.TotalReturn*100, s.Commission)
+	fmt.Printf("trades %d, win rate %.1f%%, profit factor %.2f, avg return %+.2f%%\n", s.Trades, s.WinRate*100, s.ProfitFactor, s.AvgReturn*100)
+	fmt.Printf("max drawdown %.2f%%, sharpe %.2f, sortino %.2f, VaR %.4f, ES %.4f\n\n",
+		resp.Risk.MaxDrawdown*100, resp.Risk.Sharpe, resp.Risk.Sortino, resp.Risk.HistoricalVaR, resp.Risk.HistoricalES)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENTRY\tPRICE\tEXIT\tPRICE\tLOTS\tPNL\tRETURN\tEXIT REASON")
+	for _, t := range resp.Trades {
+		fmt.Fprintf(w, "%s\t%.4f\t%s\t%.4f\t%d\t%.2f\t%+.2f%%\t%s\n",
+			t.EntryTime.Format(time.DateTime), t.EntryPrice, t.ExitTime.Format(time.DateTime), t.ExitPrice,
+			t.Lots, t.PnL, t.Return*100, t.ExitReason)
+	}
+	w.Flush()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"mamonolitmvp/internal/fakesink"
+	"net/http"
+)
+
+// Локальные получатели оповещений: webhook на http://127.0.0.1:8091/... (с ALERT_WEBHOOK_ALLOW=127.0.0.1),
+// TELEGRAM_API_URL=http://localhost:8092, SMTP_ADDR=localhost:2525
+func main() {
+	webhookAddr := flag.String("webhook", ":8091", "webhook listen address")
+	telegramAddr := flag.String("telegram", ":8092", "Telegram Bot API listen address")
+	smtpAddr := flag.String("smtp", ":2525", "SMTP listen address")
+	token := flag.String("token", "", "required Telegram bot token, empty to accept any")
+	smtpUser := flag.String("smtp-user", "", "required SMTP username, empty to accept mail without AUTH")
+	smtpPassword := flag.String("smtp-password", "", "required SMTP password")
+	flag.Parse()
+
+	logReceived := func(r fakesink.Received) {
+		log.Printf("%s -> %s: %s\n%s", r.Sink, r.To, r.Subject, r.Text)
+	}
+
+	webhook := fakesink.NewWebhook()
+	webhook.OnReceive = logReceived
+	telegram := fakesink.NewTelegram(*token)
+	telegram.OnReceive = logReceived
+	smtp := fakesink.NewSMTP()
+	smtp.Username = *smtpUser
+	smtp.Password = *smtpPassword
+	smtp.OnReceive = logReceived
+
+	errs := make(chan error, 3)
+	go func() { errs <- http.ListenAndServe(*webhookAddr, webhook) }()
+	go func() { errs <- http.ListenAndServe(*telegramAddr, telegram) }()
+	go func() { errs <- smtp.ListenAndServe(*smtpAddr) }()
+
+	log.Printf("Fake alert sinks are running: webhook %s, telegram %s, smtp %s...", *webhookAddr, *telegramAddr, *smtpAddr)
+	log.Fatal(<-errs)
+}
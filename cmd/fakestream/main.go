@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"mamonolitmvp/internal/fakestream"
+	"net/http"
+	"time"
+)
+
+// Локальный поток рыночных данных: TINKOFF_STREAM_URL=http://localhost:8090
+func main() {
+	addr := flag.String("addr", ":8090", "listen address")
+	tick := flag.Duration("tick", time.Second, "period between market data messages")
+	dropAfter := flag.Int("drop-after", 0, "close each stream after this many messages, 0 to keep it open")
+	token := flag.String("token", "", "required bearer token, empty to accept any")
+	flag.Parse()
+
+	server := fakestream.NewServer()
+	server.Tick = *tick
+	server.DropAfter = *dropAfter
+	server.Token = *token
+
+	log.Printf("Fake market data stream is running on %s...", *addr)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/joho/godotenv"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -12,13 +14,15 @@ const (
 	demoEnv       = "demo"
 )
 
-func init() {
+// loadEnvFile подгружает .env вне production и demo. Файла может не быть: тогда настройки берутся
+// из окружения и умолчаний LoadConfig.
+func loadEnvFile() {
 	env := os.Getenv("ENV")
-	if env != productionEnv && env != demoEnv {
-		err := godotenv.Load(envFilename)
-		if err != nil {
-			log.Fatal("Error loading .env file")
-		}
+	if env == productionEnv || env == demoEnv {
+		return
+	}
+	if err := godotenv.Load(envFilename); err != nil {
+		log.Printf("%s is not loaded, using environment: %v", envFilename, err)
 	}
 }
 
@@ -35,10 +39,51 @@ type Config struct {
 
 	//ShortSmaInterval int
 	//LongSmaInterval  int
-	//RSIInterval      int
+	RSIInterval int
+
+	// Повторы и таймаут одной попытки запроса к Tinkoff, лимит запросов в минуту на метод API
+	HTTPMaxRetries     int
+	HTTPTimeoutSeconds int
+	TinkoffRateLimit   int
+
+	// Планировщик: расписание обновления каталога (cron, UTC), список инструментов и интервалов
+	// для догрузки свечей по закрытию интервала, максимальный джиттер запуска в секундах
+	SchedulerEnabled   bool
+	CatalogueSyncCron  string
+	Watchlist          []string
+	WatchlistIntervals []string
+	JobJitterSeconds   int
+
+	// Проверка правил стратегий по watchlist: расписание (cron, UTC) и интервал свечей
+	RulesCron     string
+	RulesInterval string
+
+	// Поток рыночных данных по watchlist: адрес REST API с MarketDataStreamService (по умолчанию
+	// TINKOFF_API_BASE_URL), интервал свечей и глубина стакана
+	StreamEnabled        bool
+	StreamURL            string
+	StreamCandleInterval string
+	StreamOrderBookDepth int
+
+	// Скринер: число инструментов, сигналы по которым считаются одновременно
+	ScreenerWorkers int
+
+	// Оповещения: проверка при сохранении новых свечей и каналы доставки. Telegram включается токеном бота,
+	// SMTP — адресом сервера host:port; webhook доступен всегда, но только на публичные адреса
+	// и на хосты и подсети из AlertWebhookAllow.
+	AlertsEnabled     bool
+	AlertWebhookAllow []string
+	TelegramBotToken  string
+	TelegramAPIURL    string
+	SMTPAddr          string
+	SMTPFrom          string
+	SMTPUsername      string
+	SMTPPassword      string
 }
 
 func LoadConfig() *Config {
+	loadEnvFile()
+
 	return &Config{
 		APIBaseURL: os.Getenv("TINKOFF_API_BASE_URL"),
 		APIToken:   os.Getenv("TINKOFF_API_TOKEN"),
@@ -49,5 +94,76 @@ func LoadConfig() *Config {
 		PostgresPassword: os.Getenv("POSTGRES_PASSWORD"),
 		PostgresUser:     os.Getenv("POSTGRES_USER"),
 		PostgresDatabase: os.Getenv("POSTGRES_DATABASE"),
+
+		RSIInterval: getEnvInt("RSI_INTERVAL", 14),
+
+		HTTPMaxRetries:     getEnvNonNegativeInt("HTTP_MAX_RETRIES", 3),
+		HTTPTimeoutSeconds: getEnvInt("HTTP_TIMEOUT_SECONDS", 10),
+		TinkoffRateLimit:   getEnvInt("TINKOFF_RATE_LIMIT_PER_MINUTE", 100),
+
+		SchedulerEnabled:   os.Getenv("SCHEDULER_ENABLED") != "false",
+		CatalogueSyncCron:  getEnvString("CATALOGUE_SYNC_CRON", "0 0 * * *"),
+		Watchlist:          getEnvList("WATCHLIST", nil),
+		WatchlistIntervals: getEnvList("WATCHLIST_INTERVALS", []string{"CANDLE_INTERVAL_DAY"}),
+		JobJitterSeconds:   getEnvInt("JOB_JITTER_SECONDS", 60),
+
+		RulesCron:     getEnvString("RULES_CRON", "0 * * * *"),
+		RulesInterval: getEnvString("RULES_INTERVAL", "CANDLE_INTERVAL_DAY"),
+
+		StreamEnabled:        os.Getenv("STREAM_ENABLED") == "true",
+		StreamURL:            getEnvString("TINKOFF_STREAM_URL", os.Getenv("TINKOFF_API_BASE_URL")),
+		StreamCandleInterval: getEnvString("STREAM_CANDLE_INTERVAL", "CANDLE_INTERVAL_1_MIN"),
+		StreamOrderBookDepth: getEnvInt("STREAM_ORDERBOOK_DEPTH", 10),
+
+		ScreenerWorkers: getEnvInt("SCREENER_WORKERS", 4),
+
+		AlertsEnabled:     os.Getenv("ALERTS_ENABLED") != "false",
+		AlertWebhookAllow: getEnvList("ALERT_WEBHOOK_ALLOW", nil),
+		TelegramBotToken:  os.Getenv("TELEGRAM_BOT_TOKEN"),
+		TelegramAPIURL:    getEnvString("TELEGRAM_API_URL", "https://api.telegram.org"),
+		SMTPAddr:          os.Getenv("SMTP_ADDR"),
+		SMTPFrom:          os.Getenv("SMTP_FROM"),
+		SMTPUsername:      os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:      os.Getenv("SMTP_PASSWORD"),
+	}
+}
+
+func getEnvString(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList значения через запятую без пустых элементов
+func getEnvList(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
+
+// getEnvNonNegativeInt как getEnvInt, но 0 — допустимое значение, например HTTP_MAX_RETRIES=0 отключает повторы
+func getEnvNonNegativeInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvInt положительное число; пустое, нечисловое, нулевое или отрицательное значение заменяется defaultValue
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
 	}
+	return value
 }
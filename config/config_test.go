@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+func TestHTTPMaxRetries(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "", want: 3},
+		{value: "0", want: 0},
+		{value: "5", want: 5},
+		{value: "-1", want: 3},
+		{value: "many", want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("HTTP_MAX_RETRIES", tt.value)
+			if got := LoadConfig().HTTPMaxRetries; got != tt.want {
+				t.Errorf("HTTPMaxRetries = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEnvIntTreatsZeroAsUnset(t *testing.T) {
+	t.Setenv("SCREENER_WORKERS", "0")
+	if got := LoadConfig().ScreenerWorkers; got != 4 {
+		t.Errorf("ScreenerWorkers = %d, want default 4", got)
+	}
+}
+
+func TestHTTPTimeoutSeconds(t *testing.T) {
+	t.Setenv("HTTP_TIMEOUT_SECONDS", "")
+	if got := LoadConfig().HTTPTimeoutSeconds; got != 10 {
+		t.Errorf("HTTPTimeoutSeconds = %d, want default 10", got)
+	}
+	t.Setenv("HTTP_TIMEOUT_SECONDS", "30")
+	if got := LoadConfig().HTTPTimeoutSeconds; got != 30 {
+		t.Errorf("HTTPTimeoutSeconds = %d, want 30", got)
+	}
+}
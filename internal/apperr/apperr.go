@@ -0,0 +1,24 @@
+// Package apperr виды ошибок приложения. Их разворачивают ошибки клиента Tinkoff, валидации и сервисов,
+// а обработчики выбирают по ним HTTP статус ответа.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Виды ошибок. Проверять через errors.Is.
+var (
+	ErrInvalidArgument  = errors.New("invalid argument")
+	ErrUnauthenticated  = errors.New("unauthenticated")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrNotFound         = errors.New("not found")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrUnavailable      = errors.New("unavailable")
+	ErrInternal         = errors.New("internal error")
+)
+
+// InvalidArgument ошибка некорректного запроса, разворачивается в ErrInvalidArgument
+func InvalidArgument(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidArgument, fmt.Sprintf(format, args...))
+}
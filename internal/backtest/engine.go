@@ -0,0 +1,332 @@
+// Package backtest прогоняет стратегию по сохраненным свечам бар за баром: сигнал считается только
+// по закрытым барам, решение исполняется по открытию следующего бара с комиссией, проскальзыванием
+// и округлением до лота и шага цены.
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"mamonolitmvp/internal/math/coefficients_calculation"
+	"mamonolitmvp/internal/math/price_analysis"
+	"math"
+	"time"
+)
+
+// Bar свеча, по которой идет прогон
+type Bar struct {
+	Time  time.Time
+	Open  float64
+	High  float64
+	Low   float64
+	Close float64
+}
+
+// Config параметры прогона
+type Config struct {
+	InitialCash float64
+	// Lot число бумаг в лоте; сделки идут целыми лотами
+	Lot int
+	// MinPriceIncrement шаг цены; 0 — цена не округляется, проскальзывание тогда задать нельзя
+	MinPriceIncrement float64
+	// Commission доля оборота сделки, например 0.0005
+	Commission float64
+	// SlippageTicks сдвиг цены исполнения против сделки в шагах цены
+	SlippageTicks int
+	// Lookback число последних закрытых баров, по которым считается сигнал
+	Lookback int
+	// Step сигнал пересчитывается каждые Step баров
+	Step int
+
+	Confidence     float64
+	RiskFreeRate   float64
+	PeriodsPerYear float64
+}
+
+func (c Config) validate() error {
+	switch {
+	case c.InitialCash <= 0:
+		return fmt.Errorf("initial cash must be positive: %v", c.InitialCash)
+	case c.Lot <= 0:
+		return fmt.Errorf("lot must be positive: %d", c.Lot)
+	case c.MinPriceIncrement < 0:
+		return fmt.Errorf("min price increment must not be negative: %v", c.MinPriceIncrement)
+	case c.Commission < 0 || c.Commission >= 1:
+		return fmt.Errorf("commission must be in [0, 1): %v", c.Commission)
+	case c.SlippageTicks < 0:
+		return fmt.Errorf("slippage must not be negative: %d", c.SlippageTicks)
+	case c.SlippageTicks > 0 && c.MinPriceIncrement == 0:
+		return fmt.Errorf("slippage of %d ticks needs a min price increment", c.SlippageTicks)
+	case c.Lookback <= 0:
+		return fmt.Errorf("lookback must be positive: %d", c.Lookback)
+	case c.Step <= 0:
+		return fmt.Errorf("step must be positive: %d", c.Step)
+	}
+	return nil
+}
+
+// Trade сделка от входа до выхода. Позиция только длинная.
+type Trade struct {
+	EntryTime   time.Time `json:"entryTime"`
+	EntryPrice  float64   `json:"entryPrice"`
+	EntryReason string    `json:"entryReason"`
+	ExitTime    time.Time `json:"exitTime"`
+	ExitPrice   float64   `json:"exitPrice"`
+	ExitReason  string    `json:"exitReason"`
+	Lots        int       `json:"lots"`
+	Quantity    int       `json:"quantity"`
+	Bars        int       `json:"bars"`
+	Commission  float64   `json:"commission"`
+	// PnL результат сделки с учетом комиссий входа и выхода
+	PnL    float64 `json:"pnl"`
+	Return float64 `json:"return"`
+}
+
+// EquityPoint состояние счета по цене закрытия бара
+type EquityPoint struct {
+	Time     time.Time `json:"time"`
+	Equity   float64   `json:"equity"`
+	Cash     float64   `json:"cash"`
+	Quantity int       `json:"quantity"`
+	Drawdown float64   `json:"drawdown"`
+}
+
+type Summary struct {
+	Bars         int     `json:"bars"`
+	Signals      int     `json:"signals"`
+	InitialCash  float64 `json:"initialCash"`
+	FinalEquity  float64 `json:"finalEquity"`
+	TotalReturn  float64 `json:"totalReturn"`
+	Trades       int     `json:"trades"`
+	WinRate      float64 `json:"winRate"`
+	ProfitFactor float64 `json:"profitFactor"`
+	AvgReturn    float64 `json:"avgReturn"`
+	Commission   float64 `json:"commission"`
+	// Exposure доля баров, закрытых с открытой позицией
+	Exposure float64 `json:"exposure"`
+	// Skipped решения о покупке, на которые не хватило денег даже на один лот
+	Skipped int `json:"skipped"`
+}
+
+type Result struct {
+	Summary Summary
+	Risk    coefficients_calculation.Risk
+	Equity  []EquityPoint
+	Trades  []Trade
+}
+
+type Engine struct {
+	pa *price_analysis.PriceAnalysis
+	rm *coefficients_calculation.RiskMetrics
+}
+
+func NewEngine(pa *price_analysis.PriceAnalysis, rm *coefficients_calculation.RiskMetrics) *Engine {
+	return &Engine{
+		pa: pa,
+		rm: rm,
+	}
+}
+
+// account деньги и открытая позиция
+type account struct {
+	cfg    Config
+	cash   float64
+	lots   int
+	open   Trade
+	paid   float64
+	trades []Trade
+}
+
+// Run прогоняет стратегию. Решение на баре i принимается по барам [i-Lookback+1, i]
+// и исполняется по открытию бара i+1; позиция, открытая к концу данных, закрывается по последней цене закрытия.
+func (e *Engine) Run(ctx context.Context, bars []Bar, strategy Strategy, cfg Config) (Result, error) {
+	if err := cfg.validate(); err != nil {
+		return Result{}, err
+	}
+	if len(bars) <= cfg.Lookback {
+		return Result{}, fmt.Errorf("not enough bars for backtest: %d <= lookback %d", len(bars), cfg.Lookback)
+	}
+
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+	}
+
+	acc := &account{cfg: cfg, cash: cfg.InitialCash}
+	summary := Summary{Bars: len(bars), InitialCash: cfg.InitialCash}
+	equity := make([]EquityPoint, 0, len(bars))
+	var pending *Decision
+	inPosition := 0
+	peak := cfg.InitialCash
+
+	for i, bar := range bars {
+		if pending != nil {
+			switch pending.Action {
+			case ActionBuy:
+				if !acc.buy(bar, pending.Reason) {
+					summary.Skipped++
+				}
+			case ActionSell:
+				acc.sell(bar.Time, bar.Open, pending.Reason)
+			}
+			pending = nil
+		}
+
+		if i == len(bars)-1 && acc.lots > 0 {
+			acc.sell(bar.Time, bar.Close, "end of data")
+		}
+
+		point := acc.mark(bar)
+		peak = math.Max(peak, point.Equity)
+		point.Drawdown = (peak - point.Equity) / peak
+		equity = append(equity, point)
+		if point.Quantity > 0 {
+			inPosition++
+		}
+
+		if i == len(bars)-1 || i+1 < cfg.Lookback || (i+1-cfg.Lookback)%cfg.Step != 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+
+		signal, err := e.pa.TotalSignal(closes[i+1-cfg.Lookback : i+1])
+		if err != nil {
+			return Result{}, fmt.Errorf("signal at %s: %w", bar.Time.Format(time.RFC3339), err)
+		}
+		summary.Signals++
+
+		decision := strategy.Decide(signal)
+		if decision.Action == ActionBuy && acc.lots == 0 || decision.Action == ActionSell && acc.lots > 0 {
+			pending = &decision
+		}
+	}
+
+	summary.FinalEquity = equity[len(equity)-1].Equity
+	summary.TotalReturn = summary.FinalEquity/cfg.InitialCash - 1
+	summary.Exposure = float64(inPosition) / float64(len(bars))
+	summarizeTrades(&summary, acc.trades)
+
+	equityValues := make([]float64, len(equity))
+	times := make([]time.Time, len(equity))
+	for i, point := range equity {
+		equityValues[i] = point.Equity
+		times[i] = point.Time
+	}
+	risk, err := e.rm.TotalRisk(equityValues, times, cfg.Confidence, cfg.RiskFreeRate, cfg.PeriodsPerYear)
+	if err != nil {
+		return Result{}, fmt.Errorf("equity risk: %w", err)
+	}
+
+	return Result{
+		Summary: summary,
+		Risk:    risk,
+		Equity:  equity,
+		Trades:  acc.trades,
+	}, nil
+}
+
+// buy покупает на все деньги целыми лотами; false, если не хватает на один лот
+func (a *account) buy(bar Bar, reason string) bool {
+	price := a.fillPrice(bar.Open, true)
+	lotCost := price * float64(a.cfg.Lot) * (1 + a.cfg.Commission)
+	lots := int(a.cash / lotCost)
+	if lots == 0 {
+		return false
+	}
+
+	quantity := lots * a.cfg.Lot
+	commission := price * float64(quantity) * a.cfg.Commission
+	a.cash -= price*float64(quantity) + commission
+	a.lots = lots
+	a.paid = price*float64(quantity) + commission
+	a.open = Trade{
+		EntryTime:   bar.Time,
+		EntryPrice:  price,
+		EntryReason: reason,
+		Lots:        lots,
+		Quantity:    quantity,
+		Commission:  commission,
+	}
+	return true
+}
+
+// sell закрывает позицию целиком
+func (a *account) sell(t time.Time, price float64, reason string) {
+	price = a.fillPrice(price, false)
+	quantity := a.lots * a.cfg.Lot
+	commission := price * float64(quantity) * a.cfg.Commission
+	proceeds := price*float64(quantity) - commission
+	a.cash += proceeds
+
+	trade := a.open
+	trade.ExitTime = t
+	trade.ExitPrice = price
+	trade.ExitReason = reason
+	trade.Commission += commission
+	trade.PnL = proceeds - a.paid
+	trade.Return = trade.PnL / a.paid
+	a.trades = append(a.trades, trade)
+
+	a.lots = 0
+	a.paid = 0
+	a.open = Trade{}
+}
+
+func (a *account) mark(bar Bar) EquityPoint {
+	quantity := a.lots * a.cfg.Lot
+	if quantity > 0 {
+		a.open.Bars++
+	}
+	return EquityPoint{
+		Time:     bar.Time,
+		Equity:   a.cash + bar.Close*float64(quantity),
+		Cash:     a.cash,
+		Quantity: quantity,
+	}
+}
+
+// fillPrice цена исполнения: сдвиг на проскальзывание против сделки и округление до шага цены в худшую сторону
+func (a *account) fillPrice(price float64, buy bool) float64 {
+	tick := a.cfg.MinPriceIncrement
+	if tick == 0 {
+		return price
+	}
+
+	slippage := float64(a.cfg.SlippageTicks) * tick
+	if buy {
+		return roundTicks(math.Ceil((price+slippage)/tick-1e-9), tick)
+	}
+	return roundTicks(math.Max(1, math.Floor((price-slippage)/tick+1e-9)), tick)
+}
+
+// roundTicks цена из целого числа шагов без хвоста двоичного округления
+func roundTicks(ticks, tick float64) float64 {
+	return math.Round(ticks*tick*1e9) / 1e9
+}
+
+func summarizeTrades(summary *Summary, trades []Trade) {
+	summary.Trades = len(trades)
+	if len(trades) == 0 {
+		return
+	}
+
+	var wins int
+	var gross, loss, returns float64
+	for _, trade := range trades {
+		summary.Commission += trade.Commission
+		returns += trade.Return
+		if trade.PnL > 0 {
+			wins++
+			gross += trade.PnL
+		} else {
+			loss -= trade.PnL
+		}
+	}
+
+	summary.WinRate = float64(wins) / float64(len(trades))
+	summary.AvgReturn = returns / float64(len(trades))
+	if loss > 0 {
+		summary.ProfitFactor = gross / loss
+	}
+}
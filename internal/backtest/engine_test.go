@@ -0,0 +1,275 @@
+package backtest
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/math/coefficients_calculation"
+	"mamonolitmvp/internal/math/price_analysis"
+)
+
+// scriptedStrategy отдает решения по порядку вызовов и запоминает последнюю короткую SMA каждого сигнала
+type scriptedStrategy struct {
+	actions  []string
+	shortSMA []float64
+}
+
+func (s *scriptedStrategy) Decide(signal price_analysis.Signal) Decision {
+	call := len(s.shortSMA)
+	s.shortSMA = append(s.shortSMA, signal.ShortSMA[len(signal.ShortSMA)-1])
+	if call >= len(s.actions) {
+		return Decision{Action: ActionHold}
+	}
+	return Decision{Action: s.actions[call], Reason: "call " + strconv.Itoa(call)}
+}
+
+// testEngine движок с короткими SMA, чтобы сигнал считался по нескольким барам
+func testEngine() *Engine {
+	pa := price_analysis.NewPriceAnalysis()
+	pa.ShortSmaPeriod = 2
+	pa.LongSmaPeriod = 3
+	pa.RSIPeriod = 2
+	return NewEngine(pa, coefficients_calculation.NewRiskMetrics())
+}
+
+// lookback минимальное окно, на котором MFDFA сигнала находит два масштаба
+const lookback = 40
+
+// warmup число баров перед сценарием теста: первое решение принимается на последнем баре
+// первого окна, то есть на третьем баре сценария
+const warmup = lookback - 3
+
+// testBars бары по парам open/close с часовым шагом после warmup баров с небольшими колебаниями около 100
+func testBars(openClose ...[2]float64) []Bar {
+	start := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+	prices := make([][2]float64, 0, warmup+len(openClose))
+	for i := 0; i < warmup; i++ {
+		prices = append(prices, [2]float64{100, 100 + math.Sin(float64(i))})
+	}
+	prices = append(prices, openClose...)
+
+	bars := make([]Bar, len(prices))
+	for i, oc := range prices {
+		bars[i] = Bar{
+			Time:  start.Add(time.Duration(i) * time.Hour),
+			Open:  oc[0],
+			High:  math.Max(oc[0], oc[1]),
+			Low:   math.Min(oc[0], oc[1]),
+			Close: oc[1],
+		}
+	}
+	return bars
+}
+
+func testConfig() Config {
+	return Config{
+		InitialCash:       10000,
+		Lot:               10,
+		MinPriceIncrement: 0.05,
+		Commission:        0.001,
+		SlippageTicks:     2,
+		Lookback:          lookback,
+		Step:              1,
+		Confidence:        0.95,
+		PeriodsPerYear:    252,
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestRunFillsNextBarOpen(t *testing.T) {
+	all := testBars(
+		[2]float64{100, 100.5},
+		[2]float64{100.5, 101},
+		[2]float64{101, 100.4},    // решение 0: BUY
+		[2]float64{100.02, 102},   // покупка по открытию
+		[2]float64{102, 104},      // решение 2: SELL
+		[2]float64{104.98, 103.5}, // продажа по открытию, решение 3: BUY
+		[2]float64{103.01, 102.2}, // покупка по открытию
+		[2]float64{102.1, 101.03}, // конец данных: продажа по закрытию
+	)
+	bars := all[warmup:]
+	strategy := &scriptedStrategy{actions: []string{ActionBuy, ActionHold, ActionSell, ActionBuy}}
+
+	result, err := testEngine().Run(context.Background(), all, strategy, testConfig())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Сигнал по барам [i-lookback+1, i] без заглядывания вперед: короткая SMA из двух последних закрытий
+	if len(strategy.shortSMA) != 5 {
+		t.Fatalf("decisions = %d, want one per closed bar from lookback to the last but one", len(strategy.shortSMA))
+	}
+	for call, sma := range strategy.shortSMA {
+		i := call + 2
+		if want := (bars[i-1].Close + bars[i].Close) / 2; !near(sma, want) {
+			t.Errorf("decision %d short SMA = %v, want %v from bars %d and %d", call, sma, want, i-1, i)
+		}
+	}
+
+	// Покупка: (100.02 + 2 * 0.05) округляется вверх до 100.15; 9 лотов по 10 с комиссией 0.1%.
+	// Продажа: (104.98 - 0.1) округляется вниз до 104.85.
+	// Вторая покупка: 103.11 -> 103.15, 10 лотов; закрытие по концу данных: 100.93 -> 100.90.
+	want := []Trade{
+		{
+			EntryTime: bars[3].Time, EntryPrice: 100.15, EntryReason: "call 0",
+			ExitTime: bars[5].Time, ExitPrice: 104.85, ExitReason: "call 2",
+			Lots: 9, Quantity: 90, Bars: 2,
+			Commission: 9.0135 + 9.4365,
+			PnL:        9427.0635 - 9022.5135,
+		},
+		{
+			EntryTime: bars[6].Time, EntryPrice: 103.15, EntryReason: "call 3",
+			ExitTime: bars[7].Time, ExitPrice: 100.90, ExitReason: "end of data",
+			Lots: 10, Quantity: 100, Bars: 1,
+			Commission: 10.315 + 10.09,
+			PnL:        10079.91 - 10325.315,
+		},
+	}
+	if len(result.Trades) != len(want) {
+		t.Fatalf("trades = %+v, want %d", result.Trades, len(want))
+	}
+	for i, got := range result.Trades {
+		w := want[i]
+		if !got.EntryTime.Equal(w.EntryTime) || !got.ExitTime.Equal(w.ExitTime) ||
+			got.EntryReason != w.EntryReason || got.ExitReason != w.ExitReason ||
+			got.Lots != w.Lots || got.Quantity != w.Quantity || got.Bars != w.Bars {
+			t.Errorf("trade %d = %+v, want %+v", i, got, w)
+		}
+		if !near(got.EntryPrice, w.EntryPrice) || !near(got.ExitPrice, w.ExitPrice) {
+			t.Errorf("trade %d prices %v -> %v, want %v -> %v", i, got.EntryPrice, got.ExitPrice, w.EntryPrice, w.ExitPrice)
+		}
+		if !near(got.Commission, w.Commission) || !near(got.PnL, w.PnL) {
+			t.Errorf("trade %d commission %v pnl %v, want %v and %v", i, got.Commission, got.PnL, w.Commission, w.PnL)
+		}
+	}
+
+	summary := result.Summary
+	finalCash := 10000 + want[0].PnL + want[1].PnL
+	if !near(summary.FinalEquity, finalCash) || !near(summary.TotalReturn, finalCash/10000-1) {
+		t.Errorf("final equity %v return %v, want %v", summary.FinalEquity, summary.TotalReturn, finalCash)
+	}
+	if !near(summary.Commission, want[0].Commission+want[1].Commission) {
+		t.Errorf("commission %v, want %v", summary.Commission, want[0].Commission+want[1].Commission)
+	}
+	if summary.Bars != len(all) || summary.Signals != 5 || summary.Trades != 2 || summary.Skipped != 0 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if !near(summary.WinRate, 0.5) || !near(summary.ProfitFactor, want[0].PnL/-want[1].PnL) {
+		t.Errorf("win rate %v profit factor %v", summary.WinRate, summary.ProfitFactor)
+	}
+	if !near(summary.Exposure, 3/float64(len(all))) {
+		t.Errorf("exposure %v, want 3 bars of %d: bars 3, 4 and 6 close in position", summary.Exposure, len(all))
+	}
+
+	// Кривая капитала по закрытиям: в позиции деньги плюс бумаги по цене закрытия
+	if len(result.Equity) != len(all) {
+		t.Fatalf("equity points = %d, want %d", len(result.Equity), len(all))
+	}
+	equity := result.Equity[warmup:]
+	if cash := 10000 - 9022.5135; !near(equity[3].Cash, cash) || !near(equity[3].Equity, cash+90*102) || equity[3].Quantity != 90 {
+		t.Errorf("equity at entry bar = %+v", equity[3])
+	}
+	if last := equity[len(equity)-1]; last.Quantity != 0 || !near(last.Cash, finalCash) {
+		t.Errorf("last equity point = %+v, want a closed position", last)
+	}
+	peak := equity[5].Equity // деньги после прибыльной продажи
+	if got := equity[7].Drawdown; !near(got, (peak-finalCash)/peak) {
+		t.Errorf("drawdown %v after peak %v", got, peak)
+	}
+}
+
+func TestRunSkipsBuyWithoutCashForLot(t *testing.T) {
+	bars := testBars([2]float64{100, 100}, [2]float64{100, 100}, [2]float64{100, 100}, [2]float64{100, 100}, [2]float64{100, 100})
+	cfg := testConfig()
+	cfg.InitialCash = 1000 // лот 10 по 100.10 с комиссией дороже
+
+	result, err := testEngine().Run(context.Background(), bars, &scriptedStrategy{actions: []string{ActionBuy, ActionBuy}}, cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Summary.Skipped != 2 || len(result.Trades) != 0 || result.Summary.FinalEquity != 1000 {
+		t.Errorf("summary = %+v, want both buys skipped and cash untouched", result.Summary)
+	}
+}
+
+func TestRunIgnoresDecisionsThatDoNotChangePosition(t *testing.T) {
+	all := testBars([2]float64{100, 100}, [2]float64{100, 100}, [2]float64{100, 100}, [2]float64{100, 101},
+		[2]float64{101, 102}, [2]float64{102, 103})
+	bars := all[warmup:]
+	cfg := testConfig()
+	cfg.SlippageTicks = 0
+	cfg.Commission = 0
+
+	// SELL без позиции и повторный BUY в позиции не исполняются
+	strategy := &scriptedStrategy{actions: []string{ActionSell, ActionBuy, ActionBuy}}
+	result, err := testEngine().Run(context.Background(), all, strategy, cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(result.Trades) != 1 {
+		t.Fatalf("trades = %+v, want one", result.Trades)
+	}
+	trade := result.Trades[0]
+	if !trade.EntryTime.Equal(bars[4].Time) || trade.EntryPrice != 101 || trade.ExitPrice != 103 || trade.ExitReason != "end of data" {
+		t.Errorf("trade = %+v, want entry at bar 4 open and exit at the last close", trade)
+	}
+}
+
+func TestFillPriceRounding(t *testing.T) {
+	tests := []struct {
+		name     string
+		tick     float64
+		slippage int
+		price    float64
+		buy      bool
+		want     float64
+	}{
+		{"buy on tick", 0.01, 0, 250.37, true, 250.37},
+		{"buy rounds up", 0.05, 0, 100.01, true, 100.05},
+		{"sell rounds down", 0.05, 0, 100.04, false, 100.00},
+		{"buy slippage", 0.5, 3, 100, true, 101.5},
+		{"sell slippage", 0.5, 3, 100, false, 98.5},
+		{"sell keeps one tick", 0.5, 10, 2, false, 0.5},
+		{"binary tail", 0.1, 1, 0.3, true, 0.4},
+		{"no tick", 0, 0, 100.123, true, 100.123},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := &account{cfg: Config{MinPriceIncrement: tt.tick, SlippageTicks: tt.slippage}}
+			if got := acc.fillPrice(tt.price, tt.buy); got != tt.want {
+				t.Errorf("fillPrice(%v, buy=%v) = %v, want %v", tt.price, tt.buy, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunRejectsConfig(t *testing.T) {
+	bars := testBars([2]float64{100, 100}, [2]float64{100, 100}, [2]float64{100, 100}, [2]float64{100, 100})
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"slippage without tick", func(c *Config) { c.MinPriceIncrement = 0 }, "min price increment"},
+		{"lot", func(c *Config) { c.Lot = 0 }, "lot"},
+		{"commission", func(c *Config) { c.Commission = 1 }, "commission"},
+		{"lookback", func(c *Config) { c.Lookback = len(bars) }, "not enough bars"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			tt.modify(&cfg)
+			_, err := testEngine().Run(context.Background(), bars, &scriptedStrategy{}, cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
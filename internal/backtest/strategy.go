@@ -0,0 +1,56 @@
+package backtest
+
+import (
+	"fmt"
+	"mamonolitmvp/internal/math/price_analysis"
+)
+
+const (
+	ActionBuy  = "BUY"
+	ActionSell = "SELL"
+	ActionHold = "HOLD"
+)
+
+// Decision решение стратегии и его причина для журнала сделок
+type Decision struct {
+	Action string
+	Reason string
+}
+
+// Strategy принимает решение по сигналу, посчитанному только по уже закрытым барам
+type Strategy interface {
+	Decide(signal price_analysis.Signal) Decision
+}
+
+// SignalStrategy следует за трендом: покупка, когда короткая SMA выше длинной на TrendThreshold,
+// ряд персистентен (Hurst не ниже MinHurst) и RSI не перекуплен; продажа при развороте тренда или перекупленности.
+type SignalStrategy struct {
+	TrendThreshold float64
+	RSIOverbought  float64
+	MinHurst       float64
+}
+
+func NewSignalStrategy() *SignalStrategy {
+	return &SignalStrategy{
+		TrendThreshold: 0.01,
+		RSIOverbought:  70,
+		MinHurst:       0.5,
+	}
+}
+
+func (s *SignalStrategy) Decide(signal price_analysis.Signal) Decision {
+	var rsi float64
+	if len(signal.RSI) > 0 {
+		rsi = signal.RSI[len(signal.RSI)-1]
+	}
+
+	switch {
+	case signal.TrendFactor <= -s.TrendThreshold:
+		return Decision{ActionSell, fmt.Sprintf("trend factor %.4f <= -%.4f", signal.TrendFactor, s.TrendThreshold)}
+	case rsi >= s.RSIOverbought:
+		return Decision{ActionSell, fmt.Sprintf("RSI %.1f >= %.1f", rsi, s.RSIOverbought)}
+	case signal.TrendFactor >= s.TrendThreshold && signal.Hurst >= s.MinHurst:
+		return Decision{ActionBuy, fmt.Sprintf("trend factor %.4f >= %.4f, Hurst %.3f >= %.3f", signal.TrendFactor, s.TrendThreshold, signal.Hurst, s.MinHurst)}
+	}
+	return Decision{Action: ActionHold}
+}
@@ -0,0 +1,44 @@
+// Package fakesink локальные замены получателей оповещений для проверки доставки без сети:
+// webhook, Telegram Bot API (sendMessage) и SMTP-сервер. Принятые сообщения сохраняются в памяти.
+package fakesink
+
+import (
+	"sync"
+	"time"
+)
+
+// Received сообщение, принятое заменой получателя
+type Received struct {
+	Sink string `json:"sink"`
+	// To путь запроса webhook, chat_id Telegram или адрес получателя письма
+	To      string    `json:"to"`
+	Subject string    `json:"subject,omitempty"`
+	Text    string    `json:"text"`
+	Time    time.Time `json:"time"`
+}
+
+// inbox принятые сообщения; OnReceive вызывается на каждое
+type inbox struct {
+	OnReceive func(Received)
+
+	mu       sync.Mutex
+	received []Received
+}
+
+func (b *inbox) add(r Received) {
+	r.Time = time.Now()
+	b.mu.Lock()
+	b.received = append(b.received, r)
+	b.mu.Unlock()
+
+	if b.OnReceive != nil {
+		b.OnReceive(r)
+	}
+}
+
+// Received копия принятых сообщений в порядке получения
+func (b *inbox) Received() []Received {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Received(nil), b.received...)
+}
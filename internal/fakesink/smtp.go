@@ -0,0 +1,216 @@
+package fakesink
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+)
+
+// SMTP минимальный SMTP-сервер: EHLO/HELO, AUTH PLAIN, MAIL, RCPT, DATA, RSET, NOOP, QUIT без TLS.
+// Письмо сохраняется по разу на каждого получателя.
+type SMTP struct {
+	inbox
+	Hostname string
+	// Username и Password если заданы, письма принимаются только после AUTH PLAIN с ними
+	Username string
+	Password string
+	// RejectRecipients адреса, на которые RCPT отвечает 550, чтобы проверить отказ сервера
+	RejectRecipients []string
+}
+
+func NewSMTP() *SMTP {
+	return &SMTP{Hostname: "localhost"}
+}
+
+func (s *SMTP) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve принимает соединения до закрытия l
+func (s *SMTP) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.session(conn)
+	}
+}
+
+// smtpSession состояние одного соединения
+type smtpSession struct {
+	conn   *textproto.Conn
+	authed bool
+	from   string
+	to     []string
+}
+
+func (s *SMTP) session(c net.Conn) {
+	conn := textproto.NewConn(c)
+	defer conn.Close()
+
+	session := &smtpSession{conn: conn}
+	session.reply(220, s.Hostname+" ESMTP fakesink")
+
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				log.Printf("fakesink smtp: %v", err)
+			}
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			session.reply(250, s.Hostname, "8BITMIME", "AUTH PLAIN")
+		case "HELO":
+			session.reply(250, s.Hostname)
+		case "AUTH":
+			s.auth(session, arg)
+		case "MAIL":
+			if s.Username != "" && !session.authed {
+				session.reply(530, "5.7.0 Authentication required")
+				continue
+			}
+			session.from = address(arg, "FROM:")
+			session.to = nil
+			session.reply(250, "2.1.0 OK")
+		case "RCPT":
+			if session.from == "" {
+				session.reply(503, "5.5.1 Bad sequence of commands: MAIL first")
+				continue
+			}
+			to := address(arg, "TO:")
+			if slices.ContainsFunc(s.RejectRecipients, func(r string) bool { return strings.EqualFold(r, to) }) {
+				session.reply(550, "5.1.1 Mailbox unavailable")
+				continue
+			}
+			session.to = append(session.to, to)
+			session.reply(250, "2.1.5 OK")
+		case "DATA":
+			if len(session.to) == 0 {
+				session.reply(503, "5.5.1 Bad sequence of commands: RCPT first")
+				continue
+			}
+			session.reply(354, "End data with <CR><LF>.<CR><LF>")
+			if err := s.data(session); err != nil {
+				session.reply(554, "5.6.0 "+err.Error())
+				continue
+			}
+			session.reply(250, "2.0.0 OK queued")
+			session.from, session.to = "", nil
+		case "RSET":
+			session.from, session.to = "", nil
+			session.reply(250, "2.0.0 OK")
+		case "NOOP":
+			session.reply(250, "2.0.0 OK")
+		case "QUIT":
+			session.reply(221, "2.0.0 Bye")
+			return
+		default:
+			session.reply(502, "5.5.2 Command not recognized")
+		}
+	}
+}
+
+// auth AUTH PLAIN с начальным ответом в команде или после 334
+func (s *SMTP) auth(session *smtpSession, arg string) {
+	mechanism, response, _ := strings.Cut(arg, " ")
+	if !strings.EqualFold(mechanism, "PLAIN") {
+		session.reply(504, "5.5.4 Unrecognized authentication type")
+		return
+	}
+	if response == "" {
+		session.reply(334, "")
+		line, err := session.conn.ReadLine()
+		if err != nil {
+			return
+		}
+		response = line
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(response)
+	parts := strings.Split(string(decoded), "\x00")
+	if err != nil || len(parts) != 3 {
+		session.reply(501, "5.5.2 Cannot decode response")
+		return
+	}
+	if s.Username != "" && (parts[1] != s.Username || parts[2] != s.Password) {
+		session.reply(535, "5.7.8 Authentication credentials invalid")
+		return
+	}
+	session.authed = true
+	session.reply(235, "2.7.0 Authentication successful")
+}
+
+// data читает письмо до строки "." и сохраняет тему и текст
+func (s *SMTP) data(session *smtpSession) error {
+	raw, err := io.ReadAll(session.conn.DotReader())
+	if err != nil {
+		return err
+	}
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(string(raw))))
+	if err != nil {
+		return err
+	}
+	body, err := io.ReadAll(msg.Body)
+	if err != nil {
+		return err
+	}
+
+	subject := msg.Header.Get("Subject")
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(subject); err == nil {
+		subject = decoded
+	}
+	text := strings.TrimRight(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n")
+
+	for _, to := range session.to {
+		s.add(Received{
+			Sink:    "smtp",
+			To:      to,
+			Subject: subject,
+			Text:    text,
+		})
+	}
+	return nil
+}
+
+// reply ответ с кодом; несколько строк — многострочный ответ EHLO
+func (session *smtpSession) reply(code int, lines ...string) {
+	for i, line := range lines {
+		sep := "-"
+		if i == len(lines)-1 {
+			sep = " "
+		}
+		_ = session.conn.PrintfLine("%d%s%s", code, sep, line)
+	}
+}
+
+// address адрес из "FROM:<a@b> SIZE=..." без угловых скобок
+func address(arg, prefix string) string {
+	arg = strings.TrimSpace(arg)
+	if len(arg) >= len(prefix) && strings.EqualFold(arg[:len(prefix)], prefix) {
+		arg = strings.TrimSpace(arg[len(prefix):])
+	}
+	if addr, _, ok := strings.Cut(arg, " "); ok {
+		arg = addr
+	}
+	return strings.Trim(arg, "<>")
+}
@@ -0,0 +1,95 @@
+package fakesink
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Telegram отвечает на POST /bot<Token>/sendMessage в формате Bot API: {"ok": true, "result": ...}
+// или {"ok": false, "error_code": ..., "description": ...}
+type Telegram struct {
+	inbox
+	// Token если задан, запросы с другим токеном получают 401
+	Token string
+
+	nextID int
+}
+
+func NewTelegram(token string) *Telegram {
+	return &Telegram{Token: token}
+}
+
+type telegramError struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+func (t *Telegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, method, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/bot"), "/")
+	if !strings.HasPrefix(r.URL.Path, "/bot") || !ok {
+		writeTelegramError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if t.Token != "" && token != t.Token {
+		writeTelegramError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if method != "sendMessage" {
+		writeTelegramError(w, http.StatusNotFound, "Not Found: method not found")
+		return
+	}
+
+	var req struct {
+		// ChatID Bot API принимает и число, и строку
+		ChatID any    `json:"chat_id"`
+		Text   string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeTelegramError(w, http.StatusBadRequest, "Bad Request: "+err.Error())
+		return
+	}
+	chatID := ""
+	if req.ChatID != nil {
+		chatID = fmt.Sprint(req.ChatID)
+	}
+	switch {
+	case chatID == "":
+		writeTelegramError(w, http.StatusBadRequest, "Bad Request: chat_id is empty")
+		return
+	case req.Text == "":
+		writeTelegramError(w, http.StatusBadRequest, "Bad Request: message text is empty")
+		return
+	}
+
+	t.mu.Lock()
+	t.nextID++
+	id := t.nextID
+	t.mu.Unlock()
+
+	t.add(Received{
+		Sink: "telegram",
+		To:   chatID,
+		Text: req.Text,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"ok": true,
+		"result": map[string]any{
+			"message_id": id,
+			"chat":       map[string]any{"id": req.ChatID},
+			"date":       time.Now().Unix(),
+			"text":       req.Text,
+		},
+	})
+}
+
+func writeTelegramError(w http.ResponseWriter, status int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(telegramError{ErrorCode: status, Description: description})
+}
@@ -0,0 +1,43 @@
+package fakesink
+
+import (
+	"encoding/json"
+	"mamonolitmvp/pkg/notify"
+	"net/http"
+)
+
+// Webhook принимает POST с notify.Message в JSON на любой путь
+type Webhook struct {
+	inbox
+	// FailStatus если задан, каждый запрос получает этот статус, чтобы проверить ошибку доставки
+	FailStatus int
+}
+
+func NewWebhook() *Webhook {
+	return &Webhook{}
+}
+
+func (h *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.FailStatus != 0 {
+		http.Error(w, http.StatusText(h.FailStatus), h.FailStatus)
+		return
+	}
+
+	var msg notify.Message
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.add(Received{
+		Sink:    "webhook",
+		To:      r.URL.Path,
+		Subject: msg.Subject,
+		Text:    msg.Text,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
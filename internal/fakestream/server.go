@@ -0,0 +1,323 @@
+// Package fakestream локальная замена MarketDataStreamService/MarketDataServerSideStream Tinkoff
+// для проверки потоковой загрузки без сети: отдает подтверждения подписок, закрытые свечи,
+// последние цены, стаканы и пинги в формате REST API.
+package fakestream
+
+import (
+	"encoding/json"
+	"log"
+	"mamonolitmvp/internal/models"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const streamMethod = "/MarketDataServerSideStream"
+
+// candleDurations длительность свечи интервала подписки; свеча начинается на границе, кратной длительности
+var candleDurations = map[string]time.Duration{
+	"SUBSCRIPTION_INTERVAL_ONE_MINUTE":      time.Minute,
+	"SUBSCRIPTION_INTERVAL_2_MIN":           2 * time.Minute,
+	"SUBSCRIPTION_INTERVAL_3_MIN":           3 * time.Minute,
+	"SUBSCRIPTION_INTERVAL_FIVE_MINUTES":    5 * time.Minute,
+	"SUBSCRIPTION_INTERVAL_10_MIN":          10 * time.Minute,
+	"SUBSCRIPTION_INTERVAL_FIFTEEN_MINUTES": 15 * time.Minute,
+	"SUBSCRIPTION_INTERVAL_30_MIN":          30 * time.Minute,
+	"SUBSCRIPTION_INTERVAL_ONE_HOUR":        time.Hour,
+	"SUBSCRIPTION_INTERVAL_2_HOUR":          2 * time.Hour,
+	"SUBSCRIPTION_INTERVAL_4_HOUR":          4 * time.Hour,
+	"SUBSCRIPTION_INTERVAL_ONE_DAY":         24 * time.Hour,
+	"SUBSCRIPTION_INTERVAL_WEEK":            7 * 24 * time.Hour,
+	"SUBSCRIPTION_INTERVAL_MONTH":           30 * 24 * time.Hour,
+}
+
+// Server отвечает на POST .../MarketDataServerSideStream. Как и настоящий поток, каждый Tick присылает
+// обновление текущей свечи по настенным часам: закрытие, максимум, минимум и объем меняются внутри свечи,
+// а следующая свеча начинается, когда наступает ее время; с waitingClose приходят только закрытые свечи.
+// По остальным подпискам приходят цена и стакан.
+type Server struct {
+	// Tick период сообщений
+	Tick time.Duration
+	// DropAfter закрывает поток после стольких сообщений, чтобы проверить переподключение; 0 — не закрывать
+	DropAfter int
+	// StallAfter после стольких сообщений поток остается открытым, но молчит, включая пинги,
+	// чтобы проверить переподключение зависшего потока; 0 — не замолкать
+	StallAfter int
+	// Token если задан, запрос без заголовка "Authorization: Bearer <Token>" получает 401
+	Token string
+
+	mu          sync.Mutex
+	prices      map[string]float64
+	connections int
+}
+
+func NewServer() *Server {
+	return &Server{
+		Tick:   time.Second,
+		prices: make(map[string]float64),
+	}
+}
+
+// Connections число открытых за все время потоков
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, streamMethod) {
+		writeError(w, http.StatusNotFound, 5, "method not found")
+		return
+	}
+	if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeError(w, http.StatusUnauthorized, 16, "authentication token is missing or invalid")
+		return
+	}
+
+	var req models.MarketDataServerSideStreamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, 3, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.connections++
+	s.mu.Unlock()
+
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	sent := 0
+	send := func(msg models.MarketDataResponse) bool {
+		if s.DropAfter > 0 && sent >= s.DropAfter {
+			return false
+		}
+		if s.StallAfter > 0 && sent >= s.StallAfter {
+			return true
+		}
+		data, _ := json.Marshal(map[string]any{"result": msg})
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		sent++
+		return true
+	}
+
+	for _, msg := range subscriptionResponses(req) {
+		if !send(msg) {
+			return
+		}
+	}
+
+	// Текущая свеча каждой подписки; заполняется на первом Tick
+	candles := make([]*models.StreamCandle, 0)
+	if req.SubscribeCandlesRequest != nil {
+		candles = make([]*models.StreamCandle, len(req.SubscribeCandlesRequest.Instruments))
+	}
+
+	pingDelay := 5 * time.Second
+	if req.PingSettings != nil && req.PingSettings.PingDelayMs > 0 {
+		pingDelay = time.Duration(req.PingSettings.PingDelayMs) * time.Millisecond
+	}
+	ping := time.NewTicker(pingDelay)
+	defer ping.Stop()
+	tick := time.NewTicker(s.Tick)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case now := <-ping.C:
+			if !send(models.MarketDataResponse{Ping: &models.Ping{Time: now.UTC()}}) {
+				return
+			}
+		case now := <-tick.C:
+			for _, msg := range s.tick(req, candles, now.UTC()) {
+				if !send(msg) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// tick сообщения по всем подпискам за один период
+func (s *Server) tick(req models.MarketDataServerSideStreamRequest, candles []*models.StreamCandle, now time.Time) []models.MarketDataResponse {
+	var messages []models.MarketDataResponse
+
+	if req.SubscribeCandlesRequest != nil {
+		for i, c := range req.SubscribeCandlesRequest.Instruments {
+			d, ok := candleDurations[c.Interval]
+			if !ok {
+				continue
+			}
+			previous := candles[i]
+			candles[i] = s.updateCandle(previous, c, now.Truncate(d), now)
+			// С waitingClose свеча приходит один раз, когда началась следующая
+			if !req.SubscribeCandlesRequest.WaitingClose {
+				candle := *candles[i]
+				messages = append(messages, models.MarketDataResponse{Candle: &candle})
+			} else if previous != nil && !previous.Time.Equal(candles[i].Time) {
+				messages = append(messages, models.MarketDataResponse{Candle: previous})
+			}
+		}
+	}
+
+	if req.SubscribeLastPriceRequest != nil {
+		for _, l := range req.SubscribeLastPriceRequest.Instruments {
+			messages = append(messages, models.MarketDataResponse{LastPrice: &models.LastPrice{
+				Figi:          l.InstrumentId,
+				InstrumentUid: l.InstrumentId,
+				Price:         quotation(s.step(l.InstrumentId)),
+				Time:          now,
+			}})
+		}
+	}
+
+	if req.SubscribeOrderBookRequest != nil {
+		for _, o := range req.SubscribeOrderBookRequest.Instruments {
+			messages = append(messages, models.MarketDataResponse{Orderbook: s.orderBook(o, now)})
+		}
+	}
+
+	return messages
+}
+
+// updateCandle следующее состояние свечи, открытой в start: новая свеча открывается по последней цене,
+// в текущей сдвигается закрытие и расширяются максимум и минимум
+func (s *Server) updateCandle(candle *models.StreamCandle, c models.CandleInstrument, start, now time.Time) *models.StreamCandle {
+	closePrice := s.step(c.InstrumentId)
+	volume := 1 + rand.IntN(1000)
+
+	if candle == nil || !candle.Time.Equal(start) {
+		open := closePrice
+		if candle != nil {
+			open = candle.Close.Float64()
+		}
+		return &models.StreamCandle{
+			Figi:          c.InstrumentId,
+			InstrumentUid: c.InstrumentId,
+			Interval:      c.Interval,
+			Open:          quotation(open),
+			High:          quotation(math.Max(open, closePrice)),
+			Low:           quotation(math.Min(open, closePrice)),
+			Close:         quotation(closePrice),
+			Volume:        strconv.Itoa(volume),
+			Time:          start,
+			LastTradeTs:   now,
+		}
+	}
+
+	previous, _ := strconv.Atoi(candle.Volume)
+	next := *candle
+	next.High = quotation(math.Max(candle.High.Float64(), closePrice))
+	next.Low = quotation(math.Min(candle.Low.Float64(), closePrice))
+	next.Close = quotation(closePrice)
+	next.Volume = strconv.Itoa(previous + volume)
+	next.LastTradeTs = now
+	return &next
+}
+
+func (s *Server) orderBook(o models.OrderBookInstrument, now time.Time) *models.OrderBook {
+	price := s.price(o.InstrumentId)
+	step := price * 0.0005
+
+	book := &models.OrderBook{
+		Figi:          o.InstrumentId,
+		InstrumentUid: o.InstrumentId,
+		Depth:         o.Depth,
+		IsConsistent:  true,
+		Time:          now,
+		LimitUp:       quotation(price * 1.2),
+		LimitDown:     quotation(price * 0.8),
+	}
+	for level := 1; level <= o.Depth; level++ {
+		book.Bids = append(book.Bids, models.Order{Price: quotation(price - step*float64(level)), Quantity: strconv.Itoa(1 + rand.IntN(500))})
+		book.Asks = append(book.Asks, models.Order{Price: quotation(price + step*float64(level)), Quantity: strconv.Itoa(1 + rand.IntN(500))})
+	}
+	return book
+}
+
+// price текущая цена инструмента, начальная 100
+func (s *Server) price(instrumentID string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	price, ok := s.prices[instrumentID]
+	if !ok {
+		price = 100
+		s.prices[instrumentID] = price
+	}
+	return price
+}
+
+// step сдвигает цену случайным блужданием с волатильностью 0.5% за шаг
+func (s *Server) step(instrumentID string) float64 {
+	price := s.price(instrumentID) * math.Exp(0.005*rand.NormFloat64())
+
+	s.mu.Lock()
+	s.prices[instrumentID] = price
+	s.mu.Unlock()
+	return price
+}
+
+func subscriptionResponses(req models.MarketDataServerSideStreamRequest) []models.MarketDataResponse {
+	var messages []models.MarketDataResponse
+	if r := req.SubscribeCandlesRequest; r != nil {
+		resp := &models.SubscriptionResponse{TrackingId: "fake"}
+		for _, c := range r.Instruments {
+			status := models.SubscriptionStatusSuccess
+			if _, ok := candleDurations[c.Interval]; !ok {
+				status = "SUBSCRIPTION_STATUS_INTERVAL_IS_INVALID"
+			}
+			resp.CandlesSubscriptions = append(resp.CandlesSubscriptions, models.SubscriptionStatus{
+				InstrumentUid: c.InstrumentId, Interval: c.Interval, SubscriptionStatus: status,
+			})
+		}
+		messages = append(messages, models.MarketDataResponse{SubscribeCandlesResponse: resp})
+	}
+	if r := req.SubscribeOrderBookRequest; r != nil {
+		resp := &models.SubscriptionResponse{TrackingId: "fake"}
+		for _, o := range r.Instruments {
+			resp.OrderBookSubscriptions = append(resp.OrderBookSubscriptions, models.SubscriptionStatus{
+				InstrumentUid: o.InstrumentId, Depth: o.Depth, SubscriptionStatus: models.SubscriptionStatusSuccess,
+			})
+		}
+		messages = append(messages, models.MarketDataResponse{SubscribeOrderBookResponse: resp})
+	}
+	if r := req.SubscribeLastPriceRequest; r != nil {
+		resp := &models.SubscriptionResponse{TrackingId: "fake"}
+		for _, l := range r.Instruments {
+			resp.LastPriceSubscriptions = append(resp.LastPriceSubscriptions, models.SubscriptionStatus{
+				InstrumentUid: l.InstrumentId, SubscriptionStatus: models.SubscriptionStatusSuccess,
+			})
+		}
+		messages = append(messages, models.MarketDataResponse{SubscribeLastPriceResponse: resp})
+	}
+	return messages
+}
+
+func quotation(f float64) models.Quotation {
+	q, err := models.QuotationFromFloat(math.Round(f*100) / 100)
+	if err != nil {
+		log.Printf("fakestream: %v", err)
+	}
+	return q
+}
+
+// writeError ошибка в формате REST API Tinkoff
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"code": code, "message": message, "description": ""})
+}
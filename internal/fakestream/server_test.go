@@ -0,0 +1,78 @@
+package fakestream
+
+import (
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/models"
+)
+
+func candleRequest(waitingClose bool) models.MarketDataServerSideStreamRequest {
+	return models.MarketDataServerSideStreamRequest{SubscribeCandlesRequest: &models.SubscribeCandlesRequest{
+		SubscriptionAction: models.SubscriptionActionSubscribe,
+		Instruments:        []models.CandleInstrument{{InstrumentId: "uid-1", Interval: "SUBSCRIPTION_INTERVAL_ONE_MINUTE"}},
+		WaitingClose:       waitingClose,
+	}}
+}
+
+func TestTickUpdatesCurrentCandle(t *testing.T) {
+	s := NewServer()
+	req := candleRequest(false)
+	candles := make([]*models.StreamCandle, 1)
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	var updates []models.StreamCandle
+	for _, now := range []time.Time{start.Add(10 * time.Second), start.Add(30 * time.Second), start.Add(59 * time.Second), start.Add(61 * time.Second)} {
+		messages := s.tick(req, candles, now)
+		if len(messages) != 1 || messages[0].Candle == nil {
+			t.Fatalf("tick at %s: %d messages", now, len(messages))
+		}
+		candle := *messages[0].Candle
+		if candle.Time.After(now) {
+			t.Fatalf("candle %s is in the future at %s", candle.Time, now)
+		}
+		updates = append(updates, candle)
+	}
+
+	for i, candle := range updates[:3] {
+		if !candle.Time.Equal(start) {
+			t.Fatalf("update %d time = %s, want %s", i, candle.Time, start)
+		}
+		if candle.Open != updates[0].Open {
+			t.Fatalf("update %d open changed inside candle", i)
+		}
+		if candle.High.Float64() < candle.Close.Float64() || candle.Low.Float64() > candle.Close.Float64() {
+			t.Fatalf("update %d close %v outside [%v, %v]", i, candle.Close.Float64(), candle.Low.Float64(), candle.High.Float64())
+		}
+		if i > 0 && (candle.High.Float64() < updates[i-1].High.Float64() || candle.Low.Float64() > updates[i-1].Low.Float64()) {
+			t.Fatalf("update %d narrowed high/low", i)
+		}
+	}
+	next := updates[3]
+	if !next.Time.Equal(start.Add(time.Minute)) || next.Open != updates[2].Close {
+		t.Fatalf("next candle = %s open %v, want %s open %v", next.Time, next.Open.Float64(), start.Add(time.Minute), updates[2].Close.Float64())
+	}
+}
+
+func TestTickWaitingCloseSendsClosedCandles(t *testing.T) {
+	s := NewServer()
+	req := candleRequest(true)
+	candles := make([]*models.StreamCandle, 1)
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	if messages := s.tick(req, candles, start.Add(10*time.Second)); len(messages) != 0 {
+		t.Fatalf("forming candle sent with waitingClose: %+v", messages)
+	}
+	if messages := s.tick(req, candles, start.Add(50*time.Second)); len(messages) != 0 {
+		t.Fatalf("forming candle sent with waitingClose: %+v", messages)
+	}
+	last := *candles[0]
+
+	messages := s.tick(req, candles, start.Add(70*time.Second))
+	if len(messages) != 1 || messages[0].Candle == nil {
+		t.Fatalf("want closed candle, got %d messages", len(messages))
+	}
+	if closed := *messages[0].Candle; !closed.Time.Equal(start) || closed.Close != last.Close {
+		t.Fatalf("closed candle = %+v, want last state of %s", closed, start)
+	}
+}
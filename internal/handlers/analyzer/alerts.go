@@ -0,0 +1,156 @@
+package analyzer
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/models"
+	"net/http"
+)
+
+type AlertManager interface {
+	ListAlerts(ctx context.Context, instrumentUID string) ([]models.Alert, error)
+	GetAlert(ctx context.Context, id uint) (models.Alert, error)
+	CreateAlert(ctx context.Context, req models.AlertRequest) (models.Alert, error)
+	UpdateAlert(ctx context.Context, id uint, req models.AlertRequest) (models.Alert, error)
+	DeleteAlert(ctx context.Context, id uint) error
+	ListAlertEvents(ctx context.Context, id uint, req models.ListAlertEventsRequest) ([]models.AlertEvent, error)
+	TestAlert(ctx context.Context, id uint) error
+}
+
+type Alerts struct {
+	Service AlertManager
+}
+
+func NewAlertsHandler(service AlertManager) *Alerts {
+	return &Alerts{
+		Service: service,
+	}
+}
+
+// ListAlerts GET /alerts: все оповещения или оповещения инструмента, если задан instrumentId
+func (h *Alerts) ListAlerts(c echo.Context) error {
+	alerts, err := h.Service.ListAlerts(c.Request().Context(), c.QueryParam("instrumentId"))
+	if err != nil {
+		return problem.Respond(c, "Failed to list alerts", err)
+	}
+
+	return c.JSON(http.StatusOK, models.AlertsResponse{
+		Alerts: alerts,
+	})
+}
+
+// CreateAlert POST /alerts
+func (h *Alerts) CreateAlert(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	var req models.AlertRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid alert", err)
+	}
+
+	alert, err := h.Service.CreateAlert(c.Request().Context(), req)
+	if err != nil {
+		return problem.Respond(c, "Failed to create alert", err)
+	}
+
+	return c.JSON(http.StatusCreated, models.AlertResponse{
+		Alert: alert,
+	})
+}
+
+// GetAlert GET /alerts/:id: определение и состояние последней проверки
+func (h *Alerts) GetAlert(c echo.Context) error {
+	id, err := models.ParseAlertID(c.Param("id"))
+	if err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	alert, err := h.Service.GetAlert(c.Request().Context(), id)
+	if err != nil {
+		return problem.Respond(c, "Failed to get alert", err)
+	}
+
+	return c.JSON(http.StatusOK, models.AlertResponse{
+		Alert: alert,
+	})
+}
+
+// UpdateAlert PUT /alerts/:id: замена определения оповещения
+func (h *Alerts) UpdateAlert(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	id, err := models.ParseAlertID(c.Param("id"))
+	if err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	var req models.AlertRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid alert", err)
+	}
+
+	alert, err := h.Service.UpdateAlert(c.Request().Context(), id, req)
+	if err != nil {
+		return problem.Respond(c, "Failed to update alert", err)
+	}
+
+	return c.JSON(http.StatusOK, models.AlertResponse{
+		Alert: alert,
+	})
+}
+
+// DeleteAlert DELETE /alerts/:id
+func (h *Alerts) DeleteAlert(c echo.Context) error {
+	id, err := models.ParseAlertID(c.Param("id"))
+	if err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	if err := h.Service.DeleteAlert(c.Request().Context(), id); err != nil {
+		return problem.Respond(c, "Failed to delete alert", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetEvents GET /alerts/:id/events: последние срабатывания, в том числе подавленные паузой
+func (h *Alerts) GetEvents(c echo.Context) error {
+	id, err := models.ParseAlertID(c.Param("id"))
+	if err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	var req models.ListAlertEventsRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	events, err := h.Service.ListAlertEvents(c.Request().Context(), id, req)
+	if err != nil {
+		return problem.Respond(c, "Failed to list alert events", err)
+	}
+
+	return c.JSON(http.StatusOK, models.AlertEventsResponse{
+		Events: events,
+	})
+}
+
+// TestAlert POST /alerts/:id/test: пробное сообщение в канал оповещения
+func (h *Alerts) TestAlert(c echo.Context) error {
+	id, err := models.ParseAlertID(c.Param("id"))
+	if err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	if err := h.Service.TestAlert(c.Request().Context(), id); err != nil {
+		return problem.Respond(c, "Failed to deliver test notification", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,41 @@
+package analyzer
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/models"
+	"net/http"
+)
+
+type Backtester interface {
+	RunBacktest(ctx context.Context, req models.BacktestRequest) (models.BacktestResponse, error)
+}
+
+type Backtest struct {
+	Service Backtester
+}
+
+func NewBacktestHandler(service Backtester) *Backtest {
+	return &Backtest{
+		Service: service,
+	}
+}
+
+func (h *Backtest) RunBacktest(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	var req models.BacktestRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	resp, err := h.Service.RunBacktest(c.Request().Context(), req)
+	if err != nil {
+		return problem.Respond(c, "Failed to run backtest", err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
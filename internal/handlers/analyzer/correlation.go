@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/math/correlation_analysis"
+	"mamonolitmvp/internal/models"
+	"net/http"
+)
+
+type CorrelationCalculator interface {
+	GetCorrelations(ctx context.Context, req models.GetCorrelationsRequest) (correlation_analysis.CrossAsset, error)
+}
+
+type Correlation struct {
+	Service CorrelationCalculator
+}
+
+func NewCorrelationHandler(service CorrelationCalculator) *Correlation {
+	return &Correlation{
+		Service: service,
+	}
+}
+
+func (h *Correlation) GetCorrelations(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	var req models.GetCorrelationsRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	result, err := h.Service.GetCorrelations(c.Request().Context(), req)
+	if err != nil {
+		return problem.Respond(c, "Failed to calculate correlations", err)
+	}
+
+	return c.JSON(http.StatusOK, models.NewGetCorrelationsResponse(result))
+}
@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"mamonolitmvp/internal/math/correlation_analysis"
+	"mamonolitmvp/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type stubCorrelation struct {
+	req models.GetCorrelationsRequest
+}
+
+func (s *stubCorrelation) GetCorrelations(_ context.Context, req models.GetCorrelationsRequest) (correlation_analysis.CrossAsset, error) {
+	s.req = req
+	end := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	return correlation_analysis.CrossAsset{
+		Method:  correlation_analysis.Spearman,
+		Missing: correlation_analysis.MissingForwardFill,
+		IDs:     []string{"A", "B"},
+		Bars:    40,
+		Filled:  2,
+		To:      end,
+		Matrix:  [][]float64{{1, 0.5}, {0.5, 1}},
+		Rolling: []correlation_analysis.RollingPair{{A: "A", B: "B", Times: []time.Time{end}, Values: []float64{0.5}}},
+		Clustering: correlation_analysis.Clustering{
+			Merges: []correlation_analysis.Merge{{Left: 0, Right: 1, Distance: 0.5, Size: 2}},
+			Order:  []string{"A", "B"},
+		},
+	}, nil
+}
+
+func TestGetCorrelationsResponse(t *testing.T) {
+	stub := &stubCorrelation{}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/correlations?instrumentIds=A&instrumentIds=B"+
+		"&interval=CANDLE_INTERVAL_DAY&from=2025-01-01T00:00:00Z&to=2025-03-01T00:00:00Z", nil)
+	rec := httptest.NewRecorder()
+	if err := NewCorrelationHandler(stub).GetCorrelations(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("GetCorrelations: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if len(stub.req.InstrumentIds) != 2 || stub.req.Interval != "CANDLE_INTERVAL_DAY" {
+		t.Errorf("request = %+v", stub.req)
+	}
+
+	// Ответ сохраняет имена полей v1 API
+	var body struct {
+		Method        string         `json:"Method"`
+		InstrumentIds []string       `json:"InstrumentIds"`
+		Alignment     map[string]any `json:"Alignment"`
+		Matrix        [][]float64    `json:"Matrix"`
+		Rolling       []struct {
+			A      string    `json:"A"`
+			Values []float64 `json:"Values"`
+		} `json:"Rolling"`
+		Clustering struct {
+			Merges []map[string]float64 `json:"Merges"`
+			Order  []string             `json:"Order"`
+		} `json:"Clustering"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Method != "spearman" || len(body.InstrumentIds) != 2 || body.Matrix[0][1] != 0.5 {
+		t.Errorf("body = %+v", body)
+	}
+	if body.Alignment["Missing"] != "ffill" || body.Alignment["Bars"] != float64(40) || body.Alignment["Filled"] != float64(2) {
+		t.Errorf("alignment = %v", body.Alignment)
+	}
+	if len(body.Rolling) != 1 || body.Rolling[0].A != "A" || body.Rolling[0].Values[0] != 0.5 {
+		t.Errorf("rolling = %+v", body.Rolling)
+	}
+	if len(body.Clustering.Merges) != 1 || body.Clustering.Merges[0]["Distance"] != 0.5 || len(body.Clustering.Order) != 2 {
+		t.Errorf("clustering = %+v", body.Clustering)
+	}
+}
@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/math/coefficients_calculation"
+	"mamonolitmvp/internal/models"
+	"net/http"
+)
+
+type RiskCalculator interface {
+	GetRisk(ctx context.Context, req models.GetRiskRequest) (string, coefficients_calculation.Risk, error)
+}
+
+type Risk struct {
+	Service RiskCalculator
+}
+
+func NewRiskHandler(service RiskCalculator) *Risk {
+	return &Risk{
+		Service: service,
+	}
+}
+
+func (h *Risk) GetRisk(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	var req models.GetRiskRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	ticker, risk, err := h.Service.GetRisk(c.Request().Context(), req)
+	if err != nil {
+		return problem.Respond(c, "Failed to calculate risk metrics", err)
+	}
+
+	return c.JSON(http.StatusOK, models.NewGetRiskResponse(ticker, risk))
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"mamonolitmvp/internal/math/coefficients_calculation"
+	"mamonolitmvp/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+type stubRisk struct {
+	risk coefficients_calculation.Risk
+}
+
+func (s *stubRisk) GetRisk(_ context.Context, _ models.GetRiskRequest) (string, coefficients_calculation.Risk, error) {
+	return "SBER", s.risk, nil
+}
+
+func TestGetRiskResponse(t *testing.T) {
+	stub := &stubRisk{risk: coefficients_calculation.Risk{
+		Total:             6,
+		Confidence:        0.95,
+		MeanReturn:        0.01,
+		ValueAtRisk:       coefficients_calculation.ValueAtRisk{HistoricalVaR: 0.02, CornishFisherVaR: 0.03},
+		ExpectedShortfall: coefficients_calculation.ExpectedShortfall{ParametricES: 0.04},
+		Drawdown:          coefficients_calculation.Drawdown{MaxDrawdown: 0.25},
+		Ratios:            coefficients_calculation.Ratios{Sharpe: 1.5, Calmar: 2},
+	}}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/risk?"+signalsRange+"&confidence=0.95", nil)
+	rec := httptest.NewRecorder()
+	if err := NewRiskHandler(stub).GetRisk(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("GetRisk: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	// Ответ сохраняет имена полей v1 API
+	var body struct {
+		Ticker   string             `json:"ticker"`
+		Total    int                `json:"Total"`
+		Returns  map[string]float64 `json:"Returns"`
+		VaR      map[string]float64 `json:"VaR"`
+		ES       map[string]float64 `json:"ES"`
+		Ratios   map[string]float64 `json:"Ratios"`
+		Drawdown struct {
+			MaxDrawdown float64 `json:"MaxDrawdown"`
+		} `json:"Drawdown"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Ticker != "SBER" || body.Total != 6 || body.Returns["Mean"] != 0.01 {
+		t.Errorf("body = %+v", body)
+	}
+	if body.VaR["Historical"] != 0.02 || body.VaR["CornishFisher"] != 0.03 || body.ES["Parametric"] != 0.04 {
+		t.Errorf("VaR %v, ES %v", body.VaR, body.ES)
+	}
+	if body.Ratios["Sharpe"] != 1.5 || body.Ratios["Calmar"] != 2 || body.Drawdown.MaxDrawdown != 0.25 {
+		t.Errorf("ratios %v, drawdown %+v", body.Ratios, body.Drawdown)
+	}
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/internal/rules"
+	"net/http"
+)
+
+type RuleEvaluator interface {
+	ListRules(ctx context.Context, strategy string) ([]models.StrategyRule, error)
+	PutRule(ctx context.Context, strategy, name string, req models.PutRuleRequest) (models.StrategyRule, error)
+	DeleteRule(ctx context.Context, strategy, name string) error
+	EvaluateRules(ctx context.Context, strategy string, req models.GetCandlesRequest) (models.RuleEvaluation, error)
+	ListRuleEvaluations(ctx context.Context, strategy string) ([]models.RuleEvaluation, error)
+}
+
+type Rules struct {
+	Service RuleEvaluator
+}
+
+func NewRulesHandler(service RuleEvaluator) *Rules {
+	return &Rules{
+		Service: service,
+	}
+}
+
+// ListRules GET /rules: правила всех стратегий или одной, если задан strategy
+func (h *Rules) ListRules(c echo.Context) error {
+	list, err := h.Service.ListRules(c.Request().Context(), c.QueryParam("strategy"))
+	if err != nil {
+		return problem.Respond(c, "Failed to list rules", err)
+	}
+
+	return c.JSON(http.StatusOK, models.RulesResponse{
+		Rules: list,
+	})
+}
+
+// GetFields GET /rules/fields: поля и функции языка правил
+func (h *Rules) GetFields(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.RuleFieldsResponse{
+		Fields:    rules.Fields,
+		Functions: rules.Functions,
+	})
+}
+
+// PutRule PUT /rules/:strategy/:name: создание или замена правила
+func (h *Rules) PutRule(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	strategy, name := c.Param("strategy"), c.Param("name")
+	if err := models.ValidateRuleKey(strategy, name); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	var req models.PutRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid rule", err)
+	}
+
+	rule, err := h.Service.PutRule(c.Request().Context(), strategy, name, req)
+	if err != nil {
+		return problem.Respond(c, "Failed to save rule", err)
+	}
+
+	return c.JSON(http.StatusOK, models.RuleResponse{
+		Rule: rule,
+	})
+}
+
+// DeleteRule DELETE /rules/:strategy/:name
+func (h *Rules) DeleteRule(c echo.Context) error {
+	if err := h.Service.DeleteRule(c.Request().Context(), c.Param("strategy"), c.Param("name")); err != nil {
+		return problem.Respond(c, "Failed to delete rule", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// EvaluateRules GET /rules/:strategy/evaluate: решение стратегии по свечам инструмента за период
+func (h *Rules) EvaluateRules(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	var req models.GetCandlesRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	evaluation, err := h.Service.EvaluateRules(c.Request().Context(), c.Param("strategy"), req)
+	if err != nil {
+		return problem.Respond(c, "Failed to evaluate rules", err)
+	}
+
+	return c.JSON(http.StatusOK, models.RuleEvaluationResponse{
+		Evaluation: evaluation,
+	})
+}
+
+// GetEvaluations GET /rules/:strategy/evaluations: последние решения стратегии по инструментам
+func (h *Rules) GetEvaluations(c echo.Context) error {
+	evaluations, err := h.Service.ListRuleEvaluations(c.Request().Context(), c.Param("strategy"))
+	if err != nil {
+		return problem.Respond(c, "Failed to list rule evaluations", err)
+	}
+
+	return c.JSON(http.StatusOK, models.RuleEvaluationsResponse{
+		Evaluations: evaluations,
+	})
+}
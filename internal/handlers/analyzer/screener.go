@@ -0,0 +1,42 @@
+package analyzer
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/models"
+	"net/http"
+)
+
+type Screener interface {
+	Screen(ctx context.Context, req models.ScreenerRequest) (models.ScreenerResponse, error)
+}
+
+type ScreenerHandler struct {
+	Service Screener
+}
+
+func NewScreenerHandler(service Screener) *ScreenerHandler {
+	return &ScreenerHandler{
+		Service: service,
+	}
+}
+
+// Screen GET /screener: рейтинг инструментов по полю сигнала; ошибки по отдельным инструментам — в errors
+func (h *ScreenerHandler) Screen(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	var req models.ScreenerRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	response, err := h.Service.Screen(c.Request().Context(), req)
+	if err != nil {
+		return problem.Respond(c, "Failed to screen instruments", err)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
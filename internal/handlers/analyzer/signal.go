@@ -1,14 +1,17 @@
 package analyzer
 
 import (
+	"context"
 	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
 	"mamonolitmvp/internal/math/price_analysis"
 	"mamonolitmvp/internal/models"
 	"net/http"
 )
 
 type StockExchange interface {
-	GetTotalSignal(instrumentInfo map[string]any) (string, price_analysis.Signal, []price_analysis.Fdi, []price_analysis.NormalizeFdi, []float64, error)
+	GetTotalSignal(ctx context.Context, req models.GetSignalsRequest) (string, price_analysis.Signal, []price_analysis.Fdi, []price_analysis.NormalizeFdi, []float64, price_analysis.RegimeAnalysis, error)
+	ListIndicators() []price_analysis.IndicatorSpec
 }
 
 type Signal struct {
@@ -23,64 +26,37 @@ func NewSignalHandler(service StockExchange) *Signal {
 
 func (h *Signal) GetSignals(c echo.Context) error {
 	c.Request().Header.Set("Content-Type", "application/json")
-	var req models.GetCandlesRequest
+	var req models.GetSignalsRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{
-			"error": "Invalid request format",
-		})
+		return problem.BadRequest(c, "Invalid request format")
 	}
-
-	instrumentInfo := map[string]any{
-		"figi":         req.Figi,
-		"from":         req.From,
-		"to":           req.To,
-		"interval":     req.Interval,
-		"instrumentId": req.InstrumentId,
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
 	}
 
-	ticker, signal, fdiWind, normFdiWind, hurstWind, err := h.Service.GetTotalSignal(instrumentInfo)
+	ticker, signal, fdiWind, normFdiWind, hurstWind, regimes, err := h.Service.GetTotalSignal(c.Request().Context(), req)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, echo.Map{
-			"error": "Failed to fetch all candles",
-			"err":   err.Error(),
+		return problem.Respond(c, "Failed to fetch all candles", err)
+	}
+
+	return c.JSON(http.StatusOK, models.NewGetSignalsResponse(ticker, signal, fdiWind, normFdiWind, hurstWind, regimes))
+}
+
+func (h *Signal) GetIndicators(c echo.Context) error {
+	specs := h.Service.ListIndicators()
+
+	indicators := make([]map[string]any, 0, len(specs))
+	for _, spec := range specs {
+		indicators = append(indicators, map[string]any{
+			"Name":    spec.Name,
+			"Inputs":  spec.Inputs,
+			"Params":  spec.Params,
+			"Outputs": spec.Outputs,
+			"WarmUp":  spec.WarmUp(spec.Params),
 		})
 	}
 
 	return c.JSON(http.StatusOK, echo.Map{
-		"TotalPrices": signal.Total,
-		"ShortSma":    signal.ShortSMA,
-		"LongSma":     signal.LongSMA,
-		"TrendFactor": signal.TrendFactor,
-		"ticker":      ticker,
-		"Hurst":       signal.Hurst,
-		"MDFA": map[string]any{
-			"LogFq": signal.LogFq,
-			"Hq":    signal.Hq,
-			"LogS":  signal.LogS,
-		},
-		"MFSpectrum": map[string]any{
-			"Qsorted": signal.Qsorted,
-			"Tau":     signal.Tau,
-			"Alpha":   signal.Alpha,
-			"FAlpha":  signal.FAlpha,
-		},
-
-		"FDIAnalysis": map[string]any{
-			"Width":     signal.Width,
-			"Asym":      signal.Asym,
-			"Curvature": signal.Curvature,
-			"FDI":       signal.Fdi,
-		},
-		"NormFdi": map[string]any{
-			"Width":     signal.NormFdi,
-			"Asym":      signal.NormAsym,
-			"Curvature": signal.NormCurvature,
-			"FDI":       signal.NormFdi,
-		},
-		"Window": map[string]any{
-			"FdiWind":   fdiWind,
-			"HurstWind": hurstWind,
-			"NormFdi":   normFdiWind,
-		},
+		"indicators": indicators,
 	})
 }
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"context"
+	"mamonolitmvp/internal/math/price_analysis"
+	"mamonolitmvp/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+type stubExchange struct {
+	req models.GetSignalsRequest
+}
+
+func (s *stubExchange) GetTotalSignal(_ context.Context, req models.GetSignalsRequest) (string, price_analysis.Signal, []price_analysis.Fdi, []price_analysis.NormalizeFdi, []float64, price_analysis.RegimeAnalysis, error) {
+	s.req = req
+	return "SBER", price_analysis.Signal{}, nil, nil, nil, price_analysis.RegimeAnalysis{}, nil
+}
+
+func (s *stubExchange) ListIndicators() []price_analysis.IndicatorSpec {
+	return nil
+}
+
+func getSignals(t *testing.T, query, body string) (*httptest.ResponseRecorder, *stubExchange) {
+	t.Helper()
+	stub := &stubExchange{}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sig/getSignals?"+query, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	if err := NewSignalHandler(stub).GetSignals(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("GetSignals: %v", err)
+	}
+	return rec, stub
+}
+
+const signalsRange = "instrumentId=uid&interval=CANDLE_INTERVAL_DAY&from=2025-01-01T00:00:00Z&to=2025-03-01T00:00:00Z"
+
+func TestGetSignalsBindsQuery(t *testing.T) {
+	rec, stub := getSignals(t, signalsRange+
+		"&indicators=macd:fast=12,slow=26&indicators=rsi&trendingHurst=0.6&turbulentWidth=5", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	wantIndicators := []price_analysis.IndicatorParams{
+		{Name: "macd", Params: map[string]float64{"fast": 12, "slow": 26}},
+		{Name: "rsi"},
+	}
+	if !reflect.DeepEqual(stub.req.Indicators, wantIndicators) {
+		t.Errorf("indicators = %+v, want %+v", stub.req.Indicators, wantIndicators)
+	}
+	if stub.req.Regime.TrendingHurst != 0.6 || stub.req.Regime.TurbulentWidth != 5 {
+		t.Errorf("regime = %+v, want trendingHurst 0.6 and turbulentWidth 5", stub.req.Regime)
+	}
+	if stub.req.InstrumentId != "uid" || stub.req.Interval != "CANDLE_INTERVAL_DAY" {
+		t.Errorf("candles request = %+v", stub.req.GetCandlesRequest)
+	}
+}
+
+func TestGetSignalsBindsBody(t *testing.T) {
+	rec, stub := getSignals(t, signalsRange,
+		`{"indicators":[{"name":"macd","params":{"fast":10}}],"regime":{"minConfidence":0.7}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if len(stub.req.Indicators) != 1 || stub.req.Indicators[0].Params["fast"] != 10 {
+		t.Errorf("indicators = %+v", stub.req.Indicators)
+	}
+	if stub.req.Regime.MinConfidence != 0.7 {
+		t.Errorf("regime = %+v", stub.req.Regime)
+	}
+}
+
+func TestGetSignalsRejectsBadQuery(t *testing.T) {
+	for _, query := range []string{
+		"indicators=:fast=1",
+		"indicators=macd:fast",
+		"indicators=macd:fast=x",
+		"trendingHurst=abc",
+		"trendingHurst=0.4&meanRevertingHurst=0.5",
+	} {
+		t.Run(query, func(t *testing.T) {
+			rec, _ := getSignals(t, signalsRange+"&"+query, "")
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status %d, want 400: %s", rec.Code, rec.Body)
+			}
+		})
+	}
+}
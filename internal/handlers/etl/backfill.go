@@ -0,0 +1,67 @@
+package etl
+
+import (
+	"context"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/internal/services"
+	"net/http"
+)
+
+type Backfiller interface {
+	Start(ctx context.Context, req models.BackfillRequest) (models.BackfillCheckpoint, error)
+	Status(ctx context.Context, instrumentUID, interval string) (models.BackfillCheckpoint, error)
+}
+
+type BackfillHandler struct {
+	Service Backfiller
+}
+
+func NewBackfillHandler(service Backfiller) *BackfillHandler {
+	return &BackfillHandler{
+		Service: service,
+	}
+}
+
+func (h *BackfillHandler) StartBackfill(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	var req models.BackfillRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	checkpoint, err := h.Service.Start(c.Request().Context(), req)
+	if errors.Is(err, services.ErrBackfillRunning) {
+		return problem.New(c, http.StatusConflict, "Backfill is already running", err.Error())
+	}
+	if err != nil {
+		return problem.Respond(c, "Failed to start backfill", err)
+	}
+
+	return c.JSON(http.StatusAccepted, models.BackfillResponse{
+		Checkpoint: checkpoint,
+	})
+}
+
+func (h *BackfillHandler) GetBackfill(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+
+	instrumentID, interval := c.QueryParam("instrumentId"), c.QueryParam("interval")
+	if instrumentID == "" || interval == "" {
+		return problem.BadRequest(c, "instrumentId and interval are required")
+	}
+
+	checkpoint, err := h.Service.Status(c.Request().Context(), instrumentID, interval)
+	if err != nil {
+		return problem.Respond(c, "Backfill not found", err)
+	}
+
+	return c.JSON(http.StatusOK, models.BackfillResponse{
+		Checkpoint: checkpoint,
+	})
+}
@@ -0,0 +1,65 @@
+package etl
+
+import (
+	"context"
+	"mamonolitmvp/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type stubBackfiller struct {
+	instrumentUID, interval string
+}
+
+func (s *stubBackfiller) Start(_ context.Context, req models.BackfillRequest) (models.BackfillCheckpoint, error) {
+	return models.BackfillCheckpoint{InstrumentId: req.InstrumentId, Interval: req.Interval}, nil
+}
+
+func (s *stubBackfiller) Status(_ context.Context, instrumentUID, interval string) (models.BackfillCheckpoint, error) {
+	s.instrumentUID, s.interval = instrumentUID, interval
+	if instrumentUID != "uid" {
+		return models.BackfillCheckpoint{}, gorm.ErrRecordNotFound
+	}
+	return models.BackfillCheckpoint{InstrumentId: instrumentUID, Interval: interval}, nil
+}
+
+func getBackfill(t *testing.T, query string) (*httptest.ResponseRecorder, *stubBackfiller) {
+	t.Helper()
+	stub := &stubBackfiller{}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/backfill?"+query, nil)
+	rec := httptest.NewRecorder()
+	if err := NewBackfillHandler(stub).GetBackfill(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("GetBackfill: %v", err)
+	}
+	return rec, stub
+}
+
+func TestGetBackfillReadsInstrumentId(t *testing.T) {
+	rec, stub := getBackfill(t, "instrumentId=uid&interval=CANDLE_INTERVAL_HOUR")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if stub.instrumentUID != "uid" || stub.interval != "CANDLE_INTERVAL_HOUR" {
+		t.Errorf("Status(%q, %q), want uid and CANDLE_INTERVAL_HOUR", stub.instrumentUID, stub.interval)
+	}
+}
+
+func TestGetBackfillRequiresQuery(t *testing.T) {
+	for _, query := range []string{"", "interval=CANDLE_INTERVAL_HOUR", "instrument_id=uid&interval=CANDLE_INTERVAL_HOUR", "instrumentId=uid"} {
+		rec, _ := getBackfill(t, query)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("query %q: status %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestGetBackfillNotFound(t *testing.T) {
+	rec, _ := getBackfill(t, "instrumentId=other&interval=CANDLE_INTERVAL_HOUR")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", rec.Code)
+	}
+}
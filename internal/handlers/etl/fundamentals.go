@@ -0,0 +1,61 @@
+package etl
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/math/coefficients_calculation"
+	"mamonolitmvp/internal/models"
+	"net/http"
+)
+
+type FundamentalsProvider interface {
+	SyncFundamentals(ctx context.Context, assetUIDs []string) ([]models.AssetFundamental, error)
+	GetFundamentals(ctx context.Context, instrumentUID string) (models.AssetFundamental, coefficients_calculation.FinancialRatio, error)
+}
+
+type FundamentalsHandler struct {
+	Service FundamentalsProvider
+}
+
+func NewFundamentalsHandler(service FundamentalsProvider) *FundamentalsHandler {
+	return &FundamentalsHandler{
+		Service: service,
+	}
+}
+
+func (h *FundamentalsHandler) SyncFundamentals(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	var req models.GetAssetFundamentalsRequest
+	if err := c.Bind(&req); err != nil || len(req.Assets) == 0 {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+
+	fundamentals, err := h.Service.SyncFundamentals(c.Request().Context(), req.Assets)
+	if err != nil {
+		return problem.Respond(c, "Failed to fetch fundamentals", err)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"fundamentals": fundamentals,
+	})
+}
+
+func (h *FundamentalsHandler) GetFundamentals(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	instrumentID := c.QueryParam("instrumentId")
+	if instrumentID == "" {
+		return problem.BadRequest(c, "instrumentId is required")
+	}
+
+	fundamental, ratio, err := h.Service.GetFundamentals(c.Request().Context(), instrumentID)
+	if err != nil {
+		return problem.Respond(c, "Invalid uid, not found fundamentals", err)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"instrumentId": instrumentID,
+		"fundamentals": fundamental,
+		"ratios":       ratio,
+	})
+}
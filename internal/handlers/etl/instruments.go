@@ -0,0 +1,108 @@
+package etl
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/models"
+	"net/http"
+)
+
+type InstrumentProvider interface {
+	ListInstruments(ctx context.Context, req models.ListInstrumentsRequest) (models.InstrumentsPage, error)
+	GetInstrument(ctx context.Context, instrumentUID string) (models.Instrument, error)
+	GetInstrumentCandles(ctx context.Context, req models.GetInstrumentCandlesRequest) (models.CandlesPage, error)
+	GetClosePrice(ctx context.Context, instrumentUID string) (models.ClosePrice, error)
+	SyncInstruments(ctx context.Context, types []string) (map[string]models.UpsertStats, error)
+}
+
+type InstrumentHandler struct {
+	Service InstrumentProvider
+}
+
+func NewInstrumentHandler(service InstrumentProvider) *InstrumentHandler {
+	return &InstrumentHandler{
+		Service: service,
+	}
+}
+
+// ListInstruments GET /instruments?ticker&figi&limit&offset
+func (h *InstrumentHandler) ListInstruments(c echo.Context) error {
+	var req models.ListInstrumentsRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	page, err := h.Service.ListInstruments(c.Request().Context(), req)
+	if err != nil {
+		return problem.Respond(c, "Failed to list instruments", err)
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// SyncInstruments POST /instruments/sync?type=bond&type=etf: обновляет каталог из Tinkoff, по умолчанию все типы
+func (h *InstrumentHandler) SyncInstruments(c echo.Context) error {
+	var req models.SyncInstrumentsRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	synced, err := h.Service.SyncInstruments(c.Request().Context(), req.Types)
+	if err != nil {
+		return problem.Respond(c, "Failed to sync instruments", err)
+	}
+
+	return c.JSON(http.StatusOK, models.SyncInstrumentsResponse{
+		Synced: synced,
+	})
+}
+
+// GetInstrument GET /instruments/{uid}
+func (h *InstrumentHandler) GetInstrument(c echo.Context) error {
+	instrument, err := h.Service.GetInstrument(c.Request().Context(), c.Param("uid"))
+	if err != nil {
+		return problem.Respond(c, "Failed to get instrument", err)
+	}
+
+	return c.JSON(http.StatusOK, instrument)
+}
+
+// GetCandles GET /instruments/{uid}/candles?from&to&interval&limit&offset
+func (h *InstrumentHandler) GetCandles(c echo.Context) error {
+	var req models.GetInstrumentCandlesRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+
+	req.InstrumentId = c.Param("uid")
+	if req.Interval == "" {
+		req.Interval = "CANDLE_INTERVAL_DAY"
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	page, err := h.Service.GetInstrumentCandles(c.Request().Context(), req)
+	if err != nil {
+		return problem.Respond(c, "Failed to get candles", err)
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// GetClosePrice GET /instruments/{uid}/close
+func (h *InstrumentHandler) GetClosePrice(c echo.Context) error {
+	closePrice, err := h.Service.GetClosePrice(c.Request().Context(), c.Param("uid"))
+	if err != nil {
+		return problem.Respond(c, "Failed to get close price", err)
+	}
+
+	return c.JSON(http.StatusOK, closePrice)
+}
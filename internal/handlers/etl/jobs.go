@@ -0,0 +1,74 @@
+package etl
+
+import (
+	"context"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/internal/services"
+	"net/http"
+)
+
+type JobScheduler interface {
+	List(ctx context.Context) []models.JobState
+	Trigger(ctx context.Context, name string) (models.JobState, error)
+	Pause(ctx context.Context, name string) (models.JobState, error)
+	Resume(ctx context.Context, name string) (models.JobState, error)
+}
+
+type JobHandler struct {
+	Service JobScheduler
+}
+
+func NewJobHandler(service JobScheduler) *JobHandler {
+	return &JobHandler{
+		Service: service,
+	}
+}
+
+// ListJobs GET /jobs: задачи планировщика и их последние запуски
+func (h *JobHandler) ListJobs(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.JobsResponse{
+		Jobs: h.Service.List(c.Request().Context()),
+	})
+}
+
+// RunJob POST /jobs/:name/run: внеочередной запуск задачи
+func (h *JobHandler) RunJob(c echo.Context) error {
+	job, err := h.Service.Trigger(c.Request().Context(), c.Param("name"))
+	if errors.Is(err, services.ErrJobRunning) {
+		return problem.New(c, http.StatusConflict, "Job is already running", err.Error())
+	}
+	if err != nil {
+		return problem.Respond(c, "Failed to run job", err)
+	}
+
+	return c.JSON(http.StatusAccepted, models.JobResponse{
+		Job: job,
+	})
+}
+
+// PauseJob POST /jobs/:name/pause: остановка запусков по расписанию
+func (h *JobHandler) PauseJob(c echo.Context) error {
+	job, err := h.Service.Pause(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return problem.Respond(c, "Failed to pause job", err)
+	}
+
+	return c.JSON(http.StatusOK, models.JobResponse{
+		Job: job,
+	})
+}
+
+// ResumeJob POST /jobs/:name/resume: возобновление запусков по расписанию
+func (h *JobHandler) ResumeJob(c echo.Context) error {
+	job, err := h.Service.Resume(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return problem.Respond(c, "Failed to resume job", err)
+	}
+
+	return c.JSON(http.StatusOK, models.JobResponse{
+		Job: job,
+	})
+}
@@ -0,0 +1,65 @@
+package etl
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/models"
+	"net/http"
+)
+
+type MarketDataStreamer interface {
+	Status(ctx context.Context) models.StreamStatus
+	Subscribe(ctx context.Context, subscriptions models.StreamSubscriptions) models.StreamStatus
+	LastPrice(ctx context.Context, instrumentUID string) (models.LastPrice, error)
+	OrderBook(ctx context.Context, instrumentUID string) (models.OrderBook, error)
+}
+
+type StreamHandler struct {
+	Service MarketDataStreamer
+}
+
+func NewStreamHandler(service MarketDataStreamer) *StreamHandler {
+	return &StreamHandler{
+		Service: service,
+	}
+}
+
+// GetStream GET /stream: состояние потока рыночных данных
+func (h *StreamHandler) GetStream(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.Service.Status(c.Request().Context()))
+}
+
+// SetSubscriptions PUT /stream/subscriptions: заменяет подписки потока
+func (h *StreamHandler) SetSubscriptions(c echo.Context) error {
+	c.Request().Header.Set("Content-Type", "application/json")
+	var req models.StreamSubscriptions
+	if err := c.Bind(&req); err != nil {
+		return problem.BadRequest(c, "Invalid request format")
+	}
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, "Invalid request format", err)
+	}
+
+	return c.JSON(http.StatusOK, h.Service.Subscribe(c.Request().Context(), req))
+}
+
+// GetLastPrice GET /instruments/:uid/last-price: последняя цена из потока
+func (h *StreamHandler) GetLastPrice(c echo.Context) error {
+	price, err := h.Service.LastPrice(c.Request().Context(), c.Param("uid"))
+	if err != nil {
+		return problem.Respond(c, "Failed to get last price", err)
+	}
+
+	return c.JSON(http.StatusOK, price)
+}
+
+// GetOrderBook GET /instruments/:uid/orderbook: последний стакан из потока
+func (h *StreamHandler) GetOrderBook(c echo.Context) error {
+	orderBook, err := h.Service.OrderBook(c.Request().Context(), c.Param("uid"))
+	if err != nil {
+		return problem.Respond(c, "Failed to get order book", err)
+	}
+
+	return c.JSON(http.StatusOK, orderBook)
+}
@@ -0,0 +1,316 @@
+package openapi
+
+import (
+	"mamonolitmvp/internal/handlers/problem"
+	"mamonolitmvp/internal/models"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const Version = "3.0.3"
+
+// Operation описание одного маршрута API. Query, Body и Response — значения типов,
+// по которым через reflect строятся параметры и схемы; nil — без схемы.
+type Operation struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Query    any
+	Body     any
+	Status   int
+	Response any
+}
+
+type Info struct {
+	Title   string
+	Version string
+}
+
+// Document собирает OpenAPI 3 документ; именованные структуры выносятся в components/schemas
+func Document(info Info, ops []Operation) map[string]any {
+	g := &generator{schemas: make(map[string]any)}
+	problemRef := g.schema(reflect.TypeOf(problem.Problem{}))
+
+	paths := make(map[string]any)
+	for _, op := range ops {
+		item, ok := paths[op.Path].(map[string]any)
+		if !ok {
+			item = make(map[string]any)
+			paths[op.Path] = item
+		}
+
+		operation := map[string]any{
+			"summary":     op.Summary,
+			"operationId": operationID(op),
+		}
+		if op.Tag != "" {
+			operation["tags"] = []string{op.Tag}
+		}
+		params := pathParameters(op.Path)
+		if op.Query != nil {
+			params = append(params, g.parameters(reflect.TypeOf(op.Query))...)
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+		if op.Body != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.Body))},
+				},
+			}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		responseSchema := map[string]any{"type": "object"}
+		if op.Response != nil {
+			responseSchema = g.schema(reflect.TypeOf(op.Response))
+		}
+		response := map[string]any{
+			"description": http.StatusText(status),
+		}
+		if status != http.StatusNoContent {
+			response["content"] = map[string]any{
+				"application/json": map[string]any{"schema": responseSchema},
+			}
+		}
+		operation["responses"] = map[string]any{
+			strconv.Itoa(status): response,
+			"default": map[string]any{
+				"description": "Error",
+				"content": map[string]any{
+					problem.ContentType: map[string]any{"schema": problemRef},
+				},
+			},
+		}
+
+		item[strings.ToLower(op.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": Version,
+		"info": map[string]any{
+			"title":   info.Title,
+			"version": info.Version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": g.schemas,
+		},
+	}
+}
+
+// Handler отдает документ, собранный один раз при старте
+func Handler(doc map[string]any) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, doc)
+	}
+}
+
+type generator struct {
+	schemas map[string]any
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	quotationType  = reflect.TypeOf(models.Quotation{})
+	moneyValueType = reflect.TypeOf(models.MoneyValue{})
+
+	bindUnmarshalerType = reflect.TypeOf((*echo.BindUnmarshaler)(nil)).Elem()
+)
+
+func (g *generator) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case quotationType, moneyValueType:
+		// units сериализуются строкой, как в Tinkoff API
+		properties := map[string]any{
+			"units": map[string]any{"type": "string", "format": "int64"},
+			"nano":  map[string]any{"type": "integer", "format": "int32"},
+		}
+		if t == moneyValueType {
+			properties["currency"] = map[string]any{"type": "string"}
+		}
+		return g.named(t, map[string]any{"type": "object", "properties": properties})
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.named(t, g.object(t))
+	}
+	return map[string]any{}
+}
+
+// named регистрирует схему именованного типа в components и возвращает ссылку на нее
+func (g *generator) named(t reflect.Type, build map[string]any) map[string]any {
+	if t.Name() == "" {
+		return build
+	}
+
+	name := schemaName(t)
+	if _, ok := g.schemas[name]; !ok {
+		g.schemas[name] = build
+	}
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func (g *generator) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	g.fields(t, func(name string, f reflect.StructField) {
+		properties[name] = g.schema(f.Type)
+	})
+	return map[string]any{"type": "object", "properties": properties}
+}
+
+// fields обходит поля структуры так же, как encoding/json: встроенные структуры без тега раскрываются
+func (g *generator) fields(t reflect.Type, visit func(name string, f reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		if f.Anonymous && f.Tag.Get("json") == "" && f.Type.Kind() == reflect.Struct {
+			g.fields(f.Type, visit)
+			continue
+		}
+		visit(name, f)
+	}
+}
+
+// parameters параметры запроса по тегам query. Как и echo, раскрывает поля-структуры без тега;
+// типы с UnmarshalParam передаются строкой.
+func (g *generator) parameters(t reflect.Type) []any {
+	var params []any
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := f.Tag.Get("query")
+			if name == "" {
+				if f.Type.Kind() == reflect.Struct && !paramUnmarshaler(f.Type) {
+					walk(f.Type)
+				}
+				continue
+			}
+			if name == "-" {
+				continue
+			}
+
+			param := map[string]any{
+				"name":   name,
+				"in":     "query",
+				"schema": g.paramSchema(f.Type),
+			}
+			if f.Type.Kind() == reflect.Slice {
+				param["style"] = "form"
+				param["explode"] = true
+			}
+			params = append(params, param)
+		}
+	}
+	walk(t)
+	return params
+}
+
+func (g *generator) paramSchema(t reflect.Type) map[string]any {
+	switch {
+	case paramUnmarshaler(t):
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Slice && paramUnmarshaler(t.Elem()):
+		return map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	}
+	return g.schema(t)
+}
+
+// paramUnmarshaler разбирает ли тип значение параметра сам (echo.BindUnmarshaler)
+func paramUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(bindUnmarshalerType)
+}
+
+// pathParameters обязательные параметры пути вида {uid}
+func pathParameters(path string) []any {
+	var params []any
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, map[string]any{
+				"name":     strings.Trim(segment, "{}"),
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+	}
+	return params
+}
+
+func jsonName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+// schemaName имя схемы с пакетом, чтобы одинаковые имена из разных пакетов не совпадали
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	if pkg == "" || pkg == "models" {
+		return t.Name()
+	}
+	return pkg + "." + t.Name()
+}
+
+func operationID(op Operation) string {
+	parts := strings.FieldsFunc(op.Path, func(r rune) bool { return r == '/' || r == '{' || r == '}' })
+	id := strings.ToLower(op.Method)
+	for _, part := range parts {
+		if part == "api" || part == "v1" {
+			continue
+		}
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
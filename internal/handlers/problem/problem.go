@@ -0,0 +1,120 @@
+package problem
+
+import (
+	"context"
+	"errors"
+	"log"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/pkg/http_client"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const ContentType = "application/problem+json"
+
+// statusClientClosedRequest клиент закрыл соединение до ответа (nginx 499)
+const statusClientClosedRequest = 499
+
+// Problem ответ об ошибке в формате RFC 7807
+type Problem struct {
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	Status      int    `json:"status"`
+	Detail      string `json:"detail,omitempty"`
+	Instance    string `json:"instance,omitempty"`
+	Code        string `json:"code,omitempty"`
+	TinkoffCode string `json:"tinkoffCode,omitempty"`
+	// Errors ошибки полей для невалидного запроса
+	Errors []models.FieldError `json:"errors,omitempty"`
+}
+
+// Respond отвечает ошибкой со статусом по ее виду.
+// Ошибки авторизации в Tinkoff — проблема токена сервера, а не клиента, поэтому отдаются как 502.
+// Текст ошибки со статусом 5xx может раскрыть адреса, SQL и ответы Tinkoff: он только пишется в лог,
+// а клиент получает общее описание статуса.
+func Respond(c echo.Context, title string, err error) error {
+	status, code := Classify(err)
+	if retryAfter := http_client.RetryAfter(err); retryAfter > 0 {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	detail := err.Error()
+	if status >= http.StatusInternalServerError {
+		log.Printf("%s: %v", title, err)
+		detail = http.StatusText(status)
+	}
+
+	p := Problem{
+		Type:        "about:blank",
+		Title:       title,
+		Status:      status,
+		Detail:      detail,
+		Instance:    c.Request().URL.Path,
+		Code:        code,
+		TinkoffCode: http_client.TinkoffCode(err),
+	}
+
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		p.Errors = validationErr.Errors
+	}
+
+	return write(c, p)
+}
+
+// BadRequest ответ 400 на некорректный запрос
+func BadRequest(c echo.Context, detail string) error {
+	return New(c, http.StatusBadRequest, "Invalid request format", detail)
+}
+
+func New(c echo.Context, status int, title, detail string) error {
+	return write(c, Problem{
+		Type:     "about:blank",
+		Title:    title,
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request().URL.Path,
+	})
+}
+
+// Classify HTTP статус и код ошибки для ответа
+func Classify(err error) (int, string) {
+	var urlErr *url.Error
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest, "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "deadline_exceeded"
+	case errors.Is(err, apperr.ErrInvalidArgument):
+		return http.StatusBadRequest, "invalid_argument"
+	case errors.Is(err, apperr.ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound, "not_found"
+	case errors.Is(err, apperr.ErrRateLimited):
+		return http.StatusTooManyRequests, "rate_limited"
+	case errors.Is(err, apperr.ErrUnauthenticated):
+		return http.StatusBadGateway, "upstream_unauthenticated"
+	case errors.Is(err, apperr.ErrPermissionDenied):
+		return http.StatusBadGateway, "upstream_permission_denied"
+	case errors.Is(err, apperr.ErrUnavailable):
+		return http.StatusServiceUnavailable, "upstream_unavailable"
+	case errors.Is(err, apperr.ErrInternal):
+		return http.StatusBadGateway, "upstream_error"
+	case errors.As(err, &urlErr):
+		if urlErr.Timeout() {
+			return http.StatusGatewayTimeout, "upstream_timeout"
+		}
+		return http.StatusBadGateway, "upstream_unreachable"
+	}
+	return http.StatusInternalServerError, "internal"
+}
+
+func write(c echo.Context, p Problem) error {
+	c.Response().Header().Set(echo.HeaderContentType, ContentType)
+	return c.JSON(p.Status, p)
+}
@@ -0,0 +1,101 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func respond(t *testing.T, err error) (*httptest.ResponseRecorder, Problem) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/risk", nil)
+	rec := httptest.NewRecorder()
+	if err := Respond(echo.New().NewContext(req, rec), "Failed", err); err != nil {
+		t.Fatalf("Respond: %v", err)
+	}
+
+	var p Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	return rec, p
+}
+
+func TestRespondStatusByKind(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"invalid argument", apperr.InvalidArgument("bad interval"), http.StatusBadRequest, "invalid_argument"},
+		{"not found", fmt.Errorf("instrument: %w", apperr.ErrNotFound), http.StatusNotFound, "not_found"},
+		{"record not found", gorm.ErrRecordNotFound, http.StatusNotFound, "not_found"},
+		{"rate limited", apperr.ErrRateLimited, http.StatusTooManyRequests, "rate_limited"},
+		{"unauthenticated", apperr.ErrUnauthenticated, http.StatusBadGateway, "upstream_unauthenticated"},
+		{"unavailable", apperr.ErrUnavailable, http.StatusServiceUnavailable, "upstream_unavailable"},
+		{"upstream internal", apperr.ErrInternal, http.StatusBadGateway, "upstream_error"},
+		{"canceled", context.Canceled, statusClientClosedRequest, "canceled"},
+		{"deadline", context.DeadlineExceeded, http.StatusGatewayTimeout, "deadline_exceeded"},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, "internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, p := respond(t, tt.err)
+			if rec.Code != tt.status || p.Status != tt.status || p.Code != tt.code {
+				t.Errorf("status %d/%d code %q, want %d %q", rec.Code, p.Status, p.Code, tt.status, tt.code)
+			}
+			if got := rec.Header().Get(echo.HeaderContentType); got != ContentType {
+				t.Errorf("content type %q, want %q", got, ContentType)
+			}
+			if p.Instance != "/api/v1/risk" {
+				t.Errorf("instance %q", p.Instance)
+			}
+		})
+	}
+}
+
+func TestRespondHidesServerErrorDetail(t *testing.T) {
+	secret := errors.New(`pq: relation "candles" does not exist at host=10.0.0.5`)
+	for _, err := range []error{
+		secret,
+		fmt.Errorf("%w: %w", apperr.ErrInternal, secret),
+		fmt.Errorf("%w: %w", apperr.ErrUnavailable, secret),
+	} {
+		rec, p := respond(t, err)
+		if strings.Contains(rec.Body.String(), "10.0.0.5") || strings.Contains(rec.Body.String(), "candles") {
+			t.Errorf("status %d response leaks error: %s", rec.Code, rec.Body)
+		}
+		if p.Detail != http.StatusText(rec.Code) {
+			t.Errorf("detail %q, want %q", p.Detail, http.StatusText(rec.Code))
+		}
+	}
+}
+
+func TestRespondKeepsClientErrorDetail(t *testing.T) {
+	var validation models.ValidationError
+	validation.Add("interval", "unknown interval %q", "X")
+
+	_, p := respond(t, validation.Err())
+	if p.Status != http.StatusBadRequest || !strings.Contains(p.Detail, `unknown interval "X"`) {
+		t.Errorf("problem = %+v, want 400 with the validation message", p)
+	}
+	if len(p.Errors) != 1 || p.Errors[0].Field != "interval" {
+		t.Errorf("errors = %+v, want the interval field", p.Errors)
+	}
+
+	_, p = respond(t, fmt.Errorf("instrument uid-1: %w", apperr.ErrNotFound))
+	if p.Detail != "instrument uid-1: not found" {
+		t.Errorf("detail %q, want the error text", p.Detail)
+	}
+}
@@ -1,2 +1,124 @@
 // Package coefficients_calculation include Financial ratios, risk metrics
 package coefficients_calculation
+
+import "math"
+
+type FinancialRatios struct {
+}
+
+func NewFinancialRatios() *FinancialRatios {
+	return &FinancialRatios{}
+}
+
+// Fundamentals исходные показатели актива. nil означает, что показатель не передан.
+type Fundamentals struct {
+	MarketCapitalization *float64
+	SharesOutstanding    *float64
+	EpsTtm               *float64
+	NetIncomeTtm         *float64
+	EbitdaTtm            *float64
+	EnterpriseValue      *float64
+	TotalDebt            *float64
+
+	PeRatio       *float64
+	PriceToBook   *float64
+	EvToEbitda    *float64
+	Roe           *float64
+	DividendYield *float64
+	DebtToEquity  *float64
+}
+
+// FinancialRatio итоговые коэффициенты актива. Коэффициент, которого нет в источнике и который
+// не вычисляется из исходных показателей (показатель не передан или знаменатель нулевой),
+// равен nil и не попадает в ответ.
+type FinancialRatio struct {
+	PeRatio       *float64 `json:",omitempty"`
+	PriceToBook   *float64 `json:",omitempty"`
+	EvToEbitda    *float64 `json:",omitempty"`
+	Roe           *float64 `json:",omitempty"`
+	DividendYield *float64 `json:",omitempty"`
+	DebtToEquity  *float64 `json:",omitempty"`
+	EarningsYield *float64 `json:",omitempty"`
+}
+
+// PriceToEarnings P/E: капитализация к чистой прибыли
+func (fr *FinancialRatios) PriceToEarnings(marketCap, netIncome float64) float64 {
+	return safeDiv(marketCap, netIncome)
+}
+
+// EvToEbitda EV/EBITDA: стоимость компании к EBITDA
+func (fr *FinancialRatios) EvToEbitda(enterpriseValue, ebitda float64) float64 {
+	return safeDiv(enterpriseValue, ebitda)
+}
+
+// BookValue балансовая стоимость капитала по капитализации и P/B
+func (fr *FinancialRatios) BookValue(marketCap, priceToBook float64) float64 {
+	return safeDiv(marketCap, priceToBook)
+}
+
+// TotalRatios берет коэффициенты из источника и досчитывает недостающие из исходных показателей
+func (fr *FinancialRatios) TotalRatios(f Fundamentals) FinancialRatio {
+	marketCap := given(f.MarketCapitalization)
+	netIncome := given(f.NetIncomeTtm)
+
+	pe := given(f.PeRatio)
+	if math.IsNaN(pe) {
+		pe = fr.PriceToEarnings(marketCap, netIncome)
+	}
+	if math.IsNaN(pe) {
+		pe = fr.PriceToEarnings(safeDiv(marketCap, given(f.SharesOutstanding)), given(f.EpsTtm))
+	}
+
+	evToEbitda := given(f.EvToEbitda)
+	if math.IsNaN(evToEbitda) {
+		evToEbitda = fr.EvToEbitda(given(f.EnterpriseValue), given(f.EbitdaTtm))
+	}
+
+	bookValue := fr.BookValue(marketCap, given(f.PriceToBook))
+	roe := given(f.Roe)
+	if math.IsNaN(roe) {
+		roe = safeDiv(netIncome, bookValue) * 100
+	}
+	debtToEquity := given(f.DebtToEquity)
+	if math.IsNaN(debtToEquity) {
+		debtToEquity = safeDiv(given(f.TotalDebt), bookValue)
+	}
+
+	return FinancialRatio{
+		PeRatio:       known(pe),
+		PriceToBook:   known(given(f.PriceToBook)),
+		EvToEbitda:    known(evToEbitda),
+		Roe:           known(roe),
+		DividendYield: known(given(f.DividendYield)),
+		DebtToEquity:  known(debtToEquity),
+		EarningsYield: known(safeDiv(1, pe) * 100),
+	}
+}
+
+// safeDiv частное a/b; NaN, если знаменатель нулевой, показатель не передан или результат не конечен
+func safeDiv(a, b float64) float64 {
+	if b == 0 {
+		return math.NaN()
+	}
+	v := a / b
+	if math.IsInf(v, 0) {
+		return math.NaN()
+	}
+	return v
+}
+
+// given показатель источника или NaN, если он не передан; NaN распространяется по расчетам
+func given(v *float64) float64 {
+	if v == nil {
+		return math.NaN()
+	}
+	return *v
+}
+
+// known коэффициент для ответа или nil, если он не определен
+func known(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
@@ -1,2 +1,331 @@
 // Package coefficients_calculation include Financial ratios, risk metrics
 package coefficients_calculation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+type RiskMetrics struct {
+}
+
+func NewRiskMetrics() *RiskMetrics {
+	return &RiskMetrics{}
+}
+
+// Risk aggregates every metric computed by TotalRisk. VaR and ES values are
+// expressed as positive per-period losses in log-return units.
+type Risk struct {
+	Total          int
+	Confidence     float64
+	RiskFreeRate   float64
+	PeriodsPerYear float64
+	MeanReturn     float64
+	Volatility     float64
+	Skewness       float64
+	Kurtosis       float64
+	ValueAtRisk
+	ExpectedShortfall
+	Drawdown
+	Ratios
+}
+
+type ValueAtRisk struct {
+	HistoricalVaR    float64
+	ParametricVaR    float64
+	CornishFisherVaR float64
+}
+
+type ExpectedShortfall struct {
+	HistoricalES float64
+	ParametricES float64
+}
+
+type Drawdown struct {
+	MaxDrawdown float64
+	PeakIndex   int
+	TroughIndex int
+	PeakTime    time.Time
+	TroughTime  time.Time
+}
+
+type Ratios struct {
+	AnnualReturn float64
+	Sharpe       float64
+	Sortino      float64
+	Calmar       float64
+}
+
+// LogReturns returns ln(p[i]/p[i-1]) for consecutive prices.
+func (rm *RiskMetrics) LogReturns(prices []float64) ([]float64, error) {
+	if len(prices) < 2 {
+		return nil, fmt.Errorf("not enough data points for returns calculation: %d < 2", len(prices))
+	}
+
+	returns := make([]float64, len(prices)-1)
+	for i := 1; i < len(prices); i++ {
+		if prices[i-1] <= 0 || prices[i] <= 0 {
+			return nil, fmt.Errorf("non-positive price at index %d", i)
+		}
+		returns[i-1] = math.Log(prices[i] / prices[i-1])
+	}
+	return returns, nil
+}
+
+// HistoricalVaR Value-at-Risk по эмпирическому квантилю доходностей
+func (rm *RiskMetrics) HistoricalVaR(returns []float64, confidence float64) (float64, error) {
+	if err := checkInput(returns, confidence); err != nil {
+		return 0, err
+	}
+
+	sorted := slices.Clone(returns)
+	slices.Sort(sorted)
+
+	return -stat.Quantile(1-confidence, stat.Empirical, sorted, nil), nil
+}
+
+// ParametricVaR Value-at-Risk в предположении нормального распределения доходностей
+func (rm *RiskMetrics) ParametricVaR(returns []float64, confidence float64) (float64, error) {
+	if err := checkInput(returns, confidence); err != nil {
+		return 0, err
+	}
+
+	mean, std := stat.MeanStdDev(returns, nil)
+	z := distuv.UnitNormal.Quantile(1 - confidence)
+
+	return -(mean + z*std), nil
+}
+
+// CornishFisherVaR Value-at-Risk с поправкой Корниша-Фишера на асимметрию и эксцесс
+func (rm *RiskMetrics) CornishFisherVaR(returns []float64, confidence float64) (float64, error) {
+	if err := checkInput(returns, confidence); err != nil {
+		return 0, err
+	}
+
+	mean, std := stat.MeanStdDev(returns, nil)
+	s := stat.Skew(returns, nil)
+	k := stat.ExKurtosis(returns, nil)
+	if math.IsNaN(s) || math.IsNaN(k) {
+		s, k = 0, 0
+	}
+
+	z := distuv.UnitNormal.Quantile(1 - confidence)
+	zcf := z +
+		(z*z-1)*s/6 +
+		(z*z*z-3*z)*k/24 -
+		(2*z*z*z-5*z)*s*s/36
+
+	return -(mean + zcf*std), nil
+}
+
+// HistoricalES Expected Shortfall (CVaR): средний убыток за пределами исторического VaR
+func (rm *RiskMetrics) HistoricalES(returns []float64, confidence float64) (float64, error) {
+	varValue, err := rm.HistoricalVaR(returns, confidence)
+	if err != nil {
+		return 0, err
+	}
+
+	var tail float64
+	var count int
+	for _, r := range returns {
+		if r <= -varValue {
+			tail += r
+			count++
+		}
+	}
+	if count == 0 {
+		return varValue, nil
+	}
+
+	return -tail / float64(count), nil
+}
+
+// ParametricES Expected Shortfall для нормального распределения доходностей
+func (rm *RiskMetrics) ParametricES(returns []float64, confidence float64) (float64, error) {
+	if err := checkInput(returns, confidence); err != nil {
+		return 0, err
+	}
+
+	mean, std := stat.MeanStdDev(returns, nil)
+	z := distuv.UnitNormal.Quantile(1 - confidence)
+
+	return -(mean - std*distuv.UnitNormal.Prob(z)/(1-confidence)), nil
+}
+
+// MaxDrawdown максимальная относительная просадка от пика до дна. times может быть nil.
+func (rm *RiskMetrics) MaxDrawdown(prices []float64, times []time.Time) (Drawdown, error) {
+	if len(prices) == 0 {
+		return Drawdown{}, errors.New("no prices provided")
+	}
+	if times != nil && len(times) != len(prices) {
+		return Drawdown{}, fmt.Errorf("prices and times length mismatch: %d != %d", len(prices), len(times))
+	}
+
+	var dd Drawdown
+	peakIdx := 0
+	for i, p := range prices {
+		if p > prices[peakIdx] {
+			peakIdx = i
+		}
+		if prices[peakIdx] <= 0 {
+			continue
+		}
+
+		current := (prices[peakIdx] - p) / prices[peakIdx]
+		if current > dd.MaxDrawdown {
+			dd.MaxDrawdown = current
+			dd.PeakIndex = peakIdx
+			dd.TroughIndex = i
+		}
+	}
+
+	if times != nil {
+		dd.PeakTime = times[dd.PeakIndex]
+		dd.TroughTime = times[dd.TroughIndex]
+	}
+
+	return dd, nil
+}
+
+// AnnualReturn годовая доходность по среднему логарифмическому приросту
+func (rm *RiskMetrics) AnnualReturn(returns []float64, periodsPerYear float64) float64 {
+	if len(returns) == 0 {
+		return 0
+	}
+	return math.Exp(stat.Mean(returns, nil)*periodsPerYear) - 1
+}
+
+func (rm *RiskMetrics) SharpeRatio(returns []float64, riskFreeRate, periodsPerYear float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean, std := stat.MeanStdDev(returns, nil)
+	if std == 0 {
+		return 0
+	}
+
+	excess := mean - riskFreeRate/periodsPerYear
+	return excess / std * math.Sqrt(periodsPerYear)
+}
+
+func (rm *RiskMetrics) SortinoRatio(returns []float64, riskFreeRate, periodsPerYear float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	target := riskFreeRate / periodsPerYear
+
+	var downside float64
+	for _, r := range returns {
+		if r < target {
+			downside += (r - target) * (r - target)
+		}
+	}
+	downside = math.Sqrt(downside / float64(len(returns)))
+	if downside == 0 {
+		return 0
+	}
+
+	excess := stat.Mean(returns, nil) - target
+	return excess / downside * math.Sqrt(periodsPerYear)
+}
+
+func (rm *RiskMetrics) CalmarRatio(annualReturn, maxDrawdown float64) float64 {
+	if maxDrawdown == 0 {
+		return 0
+	}
+	return annualReturn / maxDrawdown
+}
+
+// TotalRisk считает все метрики риска по ряду цен закрытия
+func (rm *RiskMetrics) TotalRisk(prices []float64, times []time.Time, confidence, riskFreeRate, periodsPerYear float64) (Risk, error) {
+	if periodsPerYear <= 0 {
+		return Risk{}, fmt.Errorf("periods per year must be positive: %v", periodsPerYear)
+	}
+
+	returns, err := rm.LogReturns(prices)
+	if err != nil {
+		return Risk{}, err
+	}
+
+	historicalVaR, err := rm.HistoricalVaR(returns, confidence)
+	if err != nil {
+		return Risk{}, err
+	}
+	parametricVaR, err := rm.ParametricVaR(returns, confidence)
+	if err != nil {
+		return Risk{}, err
+	}
+	cornishFisherVaR, err := rm.CornishFisherVaR(returns, confidence)
+	if err != nil {
+		return Risk{}, err
+	}
+
+	historicalES, err := rm.HistoricalES(returns, confidence)
+	if err != nil {
+		return Risk{}, err
+	}
+	parametricES, err := rm.ParametricES(returns, confidence)
+	if err != nil {
+		return Risk{}, err
+	}
+
+	drawdown, err := rm.MaxDrawdown(prices, times)
+	if err != nil {
+		return Risk{}, err
+	}
+
+	mean, std := stat.MeanStdDev(returns, nil)
+	annualReturn := rm.AnnualReturn(returns, periodsPerYear)
+
+	return Risk{
+		Total:          len(prices),
+		Confidence:     confidence,
+		RiskFreeRate:   riskFreeRate,
+		PeriodsPerYear: periodsPerYear,
+		MeanReturn:     mean,
+		Volatility:     std * math.Sqrt(periodsPerYear),
+		Skewness:       zeroIfNaN(stat.Skew(returns, nil)),
+		Kurtosis:       zeroIfNaN(stat.ExKurtosis(returns, nil)),
+		ValueAtRisk: ValueAtRisk{
+			HistoricalVaR:    historicalVaR,
+			ParametricVaR:    parametricVaR,
+			CornishFisherVaR: cornishFisherVaR,
+		},
+		ExpectedShortfall: ExpectedShortfall{
+			HistoricalES: historicalES,
+			ParametricES: parametricES,
+		},
+		Drawdown: drawdown,
+		Ratios: Ratios{
+			AnnualReturn: annualReturn,
+			Sharpe:       rm.SharpeRatio(returns, riskFreeRate, periodsPerYear),
+			Sortino:      rm.SortinoRatio(returns, riskFreeRate, periodsPerYear),
+			Calmar:       rm.CalmarRatio(annualReturn, drawdown.MaxDrawdown),
+		},
+	}, nil
+}
+
+func checkInput(returns []float64, confidence float64) error {
+	if len(returns) < 2 {
+		return fmt.Errorf("not enough returns for risk calculation: %d < 2", len(returns))
+	}
+	if confidence <= 0 || confidence >= 1 {
+		return fmt.Errorf("confidence must be in (0, 1): %v", confidence)
+	}
+	return nil
+}
+
+func zeroIfNaN(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,201 @@
+package coefficients_calculation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// Ряд из 10 доходностей для ручного расчета. Среднее 0.003, сумма квадратов отклонений 0.00721,
+// выборочное σ = sqrt(0.00721/9) = 0.0283039063.
+// Отсортированный ряд: -0.04, -0.03, -0.02, -0.01, 0, 0.01, 0.02, 0.02, 0.03, 0.05.
+var handReturns = []float64{0.01, -0.02, 0.03, -0.01, 0.02, -0.04, 0.05, 0.00, -0.03, 0.02}
+
+// Уровень 0.75: квантиль стандартного нормального распределения z = -0.6744897502
+const handConfidence = 0.75
+
+func assertClose(t *testing.T, name string, got, want, tolerance float64) {
+	t.Helper()
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("%s = %.10f, want %.10f", name, got, want)
+	}
+}
+
+func TestValueAtRisk(t *testing.T) {
+	rm := NewRiskMetrics()
+
+	// Эмпирический квантиль 0.25 из 10 точек — третье по величине значение -0.02
+	historical, err := rm.HistoricalVaR(handReturns, handConfidence)
+	if err != nil {
+		t.Fatalf("HistoricalVaR: %v", err)
+	}
+	assertClose(t, "historical VaR", historical, 0.02, 1e-12)
+
+	// -(μ + z·σ) = -(0.003 - 0.6744897502·0.0283039063)
+	parametric, err := rm.ParametricVaR(handReturns, handConfidence)
+	if err != nil {
+		t.Fatalf("ParametricVaR: %v", err)
+	}
+	assertClose(t, "parametric VaR", parametric, 0.0160906946812, 1e-10)
+
+	// Выборочные асимметрия 0.0235212065 и эксцесс -0.7706010107 (как SKEW и KURT в Excel)
+	// дают z_cf = -0.7317867623, VaR = -(μ + z_cf·σ)
+	cornishFisher, err := rm.CornishFisherVaR(handReturns, handConfidence)
+	if err != nil {
+		t.Fatalf("CornishFisherVaR: %v", err)
+	}
+	assertClose(t, "Cornish-Fisher VaR", cornishFisher, 0.0177124239414, 1e-10)
+}
+
+func TestExpectedShortfall(t *testing.T) {
+	rm := NewRiskMetrics()
+
+	// Хвост за VaR 0.02: -0.04, -0.03, -0.02, среднее -0.03
+	historical, err := rm.HistoricalES(handReturns, handConfidence)
+	if err != nil {
+		t.Fatalf("HistoricalES: %v", err)
+	}
+	assertClose(t, "historical ES", historical, 0.03, 1e-12)
+
+	// -(μ - σ·φ(z)/0.25), φ(z) = 0.3177765727
+	parametric, err := rm.ParametricES(handReturns, handConfidence)
+	if err != nil {
+		t.Fatalf("ParametricES: %v", err)
+	}
+	assertClose(t, "parametric ES", parametric, 0.0329772733340, 1e-10)
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	prices := []float64{100, 110, 99, 120, 90, 108}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	times := make([]time.Time, len(prices))
+	for i := range times {
+		times[i] = start.AddDate(0, 0, i)
+	}
+
+	// Просадка 110 → 99 (10%) меньше, чем 120 → 90 (25%)
+	dd, err := NewRiskMetrics().MaxDrawdown(prices, times)
+	if err != nil {
+		t.Fatalf("MaxDrawdown: %v", err)
+	}
+	assertClose(t, "max drawdown", dd.MaxDrawdown, 0.25, 1e-12)
+	if dd.PeakIndex != 3 || dd.TroughIndex != 4 || !dd.PeakTime.Equal(times[3]) || !dd.TroughTime.Equal(times[4]) {
+		t.Errorf("drawdown = %+v, want peak 3 and trough 4", dd)
+	}
+
+	rising, err := NewRiskMetrics().MaxDrawdown([]float64{1, 2, 3}, nil)
+	if err != nil {
+		t.Fatalf("MaxDrawdown: %v", err)
+	}
+	if rising != (Drawdown{}) {
+		t.Errorf("rising prices drawdown = %+v, want zero", rising)
+	}
+
+	if _, err := NewRiskMetrics().MaxDrawdown(prices, times[:2]); err == nil {
+		t.Error("expected length mismatch error")
+	}
+}
+
+func TestRatios(t *testing.T) {
+	rm := NewRiskMetrics()
+	const riskFree, periods = 0.0252, 252.0
+
+	// (μ - 0.0252/252) / σ · sqrt(252)
+	assertClose(t, "Sharpe", rm.SharpeRatio(handReturns, riskFree, periods), 1.62649184694, 1e-9)
+
+	// Отклонение вниз от цели 0.0001 по всем 10 точкам: sqrt(0.00302004/10) = 0.0173782911
+	assertClose(t, "Sortino", rm.SortinoRatio(handReturns, riskFree, periods), 2.64905638110, 1e-9)
+
+	// exp(0.003·252) - 1 = 1.1297401990, Calmar при просадке 25% — в четыре раза больше
+	annual := rm.AnnualReturn(handReturns, periods)
+	assertClose(t, "annual return", annual, 1.12974019904, 1e-9)
+	assertClose(t, "Calmar", rm.CalmarRatio(annual, 0.25), 4.51896079616, 1e-9)
+	assertClose(t, "Calmar without drawdown", rm.CalmarRatio(annual, 0), 0, 0)
+}
+
+func TestRiskEmptySeries(t *testing.T) {
+	rm := NewRiskMetrics()
+
+	if _, err := rm.LogReturns([]float64{100}); err == nil {
+		t.Error("LogReturns: expected error for a single price")
+	}
+	if _, err := rm.LogReturns([]float64{100, 0}); err == nil {
+		t.Error("LogReturns: expected error for a non-positive price")
+	}
+	if _, err := rm.HistoricalVaR(nil, handConfidence); err == nil {
+		t.Error("HistoricalVaR: expected error for empty returns")
+	}
+	if _, err := rm.ParametricES([]float64{0.01}, handConfidence); err == nil {
+		t.Error("ParametricES: expected error for a single return")
+	}
+	if _, err := rm.CornishFisherVaR(handReturns, 1); err == nil {
+		t.Error("CornishFisherVaR: expected error for confidence 1")
+	}
+	if _, err := rm.MaxDrawdown(nil, nil); err == nil {
+		t.Error("MaxDrawdown: expected error for empty prices")
+	}
+	if _, err := rm.TotalRisk(nil, nil, handConfidence, 0, 252); err == nil {
+		t.Error("TotalRisk: expected error for empty prices")
+	}
+	if _, err := rm.TotalRisk([]float64{1, 2, 3}, nil, handConfidence, 0, 0); err == nil {
+		t.Error("TotalRisk: expected error for zero periods per year")
+	}
+
+	if got := rm.AnnualReturn(nil, 252); got != 0 {
+		t.Errorf("AnnualReturn(nil) = %v, want 0", got)
+	}
+	if got := rm.SharpeRatio(nil, 0, 252); got != 0 {
+		t.Errorf("SharpeRatio(nil) = %v, want 0", got)
+	}
+	if got := rm.SortinoRatio(nil, 0, 252); got != 0 {
+		t.Errorf("SortinoRatio(nil) = %v, want 0", got)
+	}
+}
+
+func TestRiskZeroVolatility(t *testing.T) {
+	rm := NewRiskMetrics()
+	// Цены растут ровно на 1% за период: все доходности ln(1.01), σ = 0
+	prices := []float64{100, 101, 102.01, 103.0301, 104.060401}
+	growth := math.Log(1.01)
+
+	risk, err := rm.TotalRisk(prices, nil, handConfidence, 0, 252)
+	if err != nil {
+		t.Fatalf("TotalRisk: %v", err)
+	}
+
+	assertClose(t, "mean", risk.MeanReturn, growth, 1e-12)
+	assertClose(t, "volatility", risk.Volatility, 0, 1e-12)
+	// Без разброса все VaR и ES равны -μ: убытка нет, есть гарантированный прирост
+	for name, v := range map[string]float64{
+		"historical VaR":     risk.HistoricalVaR,
+		"parametric VaR":     risk.ParametricVaR,
+		"Cornish-Fisher VaR": risk.CornishFisherVaR,
+		"historical ES":      risk.HistoricalES,
+		"parametric ES":      risk.ParametricES,
+	} {
+		assertClose(t, name, v, -growth, 1e-12)
+	}
+	// Асимметрия и эксцесс не определены и обнуляются, коэффициенты без σ и просадки — нули
+	if risk.Skewness != 0 || risk.Kurtosis != 0 {
+		t.Errorf("skewness %v, kurtosis %v, want zeros", risk.Skewness, risk.Kurtosis)
+	}
+	if risk.Sharpe != 0 || risk.Sortino != 0 || risk.Calmar != 0 || risk.MaxDrawdown != 0 {
+		t.Errorf("ratios = %+v, drawdown %v, want zeros", risk.Ratios, risk.MaxDrawdown)
+	}
+	assertClose(t, "annual return", risk.AnnualReturn, math.Pow(1.01, 252)-1, 1e-9)
+}
+
+func TestTotalRisk(t *testing.T) {
+	prices := []float64{100, 110, 99, 120, 90, 108}
+	risk, err := NewRiskMetrics().TotalRisk(prices, nil, handConfidence, 0, 252)
+	if err != nil {
+		t.Fatalf("TotalRisk: %v", err)
+	}
+
+	// Суммарная лог-доходность ln(108/100) за 5 периодов
+	assertClose(t, "mean", risk.MeanReturn, math.Log(1.08)/5, 1e-12)
+	if risk.Total != len(prices) || risk.MaxDrawdown != 0.25 {
+		t.Errorf("risk = %+v, want total 6 and drawdown 0.25", risk)
+	}
+	assertClose(t, "Calmar", risk.Calmar, risk.AnnualReturn/0.25, 1e-12)
+}
@@ -1,2 +1,435 @@
 // Package correlation_analysis  include Cross-asset correlations
 package correlation_analysis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+const (
+	Pearson  = "pearson"
+	Spearman = "spearman"
+	Kendall  = "kendall"
+)
+
+// Политики обработки пропущенных баров при выравнивании рядов
+const (
+	// MissingDrop оставляет только метки времени, присутствующие во всех рядах
+	MissingDrop = "drop"
+	// MissingForwardFill берет объединение меток времени и заполняет пропуски последним известным значением
+	MissingForwardFill = "ffill"
+)
+
+type CorrelationAnalysis struct {
+}
+
+func NewCorrelationAnalysis() *CorrelationAnalysis {
+	return &CorrelationAnalysis{}
+}
+
+// Series ряд цен одного инструмента
+type Series struct {
+	ID     string
+	Times  []time.Time
+	Prices []float64
+}
+
+// Aligned ряды, выровненные на общую шкалу времени. Prices[i][j] — цена i-го ряда в момент Times[j].
+type Aligned struct {
+	IDs     []string
+	Times   []time.Time
+	Prices  [][]float64
+	Dropped int
+	Filled  int
+}
+
+type RollingPair struct {
+	A      string
+	B      string
+	Times  []time.Time
+	Values []float64
+}
+
+// Merge шаг агломеративной кластеризации: кластеры Left и Right объединяются на расстоянии Distance.
+// Индексы < len(IDs) — исходные инструменты, остальные — кластеры, созданные на шаге index-len(IDs).
+type Merge struct {
+	Left     int
+	Right    int
+	Distance float64
+	Size     int
+}
+
+type Clustering struct {
+	Merges []Merge
+	Order  []string
+}
+
+func (ca *CorrelationAnalysis) AlignSeries(series []Series, missing string) (Aligned, error) {
+	if len(series) < 2 {
+		return Aligned{}, fmt.Errorf("at least two series required: %d", len(series))
+	}
+
+	lookup := make([]map[int64]float64, len(series))
+	counts := make(map[int64]int)
+	for i, s := range series {
+		if len(s.Times) != len(s.Prices) {
+			return Aligned{}, fmt.Errorf("series %s: times and prices length mismatch", s.ID)
+		}
+		lookup[i] = make(map[int64]float64, len(s.Times))
+		for j, t := range s.Times {
+			key := t.UnixNano()
+			if _, ok := lookup[i][key]; !ok {
+				counts[key]++
+			}
+			lookup[i][key] = s.Prices[j]
+		}
+	}
+
+	keys := make([]int64, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	aligned := Aligned{
+		IDs:    make([]string, len(series)),
+		Prices: make([][]float64, len(series)),
+	}
+	for i, s := range series {
+		aligned.IDs[i] = s.ID
+	}
+
+	switch missing {
+	case MissingDrop, "":
+		for _, k := range keys {
+			if counts[k] != len(series) {
+				aligned.Dropped++
+				continue
+			}
+			aligned.Times = append(aligned.Times, time.Unix(0, k).UTC())
+			for i := range series {
+				aligned.Prices[i] = append(aligned.Prices[i], lookup[i][k])
+			}
+		}
+	case MissingForwardFill:
+		last := make([]float64, len(series))
+		seen := make([]bool, len(series))
+		for _, k := range keys {
+			ready := true
+			for i := range series {
+				if v, ok := lookup[i][k]; ok {
+					last[i] = v
+					seen[i] = true
+				}
+				ready = ready && seen[i]
+			}
+			// Пока у какого-то ряда нет ни одного значения, заполнять нечем
+			if !ready {
+				aligned.Dropped++
+				continue
+			}
+
+			aligned.Times = append(aligned.Times, time.Unix(0, k).UTC())
+			for i := range series {
+				if _, ok := lookup[i][k]; !ok {
+					aligned.Filled++
+				}
+				aligned.Prices[i] = append(aligned.Prices[i], last[i])
+			}
+		}
+	default:
+		return Aligned{}, fmt.Errorf("unknown missing bars policy: %q", missing)
+	}
+
+	if len(aligned.Times) < 3 {
+		return Aligned{}, fmt.Errorf("not enough common bars after alignment: %d", len(aligned.Times))
+	}
+
+	return aligned, nil
+}
+
+// LogReturns логарифмические доходности выровненных рядов; Times сдвигаются на один бар
+func (ca *CorrelationAnalysis) LogReturns(aligned Aligned) ([][]float64, []time.Time, error) {
+	returns := make([][]float64, len(aligned.Prices))
+	for i, prices := range aligned.Prices {
+		returns[i] = make([]float64, len(prices)-1)
+		for j := 1; j < len(prices); j++ {
+			if prices[j-1] <= 0 || prices[j] <= 0 {
+				return nil, nil, fmt.Errorf("series %s: non-positive price at %s", aligned.IDs[i], aligned.Times[j])
+			}
+			returns[i][j-1] = math.Log(prices[j] / prices[j-1])
+		}
+	}
+	return returns, aligned.Times[1:], nil
+}
+
+func (ca *CorrelationAnalysis) Correlation(x, y []float64, method string) (float64, error) {
+	if len(x) != len(y) {
+		return 0, errors.New("series length mismatch")
+	}
+	if len(x) < 2 {
+		return 0, fmt.Errorf("not enough data points for correlation: %d < 2", len(x))
+	}
+
+	var rho float64
+	switch method {
+	case Pearson, "":
+		rho = stat.Correlation(x, y, nil)
+	case Spearman:
+		rho = stat.Correlation(rank(x), rank(y), nil)
+	case Kendall:
+		rho = kendallTauB(x, y)
+	default:
+		return 0, fmt.Errorf("unknown correlation method: %q", method)
+	}
+
+	// Постоянный ряд дает NaN, корреляция в этом случае не определена
+	if math.IsNaN(rho) {
+		return 0, nil
+	}
+	return rho, nil
+}
+
+func (ca *CorrelationAnalysis) CorrelationMatrix(returns [][]float64, method string) ([][]float64, error) {
+	n := len(returns)
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+		matrix[i][i] = 1
+	}
+
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			rho, err := ca.Correlation(returns[i], returns[j], method)
+			if err != nil {
+				return nil, err
+			}
+			matrix[i][j] = rho
+			matrix[j][i] = rho
+		}
+	}
+	return matrix, nil
+}
+
+// RollingCorrelations попарные корреляции в скользящем окне window с шагом step
+func (ca *CorrelationAnalysis) RollingCorrelations(ids []string, returns [][]float64, times []time.Time, window, step int, method string) ([]RollingPair, error) {
+	if window < 2 {
+		return nil, fmt.Errorf("rolling window must be at least 2: %d", window)
+	}
+	if step < 1 {
+		step = 1
+	}
+	if len(times) < window {
+		return nil, fmt.Errorf("not enough data points for rolling window: %d < %d", len(times), window)
+	}
+
+	var pairs []RollingPair
+	for i := 0; i < len(returns); i++ {
+		for j := i + 1; j < len(returns); j++ {
+			pair := RollingPair{A: ids[i], B: ids[j]}
+			for end := window; end <= len(times); end += step {
+				rho, err := ca.Correlation(returns[i][end-window:end], returns[j][end-window:end], method)
+				if err != nil {
+					return nil, err
+				}
+				pair.Times = append(pair.Times, times[end-1])
+				pair.Values = append(pair.Values, rho)
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs, nil
+}
+
+// HierarchicalClustering агломеративная кластеризация со средней связью
+// по расстоянию d = sqrt((1 - rho) / 2)
+func (ca *CorrelationAnalysis) HierarchicalClustering(ids []string, matrix [][]float64) (Clustering, error) {
+	n := len(ids)
+	if n == 0 || len(matrix) != n {
+		return Clustering{}, errors.New("matrix size does not match ids")
+	}
+
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+		for j := range dist[i] {
+			dist[i][j] = math.Sqrt(math.Max(0, (1-matrix[i][j])/2))
+		}
+	}
+
+	type cluster struct {
+		id      int
+		members []int
+	}
+	active := make([]cluster, n)
+	for i := range active {
+		active[i] = cluster{id: i, members: []int{i}}
+	}
+
+	linkage := func(a, b cluster) float64 {
+		var total float64
+		for _, x := range a.members {
+			for _, y := range b.members {
+				total += dist[x][y]
+			}
+		}
+		return total / float64(len(a.members)*len(b.members))
+	}
+
+	var result Clustering
+	for len(active) > 1 {
+		bi, bj, best := 0, 1, math.Inf(1)
+		for i := 0; i < len(active); i++ {
+			for j := i + 1; j < len(active); j++ {
+				if d := linkage(active[i], active[j]); d < best {
+					bi, bj, best = i, j, d
+				}
+			}
+		}
+
+		merged := cluster{
+			id:      n + len(result.Merges),
+			members: append(slices.Clone(active[bi].members), active[bj].members...),
+		}
+		result.Merges = append(result.Merges, Merge{
+			Left:     active[bi].id,
+			Right:    active[bj].id,
+			Distance: best,
+			Size:     len(merged.members),
+		})
+
+		active = append(active[:bj], active[bj+1:]...)
+		active[bi] = merged
+	}
+
+	for _, m := range active[0].members {
+		result.Order = append(result.Order, ids[m])
+	}
+
+	return result, nil
+}
+
+// rank ранги значений, одинаковым значениям присваивается средний ранг
+func rank(values []float64) []float64 {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return values[idx[a]] < values[idx[b]] })
+
+	ranks := make([]float64, len(values))
+	for i := 0; i < len(idx); {
+		j := i
+		for j+1 < len(idx) && values[idx[j+1]] == values[idx[i]] {
+			j++
+		}
+		avg := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			ranks[idx[k]] = avg
+		}
+		i = j + 1
+	}
+	return ranks
+}
+
+// kendallTauB tau-b Кендалла с поправкой на совпадения
+func kendallTauB(x, y []float64) float64 {
+	var concordant, discordant, tiesX, tiesY float64
+	for i := 0; i < len(x); i++ {
+		for j := i + 1; j < len(x); j++ {
+			dx := x[i] - x[j]
+			dy := y[i] - y[j]
+			switch {
+			case dx == 0 && dy == 0:
+			case dx == 0:
+				tiesX++
+			case dy == 0:
+				tiesY++
+			case dx*dy > 0:
+				concordant++
+			default:
+				discordant++
+			}
+		}
+	}
+
+	denom := math.Sqrt((concordant + discordant + tiesX) * (concordant + discordant + tiesY))
+	if denom == 0 {
+		return math.NaN()
+	}
+	return (concordant - discordant) / denom
+}
+
+// CrossAsset результат кросс-активного анализа корреляций
+type CrossAsset struct {
+	Method     string
+	Missing    string
+	IDs        []string
+	Bars       int
+	Dropped    int
+	Filled     int
+	From       time.Time
+	To         time.Time
+	Matrix     [][]float64
+	Rolling    []RollingPair
+	Clustering Clustering
+}
+
+// TotalCorrelation выравнивает ряды, считает матрицу корреляций доходностей, скользящие корреляции
+// (если window > 0) и кластеризацию матрицы
+func (ca *CorrelationAnalysis) TotalCorrelation(series []Series, method, missing string, window, step int) (CrossAsset, error) {
+	if method == "" {
+		method = Pearson
+	}
+	if missing == "" {
+		missing = MissingDrop
+	}
+
+	aligned, err := ca.AlignSeries(series, missing)
+	if err != nil {
+		return CrossAsset{}, err
+	}
+
+	returns, times, err := ca.LogReturns(aligned)
+	if err != nil {
+		return CrossAsset{}, err
+	}
+
+	matrix, err := ca.CorrelationMatrix(returns, method)
+	if err != nil {
+		return CrossAsset{}, err
+	}
+
+	var rolling []RollingPair
+	if window > 0 {
+		rolling, err = ca.RollingCorrelations(aligned.IDs, returns, times, window, step, method)
+		if err != nil {
+			return CrossAsset{}, err
+		}
+	}
+
+	clustering, err := ca.HierarchicalClustering(aligned.IDs, matrix)
+	if err != nil {
+		return CrossAsset{}, err
+	}
+
+	return CrossAsset{
+		Method:     method,
+		Missing:    missing,
+		IDs:        aligned.IDs,
+		Bars:       len(aligned.Times),
+		Dropped:    aligned.Dropped,
+		Filled:     aligned.Filled,
+		From:       aligned.Times[0],
+		To:         aligned.Times[len(aligned.Times)-1],
+		Matrix:     matrix,
+		Rolling:    rolling,
+		Clustering: clustering,
+	}, nil
+}
@@ -0,0 +1,204 @@
+package correlation_analysis
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// days метки времени start+d для каждого d
+func days(offsets ...int) []time.Time {
+	times := make([]time.Time, len(offsets))
+	for i, d := range offsets {
+		times[i] = start.AddDate(0, 0, d)
+	}
+	return times
+}
+
+// Ряд B пропускает день 2, ряд C начинается с дня 1
+var gappedSeries = []Series{
+	{ID: "A", Times: days(0, 1, 2, 3, 4, 5), Prices: []float64{10, 11, 12, 13, 14, 15}},
+	{ID: "B", Times: days(0, 1, 3, 4, 5), Prices: []float64{20, 21, 23, 24, 25}},
+	{ID: "C", Times: days(1, 2, 3, 4, 5), Prices: []float64{31, 32, 33, 34, 35}},
+}
+
+func TestAlignSeries(t *testing.T) {
+	tests := []struct {
+		name    string
+		missing string
+		want    Aligned
+	}{
+		{
+			// Остаются только дни, которые есть во всех рядах: 0 и 2 отброшены
+			name:    "drop",
+			missing: MissingDrop,
+			want: Aligned{
+				IDs:     []string{"A", "B", "C"},
+				Times:   days(1, 3, 4, 5),
+				Prices:  [][]float64{{11, 13, 14, 15}, {21, 23, 24, 25}, {31, 33, 34, 35}},
+				Dropped: 2,
+			},
+		},
+		{
+			// День 0 отброшен, пока у C нет значений; пропуск B в день 2 заполнен ценой дня 1
+			name:    "forward fill",
+			missing: MissingForwardFill,
+			want: Aligned{
+				IDs:     []string{"A", "B", "C"},
+				Times:   days(1, 2, 3, 4, 5),
+				Prices:  [][]float64{{11, 12, 13, 14, 15}, {21, 21, 23, 24, 25}, {31, 32, 33, 34, 35}},
+				Dropped: 1,
+				Filled:  1,
+			},
+		},
+	}
+
+	ca := NewCorrelationAnalysis()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ca.AlignSeries(gappedSeries, tt.missing)
+			if err != nil {
+				t.Fatalf("AlignSeries: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ca.AlignSeries(gappedSeries, "interpolate"); err == nil {
+		t.Error("expected error for an unknown policy")
+	}
+	short := []Series{gappedSeries[0], {ID: "D", Times: days(4, 5, 6), Prices: []float64{1, 2, 3}}}
+	if _, err := ca.AlignSeries(short, MissingDrop); err == nil {
+		t.Error("expected error for two common bars")
+	}
+}
+
+func TestCorrelationTies(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		x, y   []float64
+		want   float64
+	}{
+		// Ранги x: 1, 2.5, 2.5, 4; корреляция рангов 4.5/sqrt(4.5·5) = sqrt(0.9)
+		{name: "spearman ties", method: Spearman, x: []float64{1, 2, 2, 3}, y: []float64{1, 2, 3, 4}, want: math.Sqrt(0.9)},
+		{name: "spearman monotonic", method: Spearman, x: []float64{1, 2, 3, 4}, y: []float64{1, 4, 9, 16}, want: 1},
+		// 5 согласованных пар и одна пара с совпадением только по x: 5/sqrt(6·5), tau-a было бы 5/6
+		{name: "kendall ties in x", method: Kendall, x: []float64{1, 2, 2, 3}, y: []float64{1, 2, 3, 4}, want: 5 / math.Sqrt(30)},
+		// Пара (0, 1) совпадает в обоих рядах и не входит в знаменатель: (4-1)/sqrt(5·5)
+		{name: "kendall joint tie", method: Kendall, x: []float64{1, 1, 2, 3}, y: []float64{1, 1, 3, 2}, want: 0.6},
+		{name: "kendall reversed", method: Kendall, x: []float64{1, 2, 3}, y: []float64{3, 2, 1}, want: -1},
+		// Корреляция с постоянным рядом не определена и возвращается как 0
+		{name: "constant", method: Pearson, x: []float64{1, 2, 3}, y: []float64{5, 5, 5}, want: 0},
+	}
+
+	ca := NewCorrelationAnalysis()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ca.Correlation(tt.x, tt.y, tt.method)
+			if err != nil {
+				t.Fatalf("Correlation: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollingCorrelationsWindows(t *testing.T) {
+	ids := []string{"A", "B"}
+	returns := [][]float64{{1, 2, 3, 4, 5}, {1, 2, 3, 5, 4}}
+	times := days(1, 2, 3, 4, 5)
+
+	tests := []struct {
+		name         string
+		window, step int
+		times        []time.Time
+		values       []float64
+	}{
+		// Окна заканчиваются на барах 2, 3, 4; в последнем ранги 1,2,3 против 1,3,2
+		{name: "step 1", window: 3, step: 1, times: days(3, 4, 5), values: []float64{1, 1, 0.5}},
+		{name: "step 2", window: 3, step: 2, times: days(3, 5), values: []float64{1, 0.5}},
+		{name: "step 0 is 1", window: 3, step: 0, times: days(3, 4, 5), values: []float64{1, 1, 0.5}},
+		// Окно во весь ряд дает одно значение на последнем баре
+		{name: "full window", window: 5, step: 1, times: days(5), values: []float64{0.9}},
+	}
+
+	ca := NewCorrelationAnalysis()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, err := ca.RollingCorrelations(ids, returns, times, tt.window, tt.step, Spearman)
+			if err != nil {
+				t.Fatalf("RollingCorrelations: %v", err)
+			}
+			if len(pairs) != 1 || pairs[0].A != "A" || pairs[0].B != "B" {
+				t.Fatalf("pairs = %+v, want one A/B pair", pairs)
+			}
+			if !reflect.DeepEqual(pairs[0].Times, tt.times) {
+				t.Errorf("times = %v, want %v", pairs[0].Times, tt.times)
+			}
+			if len(pairs[0].Values) != len(tt.values) {
+				t.Fatalf("values = %v, want %v", pairs[0].Values, tt.values)
+			}
+			for i, want := range tt.values {
+				if math.Abs(pairs[0].Values[i]-want) > 1e-12 {
+					t.Errorf("values[%d] = %v, want %v", i, pairs[0].Values[i], want)
+				}
+			}
+		})
+	}
+
+	for _, window := range []int{1, 6} {
+		if _, err := ca.RollingCorrelations(ids, returns, times, window, 1, Spearman); err == nil {
+			t.Errorf("window %d: expected error", window)
+		}
+	}
+}
+
+func TestHierarchicalClusteringAverageLinkage(t *testing.T) {
+	// A-B и C-D коррелированы, но стоят в матрице вперемешку
+	ids := []string{"A", "C", "B", "D"}
+	matrix := [][]float64{
+		{1, 0.1, 0.9, -0.2},
+		{0.1, 1, 0, 0.8},
+		{0.9, 0, 1, 0.3},
+		{-0.2, 0.8, 0.3, 1},
+	}
+	d := func(rho float64) float64 { return math.Sqrt((1 - rho) / 2) }
+
+	got, err := NewCorrelationAnalysis().HierarchicalClustering(ids, matrix)
+	if err != nil {
+		t.Fatalf("HierarchicalClustering: %v", err)
+	}
+
+	// Сначала A+B (кластер 4), затем C+D (кластер 5), затем оба кластера на среднем
+	// из четырех перекрестных расстояний
+	want := []Merge{
+		{Left: 0, Right: 2, Distance: d(0.9), Size: 2},
+		{Left: 1, Right: 3, Distance: d(0.8), Size: 2},
+		{Left: 4, Right: 5, Distance: (d(0.1) + d(-0.2) + d(0) + d(0.3)) / 4, Size: 4},
+	}
+	if len(got.Merges) != len(want) {
+		t.Fatalf("merges = %+v, want %+v", got.Merges, want)
+	}
+	for i := range want {
+		m := got.Merges[i]
+		if m.Left != want[i].Left || m.Right != want[i].Right || m.Size != want[i].Size ||
+			math.Abs(m.Distance-want[i].Distance) > 1e-12 {
+			t.Errorf("merge %d = %+v, want %+v", i, m, want[i])
+		}
+	}
+	if !reflect.DeepEqual(got.Order, []string{"A", "B", "C", "D"}) {
+		t.Errorf("order = %v, want A B C D", got.Order)
+	}
+
+	if _, err := NewCorrelationAnalysis().HierarchicalClustering(ids, matrix[:3]); err == nil {
+		t.Error("expected error for a matrix smaller than ids")
+	}
+}
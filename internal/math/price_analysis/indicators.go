@@ -0,0 +1,543 @@
+package price_analysis
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Входные ряды свечи, которые может использовать индикатор
+const (
+	InputOpen   = "open"
+	InputHigh   = "high"
+	InputLow    = "low"
+	InputClose  = "close"
+	InputVolume = "volume"
+)
+
+// OHLCV ряды свечей одинаковой длины
+type OHLCV struct {
+	Open   []float64
+	High   []float64
+	Low    []float64
+	Close  []float64
+	Volume []float64
+}
+
+func (d OHLCV) series(input string) []float64 {
+	switch input {
+	case InputOpen:
+		return d.Open
+	case InputHigh:
+		return d.High
+	case InputLow:
+		return d.Low
+	case InputClose:
+		return d.Close
+	case InputVolume:
+		return d.Volume
+	}
+	return nil
+}
+
+// IndicatorSpec описание индикатора в реестре. Params — значения параметров по умолчанию,
+// Periods — целочисленные параметры с их минимальным значением,
+// WarmUp — индекс первого бара, для которого индикатор определен.
+type IndicatorSpec struct {
+	Name    string
+	Inputs  []string
+	Params  map[string]float64
+	Periods map[string]int
+	Outputs []string
+	WarmUp  func(params map[string]float64) int
+	Calc    func(data OHLCV, params map[string]float64) map[string][]float64
+}
+
+// IndicatorParams запрос на расчет индикатора; незаданные параметры берутся по умолчанию
+type IndicatorParams struct {
+	Name   string             `json:"name"`
+	Params map[string]float64 `json:"params"`
+}
+
+// UnmarshalParam разбирает индикатор из параметра запроса вида "macd" или "macd:fast=12,slow=26"
+func (p *IndicatorParams) UnmarshalParam(value string) error {
+	name, rest, hasParams := strings.Cut(value, ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("indicator name is required: %q", value)
+	}
+
+	parsed := IndicatorParams{Name: name}
+	if hasParams {
+		parsed.Params = make(map[string]float64)
+		for _, pair := range strings.Split(rest, ",") {
+			key, raw, ok := strings.Cut(pair, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				return fmt.Errorf("indicator %s: parameter must be key=value: %q", name, pair)
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err != nil {
+				return fmt.Errorf("indicator %s: parameter %s is not a number: %q", name, key, raw)
+			}
+			parsed.Params[key] = v
+		}
+	}
+	*p = parsed
+	return nil
+}
+
+// IndicatorResult значения индикатора. Values[output][i] соответствует бару WarmUp+i.
+type IndicatorResult struct {
+	Name   string
+	Params map[string]float64
+	WarmUp int
+	Values map[string][]float64
+}
+
+type IndicatorRegistry struct {
+	indicators map[string]IndicatorSpec
+}
+
+func NewIndicatorRegistry() *IndicatorRegistry {
+	r := &IndicatorRegistry{
+		indicators: make(map[string]IndicatorSpec),
+	}
+
+	for _, spec := range defaultIndicators() {
+		r.Register(spec)
+	}
+	return r
+}
+
+func (r *IndicatorRegistry) Register(spec IndicatorSpec) {
+	r.indicators[spec.Name] = spec
+}
+
+func (r *IndicatorRegistry) Get(name string) (IndicatorSpec, bool) {
+	spec, ok := r.indicators[name]
+	return spec, ok
+}
+
+// List индикаторы реестра, отсортированные по имени
+func (r *IndicatorRegistry) List() []IndicatorSpec {
+	specs := make([]IndicatorSpec, 0, len(r.indicators))
+	for _, spec := range r.indicators {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+func (r *IndicatorRegistry) Calculate(data OHLCV, req IndicatorParams) (IndicatorResult, error) {
+	spec, ok := r.Get(req.Name)
+	if !ok {
+		return IndicatorResult{}, fmt.Errorf("unknown indicator: %q", req.Name)
+	}
+
+	params := make(map[string]float64, len(spec.Params))
+	for k, v := range spec.Params {
+		params[k] = v
+	}
+	for k, v := range req.Params {
+		if _, ok := spec.Params[k]; !ok {
+			return IndicatorResult{}, fmt.Errorf("%s: unknown parameter %q", spec.Name, k)
+		}
+		params[k] = v
+	}
+	for k, v := range params {
+		if !validParam(v, spec.Periods, k) {
+			return IndicatorResult{}, fmt.Errorf("%s: invalid parameter %s=%v", spec.Name, k, v)
+		}
+	}
+
+	n := len(data.Close)
+	for _, input := range spec.Inputs {
+		if len(data.series(input)) != n {
+			return IndicatorResult{}, fmt.Errorf("%s: input %q length mismatch", spec.Name, input)
+		}
+	}
+
+	warmUp := spec.WarmUp(params)
+	if warmUp >= n {
+		return IndicatorResult{}, fmt.Errorf("%s: not enough data points: %d <= warm-up %d", spec.Name, n, warmUp)
+	}
+
+	values := spec.Calc(data, params)
+	for k, v := range values {
+		values[k] = v[warmUp:]
+	}
+
+	return IndicatorResult{
+		Name:   spec.Name,
+		Params: params,
+		WarmUp: warmUp,
+		Values: values,
+	}, nil
+}
+
+func (p *PriceAnalysis) CalculateIndicators(data OHLCV, reqs []IndicatorParams) ([]IndicatorResult, error) {
+	results := make([]IndicatorResult, 0, len(reqs))
+	for _, req := range reqs {
+		result, err := p.Indicators.Calculate(data, req)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func defaultIndicators() []IndicatorSpec {
+	return []IndicatorSpec{
+		{
+			Name:    "sma",
+			Inputs:  []string{InputClose},
+			Params:  map[string]float64{"period": 20},
+			Periods: map[string]int{"period": 1},
+			Outputs: []string{"sma"},
+			WarmUp:  func(p map[string]float64) int { return period(p, "period") - 1 },
+			Calc: func(d OHLCV, p map[string]float64) map[string][]float64 {
+				return map[string][]float64{"sma": smaSeries(d.Close, period(p, "period"))}
+			},
+		},
+		{
+			Name:    "ema",
+			Inputs:  []string{InputClose},
+			Params:  map[string]float64{"period": 20},
+			Periods: map[string]int{"period": 1},
+			Outputs: []string{"ema"},
+			WarmUp:  func(p map[string]float64) int { return period(p, "period") - 1 },
+			Calc: func(d OHLCV, p map[string]float64) map[string][]float64 {
+				return map[string][]float64{"ema": emaSeries(d.Close, period(p, "period"), 0)}
+			},
+		},
+		{
+			Name:    "wma",
+			Inputs:  []string{InputClose},
+			Params:  map[string]float64{"period": 20},
+			Periods: map[string]int{"period": 1},
+			Outputs: []string{"wma"},
+			WarmUp:  func(p map[string]float64) int { return period(p, "period") - 1 },
+			Calc: func(d OHLCV, p map[string]float64) map[string][]float64 {
+				return map[string][]float64{"wma": wmaSeries(d.Close, period(p, "period"))}
+			},
+		},
+		{
+			Name:    "macd",
+			Inputs:  []string{InputClose},
+			Params:  map[string]float64{"fast": 12, "slow": 26, "signal": 9},
+			Periods: map[string]int{"fast": 1, "slow": 1, "signal": 1},
+			Outputs: []string{"macd", "signal", "histogram"},
+			WarmUp: func(p map[string]float64) int {
+				return max(period(p, "fast"), period(p, "slow")) + period(p, "signal") - 2
+			},
+			Calc: macd,
+		},
+		{
+			Name:    "bollinger",
+			Inputs:  []string{InputClose},
+			Params:  map[string]float64{"period": 20, "k": 2},
+			Periods: map[string]int{"period": 1},
+			Outputs: []string{"middle", "upper", "lower", "bandwidth", "percentB"},
+			WarmUp:  func(p map[string]float64) int { return period(p, "period") - 1 },
+			Calc:    bollinger,
+		},
+		{
+			Name:    "atr",
+			Inputs:  []string{InputHigh, InputLow, InputClose},
+			Params:  map[string]float64{"period": 14},
+			Periods: map[string]int{"period": 1},
+			Outputs: []string{"atr"},
+			WarmUp:  func(p map[string]float64) int { return period(p, "period") },
+			Calc: func(d OHLCV, p map[string]float64) map[string][]float64 {
+				return map[string][]float64{"atr": wilderSeries(trueRange(d), period(p, "period"))}
+			},
+		},
+		{
+			Name:    "stochastic",
+			Inputs:  []string{InputHigh, InputLow, InputClose},
+			Params:  map[string]float64{"k": 14, "d": 3},
+			Periods: map[string]int{"k": 1, "d": 1},
+			Outputs: []string{"k", "d"},
+			WarmUp:  func(p map[string]float64) int { return period(p, "k") + period(p, "d") - 2 },
+			Calc:    stochastic,
+		},
+		{
+			Name:    "adx",
+			Inputs:  []string{InputHigh, InputLow, InputClose},
+			Params:  map[string]float64{"period": 14},
+			Periods: map[string]int{"period": 1},
+			Outputs: []string{"adx", "plusDi", "minusDi"},
+			WarmUp:  func(p map[string]float64) int { return 2*period(p, "period") - 1 },
+			Calc:    adx,
+		},
+		{
+			Name:    "obv",
+			Inputs:  []string{InputClose, InputVolume},
+			Params:  map[string]float64{},
+			Outputs: []string{"obv"},
+			WarmUp:  func(p map[string]float64) int { return 0 },
+			Calc:    obv,
+		},
+		{
+			Name:    "vwap",
+			Inputs:  []string{InputHigh, InputLow, InputClose, InputVolume},
+			Params:  map[string]float64{"period": 0},
+			Periods: map[string]int{"period": 0},
+			Outputs: []string{"vwap"},
+			WarmUp:  func(p map[string]float64) int { return max(period(p, "period"), 1) - 1 },
+			Calc:    vwap,
+		},
+	}
+}
+
+// validParam проверяет параметр: конечный и неотрицательный, а период — целый и не меньше минимума
+func validParam(v float64, periods map[string]int, name string) bool {
+	if math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+		return false
+	}
+	minimum, ok := periods[name]
+	if !ok {
+		return true
+	}
+	return v == math.Trunc(v) && v >= float64(minimum) && v <= math.MaxInt32
+}
+
+// period целочисленный параметр периода, проверенный в Calculate
+func period(params map[string]float64, name string) int {
+	return int(params[name])
+}
+
+func nanSeries(n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = math.NaN()
+	}
+	return s
+}
+
+// firstValid индекс первого не-NaN значения
+func firstValid(values []float64) int {
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			return i
+		}
+	}
+	return len(values)
+}
+
+func smaSeries(values []float64, n int) []float64 {
+	out := nanSeries(len(values))
+	start := firstValid(values)
+	for i := start + n - 1; i < len(values); i++ {
+		out[i] = sum(values[i-n+1:i+1]) / float64(n)
+	}
+	return out
+}
+
+// emaSeries EMA с затравкой SMA; alpha = 0 означает 2/(n+1)
+func emaSeries(values []float64, n int, alpha float64) []float64 {
+	if alpha == 0 {
+		alpha = 2 / float64(n+1)
+	}
+
+	out := nanSeries(len(values))
+	start := firstValid(values)
+	if start+n > len(values) {
+		return out
+	}
+
+	out[start+n-1] = sum(values[start:start+n]) / float64(n)
+	for i := start + n; i < len(values); i++ {
+		out[i] = alpha*values[i] + (1-alpha)*out[i-1]
+	}
+	return out
+}
+
+// wilderSeries сглаживание Уайлдера (EMA с alpha = 1/n)
+func wilderSeries(values []float64, n int) []float64 {
+	return emaSeries(values, n, 1/float64(n))
+}
+
+func wmaSeries(values []float64, n int) []float64 {
+	out := nanSeries(len(values))
+	denom := float64(n*(n+1)) / 2
+	for i := n - 1; i < len(values); i++ {
+		var total float64
+		for j := 0; j < n; j++ {
+			total += values[i-n+1+j] * float64(j+1)
+		}
+		out[i] = total / denom
+	}
+	return out
+}
+
+func macd(d OHLCV, p map[string]float64) map[string][]float64 {
+	fast := emaSeries(d.Close, period(p, "fast"), 0)
+	slow := emaSeries(d.Close, period(p, "slow"), 0)
+
+	line := nanSeries(len(d.Close))
+	for i := range line {
+		line[i] = fast[i] - slow[i]
+	}
+	signal := emaSeries(line, period(p, "signal"), 0)
+
+	histogram := nanSeries(len(d.Close))
+	for i := range histogram {
+		histogram[i] = line[i] - signal[i]
+	}
+
+	return map[string][]float64{"macd": line, "signal": signal, "histogram": histogram}
+}
+
+func bollinger(d OHLCV, p map[string]float64) map[string][]float64 {
+	n := period(p, "period")
+	k := p["k"]
+
+	middle := smaSeries(d.Close, n)
+	upper := nanSeries(len(d.Close))
+	lower := nanSeries(len(d.Close))
+	bandwidth := nanSeries(len(d.Close))
+	percentB := nanSeries(len(d.Close))
+
+	for i := n - 1; i < len(d.Close); i++ {
+		var variance float64
+		for _, v := range d.Close[i-n+1 : i+1] {
+			variance += (v - middle[i]) * (v - middle[i])
+		}
+		std := math.Sqrt(variance / float64(n))
+
+		upper[i] = middle[i] + k*std
+		lower[i] = middle[i] - k*std
+		bandwidth[i] = 0
+		if middle[i] != 0 {
+			bandwidth[i] = (upper[i] - lower[i]) / middle[i]
+		}
+		percentB[i] = 0.5
+		if upper[i] != lower[i] {
+			percentB[i] = (d.Close[i] - lower[i]) / (upper[i] - lower[i])
+		}
+	}
+
+	return map[string][]float64{
+		"middle":    middle,
+		"upper":     upper,
+		"lower":     lower,
+		"bandwidth": bandwidth,
+		"percentB":  percentB,
+	}
+}
+
+// trueRange истинный диапазон; для первого бара не определен
+func trueRange(d OHLCV) []float64 {
+	tr := nanSeries(len(d.Close))
+	for i := 1; i < len(d.Close); i++ {
+		tr[i] = math.Max(d.High[i]-d.Low[i],
+			math.Max(math.Abs(d.High[i]-d.Close[i-1]), math.Abs(d.Low[i]-d.Close[i-1])))
+	}
+	return tr
+}
+
+func stochastic(d OHLCV, p map[string]float64) map[string][]float64 {
+	n := period(p, "k")
+
+	k := nanSeries(len(d.Close))
+	for i := n - 1; i < len(d.Close); i++ {
+		lowest := slices.Min(d.Low[i-n+1 : i+1])
+		highest := slices.Max(d.High[i-n+1 : i+1])
+		k[i] = 50
+		if highest != lowest {
+			k[i] = 100 * (d.Close[i] - lowest) / (highest - lowest)
+		}
+	}
+
+	return map[string][]float64{"k": k, "d": smaSeries(k, period(p, "d"))}
+}
+
+func adx(d OHLCV, p map[string]float64) map[string][]float64 {
+	n := period(p, "period")
+	size := len(d.Close)
+
+	plusDM := nanSeries(size)
+	minusDM := nanSeries(size)
+	for i := 1; i < size; i++ {
+		up := d.High[i] - d.High[i-1]
+		down := d.Low[i-1] - d.Low[i]
+		plusDM[i], minusDM[i] = 0, 0
+		if up > down && up > 0 {
+			plusDM[i] = up
+		}
+		if down > up && down > 0 {
+			minusDM[i] = down
+		}
+	}
+
+	tr := wilderSeries(trueRange(d), n)
+	plus := wilderSeries(plusDM, n)
+	minus := wilderSeries(minusDM, n)
+
+	plusDi := nanSeries(size)
+	minusDi := nanSeries(size)
+	dx := nanSeries(size)
+	for i := n; i < size; i++ {
+		plusDi[i], minusDi[i], dx[i] = 0, 0, 0
+		if tr[i] != 0 {
+			plusDi[i] = 100 * plus[i] / tr[i]
+			minusDi[i] = 100 * minus[i] / tr[i]
+		}
+		if total := plusDi[i] + minusDi[i]; total != 0 {
+			dx[i] = 100 * math.Abs(plusDi[i]-minusDi[i]) / total
+		}
+	}
+
+	return map[string][]float64{"adx": wilderSeries(dx, n), "plusDi": plusDi, "minusDi": minusDi}
+}
+
+func obv(d OHLCV, _ map[string]float64) map[string][]float64 {
+	out := make([]float64, len(d.Close))
+	for i := 1; i < len(d.Close); i++ {
+		switch {
+		case d.Close[i] > d.Close[i-1]:
+			out[i] = out[i-1] + d.Volume[i]
+		case d.Close[i] < d.Close[i-1]:
+			out[i] = out[i-1] - d.Volume[i]
+		default:
+			out[i] = out[i-1]
+		}
+	}
+	return map[string][]float64{"obv": out}
+}
+
+// vwap накопленный VWAP с начала ряда или скользящий при period > 0
+func vwap(d OHLCV, p map[string]float64) map[string][]float64 {
+	n := period(p, "period")
+
+	pv := make([]float64, len(d.Close))
+	for i := range d.Close {
+		pv[i] = (d.High[i] + d.Low[i] + d.Close[i]) / 3 * d.Volume[i]
+	}
+
+	out := nanSeries(len(d.Close))
+	var cumPV, cumVol float64
+	for i := range d.Close {
+		cumPV += pv[i]
+		cumVol += d.Volume[i]
+		if n > 0 && i >= n {
+			cumPV -= pv[i-n]
+			cumVol -= d.Volume[i-n]
+		}
+		if n > 0 && i < n-1 {
+			continue
+		}
+
+		out[i] = (d.High[i] + d.Low[i] + d.Close[i]) / 3
+		if cumVol != 0 {
+			out[i] = cumPV / cumVol
+		}
+	}
+	return map[string][]float64{"vwap": out}
+}
@@ -0,0 +1,246 @@
+package price_analysis
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// Пример 10-дневных SMA и EMA из StockCharts ChartSchool ("Moving Averages - Simple and Exponential",
+// таблица cs-movavg.xls): EMA начинается с SMA первых 10 закрытий, множитель 2/(10+1).
+// Значения в таблице округлены до сотых.
+var stockChartsMACloses = []float64{
+	22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+	22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+	23.82, 23.87, 23.65, 23.19, 23.10, 23.33, 22.68, 23.10, 22.40, 22.17,
+}
+
+var stockChartsSMA10 = []float64{
+	22.22, 22.21, 22.23, 22.26, 22.30, 22.42, 22.61, 22.77, 22.91, 23.08, 23.21,
+	23.38, 23.52, 23.65, 23.71, 23.68, 23.61, 23.51, 23.43, 23.28, 23.13,
+}
+
+var stockChartsEMA10 = []float64{
+	22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28, 23.34,
+	23.43, 23.51, 23.54, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92,
+}
+
+// Небольшой ряд свечей для ручного расчета индикаторов с короткими периодами
+var handOHLCV = OHLCV{
+	Open:   []float64{9.5, 9.5, 10.5, 11, 10, 12.5},
+	High:   []float64{10, 11, 12, 11, 13, 14},
+	Low:    []float64{9, 10, 10, 9, 11, 12},
+	Close:  []float64{9.5, 10.5, 11, 10, 12.5, 13.5},
+	Volume: []float64{100, 200, 300, 400, 500, 600},
+}
+
+func calculate(t *testing.T, data OHLCV, name string, params map[string]float64) IndicatorResult {
+	t.Helper()
+	result, err := NewIndicatorRegistry().Calculate(data, IndicatorParams{Name: name, Params: params})
+	if err != nil {
+		t.Fatalf("Calculate %s: %v", name, err)
+	}
+	return result
+}
+
+func assertSeries(t *testing.T, name string, got, want []float64, tolerance float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %v, want %v", name, got, want)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > tolerance {
+			t.Errorf("%s[%d] = %.6f, want %.6f", name, i, got[i], want[i])
+		}
+	}
+}
+
+func TestMovingAveragesStockChartsReference(t *testing.T) {
+	data := OHLCV{Close: stockChartsMACloses}
+	params := map[string]float64{"period": 10}
+
+	sma := calculate(t, data, "sma", params)
+	assertSeries(t, "sma", sma.Values["sma"], stockChartsSMA10, 0.01)
+
+	// В таблице EMA[13] = 23.54 получено из округленного предыдущего значения, точное — 23.5335
+	ema := calculate(t, data, "ema", params)
+	assertSeries(t, "ema", ema.Values["ema"], stockChartsEMA10, 0.01)
+}
+
+func TestWMA(t *testing.T) {
+	// Веса 1, 2, 3: (1+4+9)/6, (2+6+12)/6, (3+8+15)/6
+	result := calculate(t, OHLCV{Close: []float64{1, 2, 3, 4, 5}}, "wma", map[string]float64{"period": 3})
+	assertSeries(t, "wma", result.Values["wma"], []float64{14.0 / 6, 20.0 / 6, 26.0 / 6}, 1e-12)
+}
+
+func TestMACD(t *testing.T) {
+	// EMA(2): 2, 2, 10/3, 28/9, 118/27, 334/81 с бара 1; EMA(3): 2, 3, 3, 4, 4 с бара 2.
+	// Линия с бара 2: 0, 1/3, 1/9, 10/27, 10/81; сигнал EMA(2) линии: 1/6, 7/54, 47/162, 29/162.
+	data := OHLCV{Close: []float64{1, 3, 2, 4, 3, 5, 4}}
+	result := calculate(t, data, "macd", map[string]float64{"fast": 2, "slow": 3, "signal": 2})
+
+	if result.WarmUp != 3 {
+		t.Fatalf("warm-up = %d, want 3", result.WarmUp)
+	}
+	assertSeries(t, "macd", result.Values["macd"], []float64{1.0 / 3, 1.0 / 9, 10.0 / 27, 10.0 / 81}, 1e-12)
+	assertSeries(t, "signal", result.Values["signal"], []float64{1.0 / 6, 7.0 / 54, 47.0 / 162, 29.0 / 162}, 1e-12)
+	assertSeries(t, "histogram", result.Values["histogram"], []float64{1.0 / 6, -1.0 / 54, 13.0 / 162, -1.0 / 18}, 1e-12)
+}
+
+func TestBollinger(t *testing.T) {
+	// Среднее 5, стандартное отклонение по генеральной совокупности 2: полосы 5±4
+	data := OHLCV{Close: []float64{2, 4, 4, 4, 5, 5, 7, 9}}
+	result := calculate(t, data, "bollinger", map[string]float64{"period": 8, "k": 2})
+
+	assertSeries(t, "middle", result.Values["middle"], []float64{5}, 1e-12)
+	assertSeries(t, "upper", result.Values["upper"], []float64{9}, 1e-12)
+	assertSeries(t, "lower", result.Values["lower"], []float64{1}, 1e-12)
+	assertSeries(t, "bandwidth", result.Values["bandwidth"], []float64{1.6}, 1e-12)
+	assertSeries(t, "percentB", result.Values["percentB"], []float64{1}, 1e-12)
+
+	flat := calculate(t, OHLCV{Close: []float64{3, 3, 3}}, "bollinger", map[string]float64{"period": 3})
+	assertSeries(t, "flat percentB", flat.Values["percentB"], []float64{0.5}, 0)
+	assertSeries(t, "flat bandwidth", flat.Values["bandwidth"], []float64{0}, 0)
+}
+
+func TestATR(t *testing.T) {
+	// TR с бара 1: 1.5, 2, 2, 3, 2. Первое значение — среднее трех TR, дальше (prev*2 + TR)/3.
+	result := calculate(t, handOHLCV, "atr", map[string]float64{"period": 3})
+
+	if result.WarmUp != 3 {
+		t.Fatalf("warm-up = %d, want 3", result.WarmUp)
+	}
+	assertSeries(t, "atr", result.Values["atr"], []float64{11.0 / 6, 20.0 / 9, 58.0 / 27}, 1e-12)
+}
+
+func TestStochastic(t *testing.T) {
+	// %K(3) с бара 2: 200/3, 100/3, 87.5, 90; %D — SMA(2) от %K
+	result := calculate(t, handOHLCV, "stochastic", map[string]float64{"k": 3, "d": 2})
+
+	assertSeries(t, "k", result.Values["k"], []float64{100.0 / 3, 87.5, 90}, 1e-12)
+	assertSeries(t, "d", result.Values["d"], []float64{50, 725.0 / 12, 88.75}, 1e-12)
+}
+
+func TestADX(t *testing.T) {
+	// +DM с бара 1: 1, 1, 0, 2, 1; -DM: 0, 0, 1, 0, 0; TR: 1.5, 2, 2, 3, 2.
+	// После сглаживания Уайлдера (n = 2) +DI: 400/7, 80/3, 2000/39, 3600/71; -DI: 0, 80/3, 400/39, 400/71.
+	// DX: 100, 0, 200/3, 80; ADX: 50, 175/3, 415/6.
+	result := calculate(t, handOHLCV, "adx", map[string]float64{"period": 2})
+
+	if result.WarmUp != 3 {
+		t.Fatalf("warm-up = %d, want 3", result.WarmUp)
+	}
+	assertSeries(t, "adx", result.Values["adx"], []float64{50, 175.0 / 3, 415.0 / 6}, 1e-9)
+	assertSeries(t, "plusDi", result.Values["plusDi"], []float64{80.0 / 3, 2000.0 / 39, 3600.0 / 71}, 1e-9)
+	assertSeries(t, "minusDi", result.Values["minusDi"], []float64{80.0 / 3, 400.0 / 39, 400.0 / 71}, 1e-9)
+}
+
+func TestOBV(t *testing.T) {
+	result := calculate(t, handOHLCV, "obv", nil)
+	assertSeries(t, "obv", result.Values["obv"], []float64{0, 200, 500, 100, 600, 1200}, 0)
+}
+
+func TestVWAP(t *testing.T) {
+	// Типичные цены (H+L+C)/3: 9.5, 10.5, 11, 10, 73/6, 79/6
+	cumulative := calculate(t, handOHLCV, "vwap", nil)
+	assertSeries(t, "cumulative vwap", cumulative.Values["vwap"],
+		[]float64{9.5, 61.0 / 6, 127.0 / 12, 10.35, 493.0 / 45, 730.0 / 63}, 1e-9)
+
+	rolling := calculate(t, handOHLCV, "vwap", map[string]float64{"period": 2})
+	if rolling.WarmUp != 1 {
+		t.Fatalf("warm-up = %d, want 1", rolling.WarmUp)
+	}
+	assertSeries(t, "rolling vwap", rolling.Values["vwap"],
+		[]float64{61.0 / 6, 10.8, 73.0 / 7, 605.0 / 54, 839.0 / 66}, 1e-9)
+}
+
+func TestIndicatorWarmUp(t *testing.T) {
+	tests := []struct {
+		name   string
+		warmUp int
+	}{
+		{name: "sma", warmUp: 19},
+		{name: "ema", warmUp: 19},
+		{name: "wma", warmUp: 19},
+		{name: "macd", warmUp: 33},
+		{name: "bollinger", warmUp: 19},
+		{name: "atr", warmUp: 14},
+		{name: "stochastic", warmUp: 15},
+		{name: "adx", warmUp: 27},
+		{name: "obv", warmUp: 0},
+		{name: "vwap", warmUp: 0},
+	}
+
+	registry := NewIndicatorRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := registry.Calculate(risingOHLCV(tt.warmUp), IndicatorParams{Name: tt.name})
+			if err == nil || !strings.Contains(err.Error(), "not enough data points") {
+				t.Fatalf("%d bars: error = %v, want not enough data points", tt.warmUp, err)
+			}
+
+			result, err := registry.Calculate(risingOHLCV(tt.warmUp+1), IndicatorParams{Name: tt.name})
+			if err != nil {
+				t.Fatalf("Calculate: %v", err)
+			}
+			if result.WarmUp != tt.warmUp {
+				t.Errorf("warm-up = %d, want %d", result.WarmUp, tt.warmUp)
+			}
+			// Первый бар после прогрева уже определен во всех выходах
+			for output, values := range result.Values {
+				if len(values) != 1 || math.IsNaN(values[0]) {
+					t.Errorf("%s = %v, want one defined value", output, values)
+				}
+			}
+		})
+	}
+}
+
+func TestIndicatorInvalidParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]float64
+	}{
+		{name: "sma", params: map[string]float64{"period": 0}},
+		{name: "sma", params: map[string]float64{"period": 2.5}},
+		{name: "sma", params: map[string]float64{"period": math.Inf(1)}},
+		{name: "sma", params: map[string]float64{"period": math.NaN()}},
+		{name: "sma", params: map[string]float64{"period": -3}},
+		{name: "macd", params: map[string]float64{"signal": 0}},
+		{name: "stochastic", params: map[string]float64{"d": 1.5}},
+		{name: "bollinger", params: map[string]float64{"k": math.Inf(1)}},
+		{name: "vwap", params: map[string]float64{"period": 0.5}},
+	}
+
+	registry := NewIndicatorRegistry()
+	for _, tt := range tests {
+		_, err := registry.Calculate(risingOHLCV(100), IndicatorParams{Name: tt.name, Params: tt.params})
+		if err == nil || !strings.Contains(err.Error(), "invalid parameter") {
+			t.Errorf("%s %v: error = %v, want invalid parameter", tt.name, tt.params, err)
+		}
+	}
+
+	// Множитель полос может быть дробным, а нулевой период VWAP означает накопление с начала ряда
+	for _, params := range []IndicatorParams{
+		{Name: "bollinger", Params: map[string]float64{"k": 1.5}},
+		{Name: "vwap", Params: map[string]float64{"period": 0}},
+	} {
+		if _, err := registry.Calculate(risingOHLCV(100), params); err != nil {
+			t.Errorf("%s %v: %v", params.Name, params.Params, err)
+		}
+	}
+}
+
+// risingOHLCV n свечей с растущими ценами и ненулевым диапазоном
+func risingOHLCV(n int) OHLCV {
+	var d OHLCV
+	for i := 0; i < n; i++ {
+		c := 100 + float64(i) + float64(i%3)
+		d.Open = append(d.Open, c-0.5)
+		d.High = append(d.High, c+1)
+		d.Low = append(d.Low, c-1)
+		d.Close = append(d.Close, c)
+		d.Volume = append(d.Volume, float64(10+i))
+	}
+	return d
+}
@@ -0,0 +1,214 @@
+package price_analysis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Режимы рынка по окну скользящего анализа
+const (
+	RegimeTrending      = "trending"
+	RegimeMeanReverting = "mean_reverting"
+	RegimeRandomWalk    = "random_walk"
+	RegimeTurbulent     = "turbulent_multifractal"
+)
+
+// ErrInsufficientData оценка Hurst или ширины спектра окна не определена: ряд окна плоский или слишком короткий
+var ErrInsufficientData = errors.New("insufficient data for regime classification")
+
+// RegimeThresholds пороги классификации режима. Нулевые поля заменяются значениями по умолчанию.
+type RegimeThresholds struct {
+	// TrendingHurst: Hurst не ниже порога — персистентный ряд, тренд
+	TrendingHurst float64 `json:"trendingHurst" query:"trendingHurst"`
+	// MeanRevertingHurst: Hurst не выше порога — антиперсистентный ряд, возврат к среднему
+	MeanRevertingHurst float64 `json:"meanRevertingHurst" query:"meanRevertingHurst"`
+	// TurbulentWidth: ширина мультифрактального спектра Δα окна не ниже порога — турбулентность.
+	// Порог абсолютный и не зависит от других окон запроса: на окне в 100 свечей у гауссова блуждания
+	// Δα редко выше 3.5, у ряда со скачками обычно 5–9.
+	TurbulentWidth float64 `json:"turbulentWidth" query:"turbulentWidth"`
+	// HurstMargin: удаление Hurst от порога, при котором уверенность достигает 1
+	HurstMargin float64 `json:"hurstMargin" query:"hurstMargin"`
+	// MinConfidence: окно с меньшей уверенностью сохраняет режим предыдущего окна, чтобы режим не мерцал у порогов
+	MinConfidence float64 `json:"minConfidence" query:"minConfidence"`
+}
+
+func DefaultRegimeThresholds() RegimeThresholds {
+	return RegimeThresholds{
+		TrendingHurst:      0.55,
+		MeanRevertingHurst: 0.45,
+		TurbulentWidth:     4,
+		HurstMargin:        0.15,
+		MinConfidence:      0.6,
+	}
+}
+
+// WithDefaults пороги, где незаданные поля взяты по умолчанию
+func (t RegimeThresholds) WithDefaults() RegimeThresholds {
+	d := DefaultRegimeThresholds()
+	if t.TrendingHurst == 0 {
+		t.TrendingHurst = d.TrendingHurst
+	}
+	if t.MeanRevertingHurst == 0 {
+		t.MeanRevertingHurst = d.MeanRevertingHurst
+	}
+	if t.TurbulentWidth == 0 {
+		t.TurbulentWidth = d.TurbulentWidth
+	}
+	if t.HurstMargin == 0 {
+		t.HurstMargin = d.HurstMargin
+	}
+	if t.MinConfidence == 0 {
+		t.MinConfidence = d.MinConfidence
+	}
+	return t
+}
+
+func (t RegimeThresholds) Validate() error {
+	switch {
+	case t.MeanRevertingHurst <= 0 || t.TrendingHurst >= 1 || t.MeanRevertingHurst >= t.TrendingHurst:
+		return fmt.Errorf("hurst thresholds must satisfy 0 < meanRevertingHurst < trendingHurst < 1: %v, %v", t.MeanRevertingHurst, t.TrendingHurst)
+	case t.TurbulentWidth <= 0:
+		return fmt.Errorf("turbulentWidth must be positive: %v", t.TurbulentWidth)
+	case t.HurstMargin <= 0:
+		return fmt.Errorf("hurstMargin must be positive: %v", t.HurstMargin)
+	case t.MinConfidence < 0.5 || t.MinConfidence > 1:
+		return fmt.Errorf("minConfidence must be in [0.5, 1]: %v", t.MinConfidence)
+	}
+	return nil
+}
+
+// RegimeWindow режим окна. Confidence — уверенность в показанном режиме: 1 вдали от порогов, 0.5 на пороге,
+// ниже 0.5 — значения окна уже за порогом, а режим удержан от предыдущего окна (Held).
+type RegimeWindow struct {
+	Index int
+	// Time время последней свечи окна
+	Time       time.Time
+	Regime     string
+	Confidence float64
+	Hurst      float64
+	// Width ширина мультифрактального спектра Δα окна
+	Width float64
+	// Held: новый режим окна определен с уверенностью ниже MinConfidence, окно сохранило режим предыдущего
+	Held bool
+}
+
+type RegimeTransition struct {
+	Index      int
+	Time       time.Time
+	From       string
+	To         string
+	Confidence float64
+}
+
+type RegimeAnalysis struct {
+	Thresholds  RegimeThresholds
+	Current     RegimeWindow
+	Windows     []RegimeWindow
+	Transitions []RegimeTransition
+}
+
+// ClassifyRegimes размечает окна скользящего анализа режимами и находит смены режима.
+// hurst, fdi и times — значения по окнам; times[i] — время последней свечи окна i.
+// Широкий спектр (турбулентность) проверяется раньше Hurst: на таком окне оценка Hurst ненадежна.
+func (p *PriceAnalysis) ClassifyRegimes(hurst []float64, fdi []Fdi, times []time.Time, thresholds RegimeThresholds) (RegimeAnalysis, error) {
+	if len(hurst) == 0 {
+		return RegimeAnalysis{}, fmt.Errorf("no windows for regime classification")
+	}
+	if len(fdi) != len(hurst) || len(times) != len(hurst) {
+		return RegimeAnalysis{}, fmt.Errorf("window series length mismatch: hurst %d, fdi %d, times %d", len(hurst), len(fdi), len(times))
+	}
+	if err := thresholds.Validate(); err != nil {
+		return RegimeAnalysis{}, err
+	}
+
+	analysis := RegimeAnalysis{
+		Thresholds: thresholds,
+		Windows:    make([]RegimeWindow, len(hurst)),
+	}
+
+	for i := range hurst {
+		width := fdi[i].Width
+		if !finite(hurst[i]) || !finite(width) {
+			return RegimeAnalysis{}, fmt.Errorf("window %d: hurst %v, width %v: %w", i, hurst[i], width, ErrInsufficientData)
+		}
+		regime := classifyWindow(hurst[i], width, thresholds)
+		window := RegimeWindow{
+			Index:      i,
+			Time:       times[i],
+			Regime:     regime,
+			Confidence: regimeConfidence(regime, hurst[i], width, thresholds),
+			Hurst:      hurst[i],
+			Width:      width,
+		}
+
+		if i > 0 {
+			prev := analysis.Windows[i-1]
+			if regime != prev.Regime && window.Confidence < thresholds.MinConfidence {
+				window.Regime = prev.Regime
+				window.Confidence = regimeConfidence(prev.Regime, hurst[i], width, thresholds)
+				window.Held = true
+			}
+			if window.Regime != prev.Regime {
+				analysis.Transitions = append(analysis.Transitions, RegimeTransition{
+					Index:      i,
+					Time:       times[i],
+					From:       prev.Regime,
+					To:         window.Regime,
+					Confidence: window.Confidence,
+				})
+			}
+		}
+
+		analysis.Windows[i] = window
+	}
+
+	analysis.Current = analysis.Windows[len(analysis.Windows)-1]
+	return analysis, nil
+}
+
+func classifyWindow(hurst, width float64, t RegimeThresholds) string {
+	switch {
+	case width >= t.TurbulentWidth:
+		return RegimeTurbulent
+	case hurst >= t.TrendingHurst:
+		return RegimeTrending
+	case hurst <= t.MeanRevertingHurst:
+		return RegimeMeanReverting
+	}
+	return RegimeRandomWalk
+}
+
+// regimeConfidence уверенность в том, что окно с hurst и width относится к regime. Для режимов по Hurst
+// учитывается и удаление от порога турбулентности: окно у этого порога не может быть уверенно трендовым.
+func regimeConfidence(regime string, hurst, width float64, t RegimeThresholds) float64 {
+	if regime == RegimeTurbulent {
+		return confidence(width-t.TurbulentWidth, t.TurbulentWidth)
+	}
+
+	calm := confidence(t.TurbulentWidth-width, t.TurbulentWidth)
+	switch regime {
+	case RegimeTrending:
+		return math.Min(calm, confidence(hurst-t.TrendingHurst, t.HurstMargin))
+	case RegimeMeanReverting:
+		return math.Min(calm, confidence(t.MeanRevertingHurst-hurst, t.HurstMargin))
+	}
+
+	// Случайное блуждание: уверенность растет к середине полосы между порогами
+	half := (t.TrendingHurst - t.MeanRevertingHurst) / 2
+	mid := t.MeanRevertingHurst + half
+	return math.Min(calm, confidence(half-math.Abs(hurst-mid), half))
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// confidence 0.5 на пороге, 1 на расстоянии scale за ним, 0 на расстоянии scale до него
+func confidence(distance, scale float64) float64 {
+	if scale <= 0 {
+		return 1
+	}
+	return 0.5 + 0.5*math.Max(-1, math.Min(1, distance/scale))
+}
@@ -0,0 +1,243 @@
+package price_analysis
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestClassifyWindow(t *testing.T) {
+	th := DefaultRegimeThresholds()
+	tests := []struct {
+		name  string
+		hurst float64
+		width float64
+		want  string
+	}{
+		{name: "persistent", hurst: 0.7, width: 1, want: RegimeTrending},
+		{name: "on trending threshold", hurst: 0.55, width: 1, want: RegimeTrending},
+		{name: "anti-persistent", hurst: 0.3, width: 1, want: RegimeMeanReverting},
+		{name: "on mean-reverting threshold", hurst: 0.45, width: 1, want: RegimeMeanReverting},
+		{name: "between thresholds", hurst: 0.5, width: 1, want: RegimeRandomWalk},
+		{name: "wide spectrum wins over hurst", hurst: 0.7, width: 6, want: RegimeTurbulent},
+		{name: "on turbulent threshold", hurst: 0.5, width: 4, want: RegimeTurbulent},
+		{name: "just below turbulent threshold", hurst: 0.5, width: 3.99, want: RegimeRandomWalk},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyWindow(tt.hurst, tt.width, th); got != tt.want {
+				t.Errorf("classifyWindow(%v, %v) = %s, want %s", tt.hurst, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegimeConfidence(t *testing.T) {
+	th := DefaultRegimeThresholds()
+	tests := []struct {
+		name   string
+		regime string
+		hurst  float64
+		width  float64
+		want   float64
+	}{
+		{name: "trending on threshold", regime: RegimeTrending, hurst: 0.55, width: 0, want: 0.5},
+		{name: "trending one margin away", regime: RegimeTrending, hurst: 0.70, width: 0, want: 1},
+		{name: "trending far away is capped", regime: RegimeTrending, hurst: 0.95, width: 0, want: 1},
+		{name: "trending half margin away", regime: RegimeTrending, hurst: 0.625, width: 0, want: 0.75},
+		{name: "trending near turbulence", regime: RegimeTrending, hurst: 0.9, width: 3, want: 0.625},
+		{name: "trending held below threshold", regime: RegimeTrending, hurst: 0.49, width: 0, want: 0.3},
+		{name: "mean reverting", regime: RegimeMeanReverting, hurst: 0.3, width: 0, want: 1},
+		{name: "random walk mid band", regime: RegimeRandomWalk, hurst: 0.5, width: 0, want: 1},
+		{name: "random walk on band edge", regime: RegimeRandomWalk, hurst: 0.55, width: 0, want: 0.5},
+		{name: "turbulent on threshold", regime: RegimeTurbulent, hurst: 0.5, width: 4, want: 0.5},
+		{name: "turbulent at twice the threshold", regime: RegimeTurbulent, hurst: 0.5, width: 8, want: 1},
+		{name: "turbulent held on calm window", regime: RegimeTurbulent, hurst: 0.5, width: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := regimeConfidence(tt.regime, tt.hurst, tt.width, th)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("regimeConfidence(%s, %v, %v) = %v, want %v", tt.regime, tt.hurst, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegimeThresholdsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*RegimeThresholds)
+		wantErr bool
+	}{
+		{name: "defaults", modify: func(*RegimeThresholds) {}},
+		{name: "hurst thresholds swapped", modify: func(t *RegimeThresholds) { t.TrendingHurst, t.MeanRevertingHurst = 0.4, 0.6 }, wantErr: true},
+		{name: "trending hurst not below 1", modify: func(t *RegimeThresholds) { t.TrendingHurst = 1 }, wantErr: true},
+		{name: "negative width", modify: func(t *RegimeThresholds) { t.TurbulentWidth = -1 }, wantErr: true},
+		{name: "large width is absolute, not normalized", modify: func(t *RegimeThresholds) { t.TurbulentWidth = 12 }},
+		{name: "negative margin", modify: func(t *RegimeThresholds) { t.HurstMargin = -0.1 }, wantErr: true},
+		{name: "min confidence below half", modify: func(t *RegimeThresholds) { t.MinConfidence = 0.4 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := DefaultRegimeThresholds()
+			tt.modify(&th)
+			if err := th.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := (RegimeThresholds{TurbulentWidth: 5}).WithDefaults(); got.TurbulentWidth != 5 || got.TrendingHurst != 0.55 {
+		t.Errorf("WithDefaults kept %+v", got)
+	}
+}
+
+// regimeSeries окна с заданными Hurst и шириной спектра, по часу между окнами
+func regimeSeries(points ...[2]float64) ([]float64, []Fdi, []time.Time) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	hurst := make([]float64, len(points))
+	fdi := make([]Fdi, len(points))
+	times := make([]time.Time, len(points))
+	for i, p := range points {
+		hurst[i] = p[0]
+		fdi[i] = Fdi{Width: p[1]}
+		times[i] = start.Add(time.Duration(i) * time.Hour)
+	}
+	return hurst, fdi, times
+}
+
+func TestClassifyRegimesTransitions(t *testing.T) {
+	p := &PriceAnalysis{}
+	hurst, fdi, times := regimeSeries(
+		[2]float64{0.75, 1},   // trending
+		[2]float64{0.545, 1},  // слабый random walk: удерживается trending
+		[2]float64{0.50, 1},   // уверенный random walk
+		[2]float64{0.50, 9},   // турбулентность
+		[2]float64{0.30, 4.2}, // турбулентность у порога, режим тот же
+		[2]float64{0.30, 1},   // уверенный возврат к среднему
+	)
+
+	analysis, err := p.ClassifyRegimes(hurst, fdi, times, DefaultRegimeThresholds())
+	if err != nil {
+		t.Fatalf("ClassifyRegimes: %v", err)
+	}
+
+	wantRegimes := []string{RegimeTrending, RegimeTrending, RegimeRandomWalk, RegimeTurbulent, RegimeTurbulent, RegimeMeanReverting}
+	wantHeld := []bool{false, true, false, false, false, false}
+	for i, w := range analysis.Windows {
+		if w.Regime != wantRegimes[i] || w.Held != wantHeld[i] {
+			t.Errorf("window %d = %s held %v, want %s held %v", i, w.Regime, w.Held, wantRegimes[i], wantHeld[i])
+		}
+		if w.Width != fdi[i].Width || !w.Time.Equal(times[i]) {
+			t.Errorf("window %d = %+v, want width %v and time %s", i, w, fdi[i].Width, times[i])
+		}
+	}
+
+	// Удержанное окно сообщает уверенность в показанном режиме: Hurst уже ниже порога тренда
+	held := analysis.Windows[1]
+	if want := regimeConfidence(RegimeTrending, 0.545, 1, DefaultRegimeThresholds()); held.Confidence != want || held.Confidence >= 0.5 {
+		t.Errorf("held window confidence = %v, want %v below 0.5", held.Confidence, want)
+	}
+
+	// Окно 4 турбулентное само по себе (ширина выше порога), но с низкой уверенностью
+	if w := analysis.Windows[4]; w.Held || w.Confidence >= DefaultRegimeThresholds().MinConfidence {
+		t.Errorf("window 4 = %+v, want a low-confidence turbulent window", w)
+	}
+
+	wantTransitions := []RegimeTransition{
+		{Index: 2, From: RegimeTrending, To: RegimeRandomWalk},
+		{Index: 3, From: RegimeRandomWalk, To: RegimeTurbulent},
+		{Index: 5, From: RegimeTurbulent, To: RegimeMeanReverting},
+	}
+	if len(analysis.Transitions) != len(wantTransitions) {
+		t.Fatalf("transitions = %+v, want %+v", analysis.Transitions, wantTransitions)
+	}
+	for i, want := range wantTransitions {
+		got := analysis.Transitions[i]
+		if got.Index != want.Index || got.From != want.From || got.To != want.To {
+			t.Errorf("transition %d = %+v, want %+v", i, got, want)
+		}
+		if got.Confidence != analysis.Windows[got.Index].Confidence || !got.Time.Equal(times[got.Index]) {
+			t.Errorf("transition %d = %+v, want confidence and time of window %d", i, got, got.Index)
+		}
+	}
+
+	if analysis.Current != analysis.Windows[len(analysis.Windows)-1] {
+		t.Errorf("current = %+v, want the last window", analysis.Current)
+	}
+}
+
+func TestClassifyRegimesIsAbsolute(t *testing.T) {
+	p := &PriceAnalysis{}
+	classify := func(points ...[2]float64) RegimeAnalysis {
+		t.Helper()
+		hurst, fdi, times := regimeSeries(points...)
+		analysis, err := p.ClassifyRegimes(hurst, fdi, times, DefaultRegimeThresholds())
+		if err != nil {
+			t.Fatalf("ClassifyRegimes: %v", err)
+		}
+		return analysis
+	}
+
+	// Одно и то же окно классифицируется одинаково, какие бы окна ни были рядом
+	alone := classify([2]float64{0.5, 2}).Current
+	if alone.Regime != RegimeRandomWalk {
+		t.Fatalf("window = %+v, want random walk", alone)
+	}
+	for name, analysis := range map[string]RegimeAnalysis{
+		"calm": classify([2]float64{0.5, 2}, [2]float64{0.5, 0.1}, [2]float64{0.5, 0.2}),
+		"wild": classify([2]float64{0.5, 2}, [2]float64{0.5, 11}, [2]float64{0.5, 12}),
+	} {
+		if got := analysis.Windows[0]; got.Regime != alone.Regime || got.Confidence != alone.Confidence {
+			t.Errorf("%s neighbours: window = %+v, want %+v", name, got, alone)
+		}
+	}
+}
+
+func TestClassifyRegimesErrors(t *testing.T) {
+	p := &PriceAnalysis{}
+	hurst, fdi, times := regimeSeries([2]float64{0.5, 1}, [2]float64{0.5, 1})
+
+	if _, err := p.ClassifyRegimes(nil, nil, nil, DefaultRegimeThresholds()); err == nil {
+		t.Error("no windows: expected error")
+	}
+	if _, err := p.ClassifyRegimes(hurst, fdi[:1], times, DefaultRegimeThresholds()); err == nil {
+		t.Error("length mismatch: expected error")
+	}
+	// Плоское или короткое окно дает NaN вместо Hurst; такой результат не кодируется в JSON
+	for name, window := range map[string][2]float64{
+		"nan hurst": {math.NaN(), 1},
+		"inf hurst": {math.Inf(1), 1},
+		"nan width": {0.5, math.NaN()},
+	} {
+		h, f, ts := regimeSeries([2]float64{0.5, 1}, window)
+		if _, err := p.ClassifyRegimes(h, f, ts, DefaultRegimeThresholds()); !errors.Is(err, ErrInsufficientData) {
+			t.Errorf("%s: error = %v, want ErrInsufficientData", name, err)
+		}
+	}
+	// Плоский ряд цен: спектр окна не определен
+	flat := make([]float64, 120)
+	for i := range flat {
+		flat[i] = 100
+	}
+	pa := NewPriceAnalysis()
+	fdiFlat, _, hurstFlat, err := pa.SlidingWindowAnalysis(context.Background(), flat, 100)
+	if err != nil {
+		t.Fatalf("SlidingWindowAnalysis: %v", err)
+	}
+	_, err = pa.ClassifyRegimes(hurstFlat, fdiFlat, make([]time.Time, len(hurstFlat)), DefaultRegimeThresholds())
+	if !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("flat prices: error = %v, want ErrInsufficientData", err)
+	}
+	bad := DefaultRegimeThresholds()
+	bad.TurbulentWidth = 0
+	if _, err := p.ClassifyRegimes(hurst, fdi, times, bad); err == nil {
+		t.Error("invalid thresholds: expected error")
+	}
+}
@@ -0,0 +1,80 @@
+package price_analysis
+
+import (
+	"math"
+	"testing"
+)
+
+// Пример RSI(14) из StockCharts ChartSchool ("Relative Strength Index (RSI)", таблица расчета
+// в rsi.xls): первое значение — простое среднее изменений за 14 периодов, дальше сглаживание Уайлдера.
+// Цены закрытия — из таблицы с четырьмя знаками, на графике они округлены до сотых; RSI округлен до сотых.
+var stockChartsCloses = []float64{
+	44.3389, 44.0902, 44.1497, 43.6124, 44.3278, 44.8264, 45.0955, 45.4245, 45.8433, 46.0826,
+	45.8931, 46.0328, 45.6140, 46.2820, 46.2820, 46.0028, 46.0328, 46.4116, 46.2222, 45.6439,
+	46.2122, 46.2521, 45.7137, 46.4515, 45.7835, 45.3548, 44.0288, 44.1783, 44.2181, 44.5672,
+	43.4205, 42.6628, 43.1314,
+}
+
+var stockChartsRSI = []float64{
+	70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38,
+	54.71, 50.42, 39.99, 41.46, 41.87, 45.46, 37.30, 33.08, 37.77,
+}
+
+func TestCalculateRSIStockChartsReference(t *testing.T) {
+	p := &PriceAnalysis{RSIPeriod: 14}
+	got, err := p.CalculateRSI(stockChartsCloses)
+	if err != nil {
+		t.Fatalf("CalculateRSI: %v", err)
+	}
+	if len(got) != len(stockChartsRSI) {
+		t.Fatalf("len = %d, want %d", len(got), len(stockChartsRSI))
+	}
+	for i, want := range stockChartsRSI {
+		if math.Abs(got[i]-want) > 0.005 {
+			t.Errorf("RSI[%d] (close %v) = %.4f, want %.2f", i, stockChartsCloses[i+14], got[i], want)
+		}
+	}
+}
+
+func TestCalculateRSIEdgeCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		period  int
+		prices  []float64
+		want    []float64
+		wantErr bool
+	}{
+		{name: "empty", period: 3, wantErr: true},
+		{name: "zero period", period: 0, prices: []float64{1, 2}, wantErr: true},
+		{name: "too short", period: 3, prices: []float64{1, 2, 3}, wantErr: true},
+		{name: "only gains", period: 3, prices: []float64{1, 2, 3, 4, 5}, want: []float64{100, 100}},
+		{name: "only losses", period: 3, prices: []float64{5, 4, 3, 2, 1}, want: []float64{0, 0}},
+		{name: "flat", period: 3, prices: []float64{2, 2, 2, 2}, want: []float64{50}},
+		// avgGain 1/3, avgLoss 1/3 → 50; затем gain 1: avgGain 5/9, avgLoss 2/9 → 100 - 100/3.5
+		{name: "wilder smoothing", period: 3, prices: []float64{1, 2, 1, 1, 2}, want: []float64{50, 100 - 100/3.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PriceAnalysis{RSIPeriod: tt.period}
+			got, err := p.CalculateRSI(tt.prices)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CalculateRSI: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("RSI[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package price_analysis
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"mamonolitmvp/internal/math/fractal_analysis"
@@ -12,6 +13,9 @@ type Signal struct {
 	ShortSMA    []float64
 	LongSMA     []float64
 	TrendFactor float64
+	RSI         []float64
+	RSITrend    string
+	Indicators  []IndicatorResult
 	Hurst       float64
 	Mfdfa
 	MfSpectrum
@@ -36,6 +40,10 @@ type PriceAnalysis struct {
 	fa             *fractal_analysis.FractalDimension
 	ShortSmaPeriod int
 	LongSmaPeriod  int
+	RSIPeriod      int
+	// FlatAmplitude относительный размах цен, ниже которого AnalyzeTrendWithAmplitude видит флэт
+	FlatAmplitude float64
+	Indicators    *IndicatorRegistry
 }
 
 type SlidingWindow struct {
@@ -48,6 +56,9 @@ func NewPriceAnalysis() *PriceAnalysis {
 		fa:             fractal_analysis.NewFractalDimension(),
 		ShortSmaPeriod: 50,
 		LongSmaPeriod:  100,
+		RSIPeriod:      14,
+		FlatAmplitude:  0.02,
+		Indicators:     NewIndicatorRegistry(),
 	}
 }
 
@@ -108,6 +119,11 @@ func (p *PriceAnalysis) TotalSignal(prices []float64) (Signal, error) {
 
 	trendFactor := (smaShort[len(smaShort)-1] - smaLong[len(smaLong)-1]) / smaLong[len(smaLong)-1]
 
+	rsiValues, err := p.CalculateRSI(prices)
+	if err != nil {
+		return Signal{}, err
+	}
+
 	width, asym, curvature, fdi := p.fa.CalcFdi(alpha, fAlpha, tau)
 
 	normWidth, normAsym, normCurvature, normFdi, err := p.fa.CalcNormalizedFdi(alpha, fAlpha, tau)
@@ -120,6 +136,8 @@ func (p *PriceAnalysis) TotalSignal(prices []float64) (Signal, error) {
 		ShortSMA:    smaShort,
 		LongSMA:     smaLong,
 		TrendFactor: trendFactor,
+		RSI:         rsiValues,
+		RSITrend:    rsiTrend(rsiValues[len(rsiValues)-1]),
 		Hurst:       hurst,
 		Mfdfa: Mfdfa{
 			LogFq: stringLogFq,
@@ -149,7 +167,8 @@ func (p *PriceAnalysis) TotalSignal(prices []float64) (Signal, error) {
 	return signal, nil
 }
 
-func (p *PriceAnalysis) SlidingWindowAnalysis(prices []float64, windowSize int) ([]Fdi, []NormalizeFdi, []float64, error) {
+// SlidingWindowAnalysis считает сигнал в каждом окне; между окнами проверяет отмену ctx
+func (p *PriceAnalysis) SlidingWindowAnalysis(ctx context.Context, prices []float64, windowSize int) ([]Fdi, []NormalizeFdi, []float64, error) {
 	if len(prices) < windowSize {
 		return nil, nil, nil, errors.New("длина данных меньше размера окна")
 	}
@@ -158,6 +177,10 @@ func (p *PriceAnalysis) SlidingWindowAnalysis(prices []float64, windowSize int)
 	var hurstSeries []float64
 
 	for i := 0; i <= len(prices)-windowSize; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
+
 		window := prices[i : i+windowSize]
 
 		signal, err := p.TotalSignal(window)
@@ -1,7 +1,10 @@
 // Package price_analysis include RSI, SMA, Fractal Dimension
 package price_analysis
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+)
 
 func (p *PriceAnalysis) CalculateShortMovingAverage(prices []float64) ([]float64, error) {
 	if len(prices) < p.ShortSmaPeriod {
@@ -61,10 +64,18 @@ func (p *PriceAnalysis) AnalyzeTrendWithSMA(prices []float64) string {
 	}
 }
 
+// AnalyzeTrendWithAmplitude размах цен относительно минимальной цены: меньше FlatAmplitude — флэт.
+// Порог относительный, чтобы не зависеть от масштаба цены инструмента.
 func (p *PriceAnalysis) AnalyzeTrendWithAmplitude(prices []float64) string {
-	amplitude := p.CalculateAmplitude(prices)
+	if len(prices) == 0 {
+		return ""
+	}
+	minPrice := slices.Min(prices)
+	if minPrice <= 0 {
+		return ""
+	}
 
-	if amplitude < 2.0 {
+	if p.CalculateAmplitude(prices)/minPrice < p.FlatAmplitude {
 		return "Flat"
 	} else {
 		return "Trend"
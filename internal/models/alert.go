@@ -0,0 +1,222 @@
+package models
+
+import (
+	"mamonolitmvp/internal/math/price_analysis"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Виды оповещений
+const (
+	// AlertRegimeChange смена режима рынка по окнам Hurst/FDI
+	AlertRegimeChange = "regime_change"
+	// AlertHurstCross пересечение порога Threshold показателем Херста последнего окна
+	AlertHurstCross = "hurst_cross"
+	// AlertSMACross пересечение короткой и длинной SMA
+	AlertSMACross = "sma_cross"
+)
+
+// Направления пересечения для hurst_cross и sma_cross: up — снизу вверх (для SMA — короткая выше длинной)
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+	DirectionBoth = "both"
+)
+
+// Каналы доставки оповещений
+const (
+	SinkWebhook  = "webhook"
+	SinkTelegram = "telegram"
+	SinkSMTP     = "smtp"
+)
+
+// Alert оповещение по инструменту. Проверяется при сохранении новых свечей интервала;
+// срабатывает, когда состояние (режим или сторона порога) меняется по сравнению с прошлой проверкой.
+type Alert struct {
+	Id           uint   `json:"id" gorm:"primaryKey"`
+	Name         string `json:"name" gorm:"size:100"`
+	InstrumentId string `json:"instrumentId" gorm:"size:255;index:idx_alert_instrument_interval"`
+	Interval     string `json:"interval" gorm:"size:50;index:idx_alert_instrument_interval"`
+	Kind         string `json:"kind" gorm:"size:20"`
+	// Threshold порог Hurst для hurst_cross
+	Threshold float64 `json:"threshold"`
+	// Direction up, down или both для hurst_cross и sma_cross
+	Direction string `json:"direction" gorm:"size:10"`
+	// Regime для regime_change: срабатывать только при входе в этот режим; пусто — при любой смене
+	Regime string `json:"regime,omitempty" gorm:"size:30"`
+	Sink   string `json:"sink" gorm:"size:20"`
+	// Target получатель: URL для webhook, chat_id для telegram, адрес почты для smtp
+	Target string `json:"target" gorm:"size:500"`
+	// CooldownSeconds после срабатывания новые срабатывания не отправляются, а только записываются
+	CooldownSeconds int  `json:"cooldownSeconds"`
+	Enabled         bool `json:"enabled"`
+
+	// LastState состояние на последней проверке: режим или above/below
+	LastState string `json:"lastState,omitempty" gorm:"size:30"`
+	// LastCandleTime последняя проверенная свеча: повторная проверка на той же свече ничего не отправляет
+	LastCandleTime  time.Time `json:"lastCandleTime"`
+	LastEvaluatedAt time.Time `json:"lastEvaluatedAt"`
+	LastFiredAt     time.Time `json:"lastFiredAt"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// Cooldown активна ли пауза после последнего срабатывания
+func (a Alert) Cooldown(now time.Time) bool {
+	return !a.LastFiredAt.IsZero() && now.Before(a.LastFiredAt.Add(time.Duration(a.CooldownSeconds)*time.Second))
+}
+
+type AlertRequest struct {
+	Name         string  `json:"name"`
+	InstrumentId string  `json:"instrumentId"`
+	Interval     string  `json:"interval"`
+	Kind         string  `json:"kind"`
+	Threshold    float64 `json:"threshold"`
+	Direction    string  `json:"direction"`
+	Regime       string  `json:"regime"`
+	Sink         string  `json:"sink"`
+	Target       string  `json:"target"`
+	// CooldownSeconds: по умолчанию 3600, 0 — без паузы
+	CooldownSeconds *int `json:"cooldownSeconds"`
+	// Enabled: по умолчанию true
+	Enabled *bool `json:"enabled"`
+}
+
+const defaultAlertCooldown = 3600
+
+func (r AlertRequest) Validate() error {
+	var v ValidationError
+	if r.InstrumentId == "" {
+		v.Add("instrumentId", "is required")
+	}
+	v.interval("interval", r.Interval)
+	if len(r.Name) > 100 {
+		v.Add("name", "must be at most 100 characters")
+	}
+
+	switch r.Kind {
+	case AlertRegimeChange:
+		switch r.Regime {
+		case "", price_analysis.RegimeTrending, price_analysis.RegimeMeanReverting,
+			price_analysis.RegimeRandomWalk, price_analysis.RegimeTurbulent:
+		default:
+			v.Add("regime", "unknown regime %q", r.Regime)
+		}
+	case AlertHurstCross:
+		if r.Threshold <= 0 || r.Threshold >= 1 {
+			v.Add("threshold", "must be in (0, 1)")
+		}
+	case AlertSMACross:
+	default:
+		v.Add("kind", "must be one of %s, %s, %s", AlertRegimeChange, AlertHurstCross, AlertSMACross)
+	}
+	switch r.Direction {
+	case "", DirectionUp, DirectionDown, DirectionBoth:
+	default:
+		v.Add("direction", "must be one of %s, %s, %s", DirectionUp, DirectionDown, DirectionBoth)
+	}
+
+	switch r.Sink {
+	case SinkWebhook:
+		if u, err := url.Parse(r.Target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.Add("target", "must be an http(s) URL")
+		}
+	case SinkTelegram:
+		if r.Target == "" {
+			v.Add("target", "is required: telegram chat_id")
+		}
+	case SinkSMTP:
+		if _, err := mail.ParseAddress(r.Target); err != nil {
+			v.Add("target", "must be an email address")
+		}
+	default:
+		v.Add("sink", "must be one of %s, %s, %s", SinkWebhook, SinkTelegram, SinkSMTP)
+	}
+	if len(r.Target) > 500 {
+		v.Add("target", "must be at most 500 characters")
+	}
+
+	if r.CooldownSeconds != nil && *r.CooldownSeconds < 0 {
+		v.Add("cooldownSeconds", "must not be negative")
+	}
+	return v.Err()
+}
+
+// Alert определение оповещения из запроса; состояние проверок не заполняется
+func (r AlertRequest) Alert() Alert {
+	cooldown := defaultAlertCooldown
+	if r.CooldownSeconds != nil {
+		cooldown = *r.CooldownSeconds
+	}
+	direction := r.Direction
+	if direction == "" {
+		direction = DirectionBoth
+	}
+	return Alert{
+		Name:            r.Name,
+		InstrumentId:    r.InstrumentId,
+		Interval:        r.Interval,
+		Kind:            r.Kind,
+		Threshold:       r.Threshold,
+		Direction:       direction,
+		Regime:          r.Regime,
+		Sink:            r.Sink,
+		Target:          r.Target,
+		CooldownSeconds: cooldown,
+		Enabled:         r.Enabled == nil || *r.Enabled,
+	}
+}
+
+// AlertEvent срабатывание оповещения
+type AlertEvent struct {
+	Id         uint      `json:"id" gorm:"primaryKey"`
+	AlertId    uint      `json:"alertId" gorm:"index"`
+	CandleTime time.Time `json:"candleTime"`
+	From       string    `json:"from" gorm:"size:30"`
+	To         string    `json:"to" gorm:"size:30"`
+	Message    string    `json:"message" gorm:"type:TEXT"`
+	// Suppressed срабатывание пришлось на паузу после предыдущего и не отправлялось
+	Suppressed bool `json:"suppressed"`
+	Delivered  bool `json:"delivered"`
+	// Error ошибка доставки
+	Error     string    `json:"error,omitempty" gorm:"type:TEXT"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AlertResponse struct {
+	Alert Alert `json:"alert"`
+}
+
+type AlertsResponse struct {
+	Alerts []Alert `json:"alerts"`
+}
+
+type AlertEventsResponse struct {
+	Events []AlertEvent `json:"events"`
+}
+
+// ListAlertEventsRequest limit последних срабатываний; 0 — DefaultPageLimit
+type ListAlertEventsRequest struct {
+	Limit int `json:"limit" query:"limit"`
+}
+
+func (r ListAlertEventsRequest) Validate() error {
+	var v ValidationError
+	if r.Limit < 0 || r.Limit > MaxPageLimit {
+		v.Add("limit", "must be in [0, %d]", MaxPageLimit)
+	}
+	return v.Err()
+}
+
+// ParseAlertID разбирает идентификатор оповещения из пути запроса
+func ParseAlertID(value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		var v ValidationError
+		v.Add("id", "must be a positive integer")
+		return 0, v.Err()
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+const (
+	BackfillRunning = "running"
+	BackfillDone    = "done"
+	BackfillFailed  = "failed"
+)
+
+// BackfillCheckpoint состояние загрузки истории свечей инструмента: свечи [From, Cursor) уже сохранены
+type BackfillCheckpoint struct {
+	InstrumentId string    `json:"instrumentId" gorm:"primaryKey;size:255"`
+	Interval     string    `json:"interval" gorm:"primaryKey;size:50"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Cursor       time.Time `json:"cursor"`
+	Chunks       int       `json:"chunks"`
+	Candles      int       `json:"candles"`
+	Status       string    `json:"status" gorm:"size:20"`
+	Error        string    `json:"error,omitempty"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type BackfillRequest struct {
+	InstrumentId string `json:"instrumentId"`
+	Interval     string `json:"interval"`
+	// To: конец загрузки, по умолчанию текущее время. example: "2025-02-16T17:33:53.311Z"
+	To string `json:"to"`
+}
+
+type BackfillResponse struct {
+	Checkpoint BackfillCheckpoint `json:"checkpoint"`
+}
+
+func (r BackfillRequest) Validate() error {
+	var v ValidationError
+	if r.InstrumentId == "" {
+		v.Add("instrumentId", "is required")
+	}
+	v.interval("interval", r.Interval)
+	if r.To != "" {
+		v.parseTime("to", r.To)
+	}
+	return v.Err()
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"mamonolitmvp/internal/backtest"
+	"mamonolitmvp/internal/math/coefficients_calculation"
+)
+
+type BacktestRequest struct {
+	GetCandlesRequest
+	// InitialCash: начальный капитал в валюте инструмента, по умолчанию 1 000 000
+	InitialCash float64 `json:"initialCash"`
+	// Commission: доля оборота сделки, например 0.0005
+	Commission float64 `json:"commission"`
+	// SlippageTicks: сдвиг цены исполнения против сделки в шагах цены инструмента
+	SlippageTicks int `json:"slippageTicks"`
+	// Lookback: число закрытых свечей для расчета сигнала, по умолчанию 100
+	Lookback int `json:"lookback"`
+	// Step: сигнал пересчитывается каждые Step свечей, по умолчанию 1
+	Step int `json:"step"`
+	// TrendThreshold: порог TrendFactor для входа и выхода, по умолчанию 0.01
+	TrendThreshold float64 `json:"trendThreshold"`
+	// RSIOverbought: RSI, при котором позиция закрывается, по умолчанию 70
+	RSIOverbought float64 `json:"rsiOverbought"`
+	// MinHurst: минимальный показатель Херста для входа, по умолчанию 0.5
+	MinHurst float64 `json:"minHurst"`
+	// Confidence, RiskFreeRate, PeriodsPerYear: параметры метрик риска кривой капитала, как в /api/v1/risk
+	Confidence     float64 `json:"confidence"`
+	RiskFreeRate   float64 `json:"riskFreeRate"`
+	PeriodsPerYear float64 `json:"periodsPerYear"`
+}
+
+func (r BacktestRequest) Validate() error {
+	var v ValidationError
+	r.validate(&v, false)
+	if r.InitialCash < 0 {
+		v.Add("initialCash", "must not be negative")
+	}
+	if r.Commission < 0 || r.Commission >= 1 {
+		v.Add("commission", "must be in [0, 1)")
+	}
+	if r.SlippageTicks < 0 {
+		v.Add("slippageTicks", "must not be negative")
+	}
+	if r.Lookback < 0 {
+		v.Add("lookback", "must not be negative")
+	}
+	if r.Step < 0 {
+		v.Add("step", "must not be negative")
+	}
+	if r.TrendThreshold < 0 {
+		v.Add("trendThreshold", "must not be negative")
+	}
+	if r.RSIOverbought < 0 || r.RSIOverbought > 100 {
+		v.Add("rsiOverbought", "must be in [0, 100]")
+	}
+	if r.MinHurst < 0 || r.MinHurst > 1 {
+		v.Add("minHurst", "must be in [0, 1]")
+	}
+	if r.Confidence < 0 || r.Confidence >= 1 {
+		v.Add("confidence", "must be in (0, 1)")
+	}
+	if r.PeriodsPerYear < 0 {
+		v.Add("periodsPerYear", "must not be negative")
+	}
+	return v.Err()
+}
+
+type BacktestResponse struct {
+	Ticker            string                        `json:"ticker"`
+	InstrumentId      string                        `json:"instrumentId"`
+	Interval          string                        `json:"interval"`
+	Lot               int                           `json:"lot"`
+	MinPriceIncrement float64                       `json:"minPriceIncrement"`
+	Summary           backtest.Summary              `json:"summary"`
+	Risk              coefficients_calculation.Risk `json:"risk"`
+	Equity            []backtest.EquityPoint        `json:"equity"`
+	Trades            []backtest.Trade              `json:"trades"`
+}
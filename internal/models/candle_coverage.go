@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// CandleCoverage диапазон [From, To), за который свечи инструмента с интервалом Interval
+// уже загружены из Tinkoff и сохранены в базе
+type CandleCoverage struct {
+	InstrumentId string    `json:"instrumentId" gorm:"primaryKey;size:255"`
+	Interval     string    `json:"interval" gorm:"primaryKey;size:50"`
+	From         time.Time `json:"from" gorm:"primaryKey"`
+	To           time.Time `json:"to"`
+}
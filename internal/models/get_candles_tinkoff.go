@@ -1,11 +1,13 @@
 package models
 
+import "time"
+
 type GetCandlesRequest struct {
-	Figi string `json:"figi"`
+	Figi string `json:"figi" query:"figi"`
 	// From: example: "2025-02-16T17:33:53.311Z"
-	From string `json:"from"`
+	From string `json:"from" query:"from"`
 	// To: example: "2025-02-16T17:33:53.311Z"
-	To string `json:"to"`
+	To string `json:"to" query:"to"`
 	// CANDLE_INTERVAL_UNSPECIFIED Интервал не определён.
 	//
 	// CANDLE_INTERVAL_1_MIN от 1 минуты до 1 дня.
@@ -33,10 +35,66 @@ type GetCandlesRequest struct {
 	// CANDLE_INTERVAL_WEEK от 1 недели до 2 лет.
 	//
 	// CANDLE_INTERVAL_MONTH 	от 1 месяца до 10 лет.
-	Interval     string `json:"interval"`
-	InstrumentId string `json:"instrumentId"`
+	Interval     string `json:"interval" query:"interval"`
+	InstrumentId string `json:"instrumentId" query:"instrumentId"`
+}
+
+// Validate проверяет инструмент, метки времени и интервал. Период не ограничен:
+// свечи для анализа загружаются кусками, допустимыми для интервала.
+func (r GetCandlesRequest) Validate() error {
+	var v ValidationError
+	r.validate(&v, false)
+	return v.Err()
+}
+
+// ValidateWithinLimit как Validate, но период должен укладываться в один запрос GetCandles
+func (r GetCandlesRequest) ValidateWithinLimit() error {
+	var v ValidationError
+	r.validate(&v, true)
+	return v.Err()
+}
+
+func (r GetCandlesRequest) validate(v *ValidationError, withinLimit bool) {
+	if r.InstrumentId == "" {
+		v.Add("instrumentId", "is required")
+	}
+	v.validateRange(r.From, r.To, r.Interval, withinLimit)
 }
 
 type GetCandlesResponse struct {
 	Candles []HistoricCandle `json:"candles"`
 }
+
+// CandleIntervalLimit максимальный период одного запроса GetCandles для интервала
+type CandleIntervalLimit struct {
+	Years  int
+	Months int
+	Days   int
+}
+
+// Add конец допустимого периода запроса, начинающегося в t
+func (l CandleIntervalLimit) Add(t time.Time) time.Time {
+	return t.AddDate(l.Years, l.Months, l.Days)
+}
+
+// Sub начало допустимого периода запроса, заканчивающегося в t
+func (l CandleIntervalLimit) Sub(t time.Time) time.Time {
+	return t.AddDate(-l.Years, -l.Months, -l.Days)
+}
+
+// CandleIntervalLimits ограничения периода запроса из описания GetCandlesRequest.Interval
+var CandleIntervalLimits = map[string]CandleIntervalLimit{
+	"CANDLE_INTERVAL_1_MIN":  {Days: 1},
+	"CANDLE_INTERVAL_2_MIN":  {Days: 1},
+	"CANDLE_INTERVAL_3_MIN":  {Days: 1},
+	"CANDLE_INTERVAL_5_MIN":  {Days: 1},
+	"CANDLE_INTERVAL_10_MIN": {Days: 1},
+	"CANDLE_INTERVAL_15_MIN": {Days: 1},
+	"CANDLE_INTERVAL_30_MIN": {Days: 2},
+	"CANDLE_INTERVAL_HOUR":   {Days: 7},
+	"CANDLE_INTERVAL_2_HOUR": {Months: 1},
+	"CANDLE_INTERVAL_4_HOUR": {Months: 1},
+	"CANDLE_INTERVAL_DAY":    {Years: 1},
+	"CANDLE_INTERVAL_WEEK":   {Years: 2},
+	"CANDLE_INTERVAL_MONTH":  {Years: 10},
+}
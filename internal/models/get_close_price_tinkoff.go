@@ -8,17 +8,12 @@ type GetClosePricesRequest struct {
 	Instruments []InstrumentRequest `json:"instruments"`
 }
 
-type Price struct {
-	Units string `json:"units"`
-	Nano  int `json:"nano"`
-}
-
 type ClosePrice struct {
-	Figi                string `json:"figi"`
-	InstrumentUid       string `json:"instrumentUid"`
-	Price               Price  `json:"price"`
-	EveningSessionPrice Price  `json:"eveningSessionPrice"`
-	Time                string `json:"time"`
+	Figi                string    `json:"figi"`
+	InstrumentUid       string    `json:"instrumentUid"`
+	Price               Quotation `json:"price"`
+	EveningSessionPrice Quotation `json:"eveningSessionPrice"`
+	Time                string    `json:"time"`
 }
 
 type ClosePricesResponse struct {
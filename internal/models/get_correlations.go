@@ -0,0 +1,52 @@
+package models
+
+import "mamonolitmvp/internal/math/correlation_analysis"
+
+type GetCorrelationsRequest struct {
+	InstrumentIds []string `json:"instrumentIds" query:"instrumentIds"`
+	// From: example: "2025-02-16T17:33:53.311Z"
+	From string `json:"from" query:"from"`
+	// To: example: "2025-02-16T17:33:53.311Z"
+	To string `json:"to" query:"to"`
+	// Interval: интервал свечей, по умолчанию CANDLE_INTERVAL_DAY
+	Interval string `json:"interval" query:"interval"`
+	// Method: "pearson" (по умолчанию), "spearman" или "kendall"
+	Method string `json:"method" query:"method"`
+	// Missing: "drop" — только общие бары (по умолчанию), "ffill" — заполнение последним значением
+	Missing string `json:"missing" query:"missing"`
+	// Window: размер скользящего окна в барах, 0 — без скользящих корреляций
+	Window int `json:"window" query:"window"`
+	Step   int `json:"step" query:"step"`
+}
+
+// Validate проверяет запрос; пустой Interval допустим и заменяется значением по умолчанию
+func (r GetCorrelationsRequest) Validate() error {
+	var v ValidationError
+	if len(r.InstrumentIds) < 2 {
+		v.Add("instrumentIds", "at least two instruments required")
+	}
+
+	interval := r.Interval
+	if interval == "" {
+		interval = "CANDLE_INTERVAL_DAY"
+	}
+	v.validateRange(r.From, r.To, interval, false)
+
+	switch r.Method {
+	case "", correlation_analysis.Pearson, correlation_analysis.Spearman, correlation_analysis.Kendall:
+	default:
+		v.Add("method", "must be one of pearson, spearman, kendall")
+	}
+	switch r.Missing {
+	case "", correlation_analysis.MissingDrop, correlation_analysis.MissingForwardFill:
+	default:
+		v.Add("missing", "must be one of drop, ffill")
+	}
+	if r.Window < 0 {
+		v.Add("window", "must not be negative")
+	}
+	if r.Step < 0 {
+		v.Add("step", "must not be negative")
+	}
+	return v.Err()
+}
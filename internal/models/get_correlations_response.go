@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"mamonolitmvp/internal/math/correlation_analysis"
+)
+
+// GetCorrelationsResponse ответ /api/v1/correlations. Имена полей зафиксированы в v1 API
+type GetCorrelationsResponse struct {
+	Method        string             `json:"Method"`
+	InstrumentIds []string           `json:"InstrumentIds"`
+	Alignment     AlignmentResponse  `json:"Alignment"`
+	Matrix        [][]float64        `json:"Matrix"`
+	Rolling       []RollingResponse  `json:"Rolling"`
+	Clustering    ClusteringResponse `json:"Clustering"`
+}
+
+// AlignmentResponse итог выравнивания рядов на общую шкалу времени
+type AlignmentResponse struct {
+	Missing string    `json:"Missing"`
+	Bars    int       `json:"Bars"`
+	Dropped int       `json:"Dropped"`
+	Filled  int       `json:"Filled"`
+	From    time.Time `json:"From"`
+	To      time.Time `json:"To"`
+}
+
+// RollingResponse скользящая корреляция пары инструментов по концам окон
+type RollingResponse struct {
+	A      string      `json:"A"`
+	B      string      `json:"B"`
+	Times  []time.Time `json:"Times"`
+	Values []float64   `json:"Values"`
+}
+
+// ClusteringResponse шаги кластеризации и порядок инструментов в дендрограмме
+type ClusteringResponse struct {
+	Merges []correlation_analysis.Merge `json:"Merges"`
+	Order  []string                     `json:"Order"`
+}
+
+func NewGetCorrelationsResponse(result correlation_analysis.CrossAsset) GetCorrelationsResponse {
+	rolling := make([]RollingResponse, 0, len(result.Rolling))
+	for _, pair := range result.Rolling {
+		rolling = append(rolling, RollingResponse{
+			A:      pair.A,
+			B:      pair.B,
+			Times:  pair.Times,
+			Values: pair.Values,
+		})
+	}
+
+	return GetCorrelationsResponse{
+		Method:        result.Method,
+		InstrumentIds: result.IDs,
+		Alignment: AlignmentResponse{
+			Missing: result.Missing,
+			Bars:    result.Bars,
+			Dropped: result.Dropped,
+			Filled:  result.Filled,
+			From:    result.From,
+			To:      result.To,
+		},
+		Matrix:  result.Matrix,
+		Rolling: rolling,
+		Clustering: ClusteringResponse{
+			Merges: result.Clustering.Merges,
+			Order:  result.Clustering.Order,
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type GetAssetFundamentalsRequest struct {
+	Assets []string `json:"assets"`
+}
+
+type GetAssetFundamentalsResponse struct {
+	Fundamentals []AssetFundamental `json:"fundamentals"`
+}
+
+// AssetFundamental фундаментальные показатели актива (Instrument.AssetUid) на момент загрузки Time.
+// Показатель, которого нет в ответе Tinkoff, равен nil; ноль — настоящее значение, например дивидендов нет.
+type AssetFundamental struct {
+	AssetUid string    `json:"assetUid" gorm:"primaryKey;type:VARCHAR(255)"` // UID актива
+	Time     time.Time `json:"time" gorm:"primaryKey"`                       // Время загрузки показателей
+	Currency string    `json:"currency" gorm:"type:VARCHAR(50)"`             // Валюта
+
+	MarketCapitalization    *float64 `json:"marketCapitalization"`    // Рыночная капитализация
+	SharesOutstanding       *float64 `json:"sharesOutstanding"`       // Количество акций в обращении
+	EpsTtm                  *float64 `json:"epsTtm"`                  // Прибыль на акцию
+	NetIncomeTtm            *float64 `json:"netIncomeTtm"`            // Чистая прибыль
+	EbitdaTtm               *float64 `json:"ebitdaTtm"`               // EBITDA
+	TotalEnterpriseValueMrq *float64 `json:"totalEnterpriseValueMrq"` // Стоимость компании (EV)
+	TotalDebtMrq            *float64 `json:"totalDebtMrq"`            // Совокупный долг
+
+	PeRatioTtm            *float64 `json:"peRatioTtm"`            // P/E
+	PriceToBookTtm        *float64 `json:"priceToBookTtm"`        // P/B
+	EvToEbitdaMrq         *float64 `json:"evToEbitdaMrq"`         // EV/EBITDA
+	Roe                   *float64 `json:"roe"`                   // Рентабельность капитала, %
+	DividendYieldDailyTtm *float64 `json:"dividendYieldDailyTtm"` // Дивидендная доходность, %
+	TotalDebtToEquityMrq  *float64 `json:"totalDebtToEquityMrq"`  // Долг/капитал
+}
@@ -0,0 +1,176 @@
+package models
+
+import "time"
+
+// InstrumentStatus: "INSTRUMENT_STATUS_UNSPECIFIED" - Значение не определено.
+//
+// InstrumentStatus: "INSTRUMENT_STATUS_BASE" - Базовый список инструментов (по умолчанию).
+// Инструменты доступные для торговли через TINKOFF INVEST API. Cейчас списки бумаг,
+// доступных из api и других интерфейсах совпадают (за исключением внебиржевых бумаг)
+// , но в будущем возможны ситуации, когда списки инструментов будут отличаться.
+//
+// InstrumentStatus: "INSTRUMENT_STATUS_ALL" - Список всех инструментов.
+type InstrumentsRequest struct {
+	InstrumentStatus string `json:"instrumentStatus"`
+}
+
+// InstrumentsResponse ответ InstrumentsService/Shares, Bonds, Etfs, Currencies и Futures
+type InstrumentsResponse struct {
+	Instruments []TinkoffInstrument `json:"instruments"`
+}
+
+// TinkoffInstrument объединение полей Share, Bond, Etf, Currency и Future из Tinkoff API.
+// Поля, которых нет у типа инструмента, остаются нулевыми.
+type TinkoffInstrument struct {
+	Figi                  string     `json:"figi"`
+	Ticker                string     `json:"ticker"`
+	ClassCode             string     `json:"classCode"`
+	Isin                  string     `json:"isin"`
+	Lot                   int        `json:"lot"`
+	Currency              string     `json:"currency"`
+	ShortEnabledFlag      bool       `json:"shortEnabledFlag"`
+	Name                  string     `json:"name"`
+	Exchange              string     `json:"exchange"`
+	CountryOfRisk         string     `json:"countryOfRisk"`
+	CountryOfRiskName     string     `json:"countryOfRiskName"`
+	Sector                string     `json:"sector"`
+	TradingStatus         string     `json:"tradingStatus"`
+	BuyAvailableFlag      bool       `json:"buyAvailableFlag"`
+	SellAvailableFlag     bool       `json:"sellAvailableFlag"`
+	MinPriceIncrement     Quotation  `json:"minPriceIncrement"`
+	ApiTradeAvailableFlag bool       `json:"apiTradeAvailableFlag"`
+	Uid                   string     `json:"uid"`
+	PositionUid           string     `json:"positionUid"`
+	AssetUid              string     `json:"assetUid"`
+	ForIisFlag            bool       `json:"forIisFlag"`
+	ForQualInvestorFlag   bool       `json:"forQualInvestorFlag"`
+	First1minCandleDate   time.Time  `json:"first1minCandleDate"`
+	First1dayCandleDate   time.Time  `json:"first1dayCandleDate"`
+	InstrumentType        string     `json:"instrumentType"`
+	Nominal               MoneyValue `json:"nominal"`
+
+	// Share
+	ShareType     string `json:"shareType"`
+	IssueSize     string `json:"issueSize"`
+	IssueSizePlan string `json:"issueSizePlan"`
+	DivYieldFlag  bool   `json:"divYieldFlag"`
+
+	// Bond
+	CouponQuantityPerYear int        `json:"couponQuantityPerYear"`
+	MaturityDate          time.Time  `json:"maturityDate"`
+	InitialNominal        MoneyValue `json:"initialNominal"`
+	AciValue              MoneyValue `json:"aciValue"`
+	PlacementDate         time.Time  `json:"placementDate"`
+	FloatingCouponFlag    bool       `json:"floatingCouponFlag"`
+	PerpetualFlag         bool       `json:"perpetualFlag"`
+	AmortizationFlag      bool       `json:"amortizationFlag"`
+
+	// Etf
+	FixedCommission Quotation `json:"fixedCommission"`
+	FocusType       string    `json:"focusType"`
+	ReleasedDate    time.Time `json:"releasedDate"`
+	NumShares       Quotation `json:"numShares"`
+	RebalancingFreq string    `json:"rebalancingFreq"`
+
+	// Currency
+	IsoCurrencyName string `json:"isoCurrencyName"`
+
+	// Future
+	FuturesType           string    `json:"futuresType"`
+	AssetType             string    `json:"assetType"`
+	BasicAsset            string    `json:"basicAsset"`
+	BasicAssetSize        Quotation `json:"basicAssetSize"`
+	BasicAssetPositionUid string    `json:"basicAssetPositionUid"`
+	FirstTradeDate        time.Time `json:"firstTradeDate"`
+	LastTradeDate         time.Time `json:"lastTradeDate"`
+	ExpirationDate        time.Time `json:"expirationDate"`
+}
+
+// ToBaseInstrument строка общей таблицы каталога без деталей типа
+func (t TinkoffInstrument) ToBaseInstrument(instrumentType string) Instrument {
+	return Instrument{
+		Uid:                   t.Uid,
+		Figi:                  t.Figi,
+		Ticker:                t.Ticker,
+		ClassCode:             t.ClassCode,
+		Isin:                  t.Isin,
+		Name:                  t.Name,
+		InstrumentType:        instrumentType,
+		Lot:                   t.Lot,
+		Currency:              t.Currency,
+		Exchange:              t.Exchange,
+		CountryOfRisk:         t.CountryOfRisk,
+		Sector:                t.Sector,
+		TradingStatus:         t.TradingStatus,
+		MinPriceIncrement:     t.MinPriceIncrement,
+		ApiTradeAvailableFlag: t.ApiTradeAvailableFlag,
+		BuyAvailableFlag:      t.BuyAvailableFlag,
+		SellAvailableFlag:     t.SellAvailableFlag,
+		ShortEnabledFlag:      t.ShortEnabledFlag,
+		ForIisFlag:            t.ForIisFlag,
+		ForQualInvestorFlag:   t.ForQualInvestorFlag,
+		AssetUid:              t.AssetUid,
+		PositionUid:           t.PositionUid,
+		First1minCandleDate:   t.First1minCandleDate,
+		First1dayCandleDate:   t.First1dayCandleDate,
+	}
+}
+
+// ToInstrument строка каталога с деталями типа instrumentType
+func (t TinkoffInstrument) ToInstrument(instrumentType string) Instrument {
+	instrument := t.ToBaseInstrument(instrumentType)
+
+	switch instrumentType {
+	case InstrumentTypeShare:
+		instrument.ShareDetails = &ShareDetails{
+			Uid:           t.Uid,
+			ShareType:     t.ShareType,
+			IssueSize:     t.IssueSize,
+			IssueSizePlan: t.IssueSizePlan,
+			Nominal:       t.Nominal,
+			DivYieldFlag:  t.DivYieldFlag,
+		}
+	case InstrumentTypeBond:
+		instrument.BondDetails = &BondDetails{
+			Uid:                   t.Uid,
+			CouponQuantityPerYear: t.CouponQuantityPerYear,
+			MaturityDate:          t.MaturityDate,
+			Nominal:               t.Nominal,
+			InitialNominal:        t.InitialNominal,
+			AciValue:              t.AciValue,
+			PlacementDate:         t.PlacementDate,
+			FloatingCouponFlag:    t.FloatingCouponFlag,
+			PerpetualFlag:         t.PerpetualFlag,
+			AmortizationFlag:      t.AmortizationFlag,
+		}
+	case InstrumentTypeEtf:
+		instrument.EtfDetails = &EtfDetails{
+			Uid:             t.Uid,
+			FixedCommission: t.FixedCommission,
+			FocusType:       t.FocusType,
+			ReleasedDate:    t.ReleasedDate,
+			NumShares:       t.NumShares,
+			RebalancingFreq: t.RebalancingFreq,
+		}
+	case InstrumentTypeCurrency:
+		instrument.CurrencyDetails = &CurrencyDetails{
+			Uid:             t.Uid,
+			Nominal:         t.Nominal,
+			IsoCurrencyName: t.IsoCurrencyName,
+		}
+	case InstrumentTypeFuture:
+		instrument.FutureDetails = &FutureDetails{
+			Uid:                   t.Uid,
+			FuturesType:           t.FuturesType,
+			AssetType:             t.AssetType,
+			BasicAsset:            t.BasicAsset,
+			BasicAssetSize:        t.BasicAssetSize,
+			BasicAssetPositionUid: t.BasicAssetPositionUid,
+			FirstTradeDate:        t.FirstTradeDate,
+			LastTradeDate:         t.LastTradeDate,
+			ExpirationDate:        t.ExpirationDate,
+		}
+	}
+
+	return instrument
+}
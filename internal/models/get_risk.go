@@ -0,0 +1,23 @@
+package models
+
+type GetRiskRequest struct {
+	GetCandlesRequest
+	// Confidence: уровень доверия для VaR/ES, например 0.95 или 0.99
+	Confidence float64 `json:"confidence" query:"confidence"`
+	// RiskFreeRate: годовая безрисковая ставка, например 0.16
+	RiskFreeRate float64 `json:"riskFreeRate" query:"riskFreeRate"`
+	// PeriodsPerYear: число свечей в году для аннуализации. Если не задано, выводится из Interval.
+	PeriodsPerYear float64 `json:"periodsPerYear" query:"periodsPerYear"`
+}
+
+func (r GetRiskRequest) Validate() error {
+	var v ValidationError
+	r.validate(&v, false)
+	if r.Confidence < 0 || r.Confidence >= 1 {
+		v.Add("confidence", "must be in (0, 1)")
+	}
+	if r.PeriodsPerYear < 0 {
+		v.Add("periodsPerYear", "must not be negative")
+	}
+	return v.Err()
+}
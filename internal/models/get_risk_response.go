@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"mamonolitmvp/internal/math/coefficients_calculation"
+)
+
+// GetRiskResponse ответ /api/v1/risk. Имена полей зафиксированы в v1 API
+type GetRiskResponse struct {
+	Ticker         string           `json:"ticker"`
+	Total          int              `json:"Total"`
+	Confidence     float64          `json:"Confidence"`
+	RiskFreeRate   float64          `json:"RiskFreeRate"`
+	PeriodsPerYear float64          `json:"PeriodsPerYear"`
+	Returns        ReturnsResponse  `json:"Returns"`
+	VaR            VaRResponse      `json:"VaR"`
+	ES             ESResponse       `json:"ES"`
+	Drawdown       DrawdownResponse `json:"Drawdown"`
+	Ratios         RatiosResponse   `json:"Ratios"`
+}
+
+// ReturnsResponse статистики лог-доходностей; Volatility аннуализирована
+type ReturnsResponse struct {
+	Mean       float64 `json:"Mean"`
+	Volatility float64 `json:"Volatility"`
+	Skewness   float64 `json:"Skewness"`
+	Kurtosis   float64 `json:"Kurtosis"`
+}
+
+// VaRResponse Value-at-Risk как положительный убыток за период
+type VaRResponse struct {
+	Historical    float64 `json:"Historical"`
+	Parametric    float64 `json:"Parametric"`
+	CornishFisher float64 `json:"CornishFisher"`
+}
+
+// ESResponse Expected Shortfall как положительный убыток за период
+type ESResponse struct {
+	Historical float64 `json:"Historical"`
+	Parametric float64 `json:"Parametric"`
+}
+
+type DrawdownResponse struct {
+	MaxDrawdown float64   `json:"MaxDrawdown"`
+	PeakTime    time.Time `json:"PeakTime"`
+	TroughTime  time.Time `json:"TroughTime"`
+}
+
+// RatiosResponse годовая доходность и коэффициенты доходности к риску
+type RatiosResponse struct {
+	AnnualReturn float64 `json:"AnnualReturn"`
+	Sharpe       float64 `json:"Sharpe"`
+	Sortino      float64 `json:"Sortino"`
+	Calmar       float64 `json:"Calmar"`
+}
+
+func NewGetRiskResponse(ticker string, risk coefficients_calculation.Risk) GetRiskResponse {
+	return GetRiskResponse{
+		Ticker:         ticker,
+		Total:          risk.Total,
+		Confidence:     risk.Confidence,
+		RiskFreeRate:   risk.RiskFreeRate,
+		PeriodsPerYear: risk.PeriodsPerYear,
+		Returns: ReturnsResponse{
+			Mean:       risk.MeanReturn,
+			Volatility: risk.Volatility,
+			Skewness:   risk.Skewness,
+			Kurtosis:   risk.Kurtosis,
+		},
+		VaR: VaRResponse{
+			Historical:    risk.HistoricalVaR,
+			Parametric:    risk.ParametricVaR,
+			CornishFisher: risk.CornishFisherVaR,
+		},
+		ES: ESResponse{
+			Historical: risk.HistoricalES,
+			Parametric: risk.ParametricES,
+		},
+		Drawdown: DrawdownResponse{
+			MaxDrawdown: risk.MaxDrawdown,
+			PeakTime:    risk.PeakTime,
+			TroughTime:  risk.TroughTime,
+		},
+		Ratios: RatiosResponse{
+			AnnualReturn: risk.AnnualReturn,
+			Sharpe:       risk.Sharpe,
+			Sortino:      risk.Sortino,
+			Calmar:       risk.Calmar,
+		},
+	}
+}
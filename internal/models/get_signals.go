@@ -0,0 +1,30 @@
+package models
+
+import (
+	"fmt"
+	"mamonolitmvp/internal/math/price_analysis"
+)
+
+type GetSignalsRequest struct {
+	GetCandlesRequest
+	// Indicators: дополнительные индикаторы; в запросе повторяющийся параметр indicators=macd:fast=12,slow=26,
+	// в теле — [{"name": "macd", "params": {"fast": 12}}]
+	Indicators []price_analysis.IndicatorParams `json:"indicators" query:"indicators"`
+	// Regime: пороги классификации режима рынка по окнам, незаданные берутся по умолчанию;
+	// в запросе — параметры trendingHurst, turbulentWidth и т.д.
+	Regime price_analysis.RegimeThresholds `json:"regime"`
+}
+
+func (r GetSignalsRequest) Validate() error {
+	var v ValidationError
+	r.validate(&v, false)
+	for i, indicator := range r.Indicators {
+		if indicator.Name == "" {
+			v.Add(fmt.Sprintf("indicators[%d].name", i), "is required")
+		}
+	}
+	if err := r.Regime.WithDefaults().Validate(); err != nil {
+		v.Add("regime", "%s", err)
+	}
+	return v.Err()
+}
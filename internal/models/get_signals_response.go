@@ -0,0 +1,119 @@
+package models
+
+import "mamonolitmvp/internal/math/price_analysis"
+
+// GetSignalsResponse ответ /api/v1/sig/getSignals. Имена полей зафиксированы в v1 API
+type GetSignalsResponse struct {
+	TotalPrices int                              `json:"TotalPrices"`
+	ShortSma    []float64                        `json:"ShortSma"`
+	LongSma     []float64                        `json:"LongSma"`
+	TrendFactor float64                          `json:"TrendFactor"`
+	Ticker      string                           `json:"ticker"`
+	Hurst       float64                          `json:"Hurst"`
+	RSI         RSIResponse                      `json:"RSI"`
+	Indicators  []price_analysis.IndicatorResult `json:"Indicators"`
+	MDFA        MDFAResponse                     `json:"MDFA"`
+	MFSpectrum  MFSpectrumResponse               `json:"MFSpectrum"`
+	FDIAnalysis FDIResponse                      `json:"FDIAnalysis"`
+	NormFdi     FDIResponse                      `json:"NormFdi"`
+	Window      WindowResponse                   `json:"Window"`
+	Regime      RegimeResponse                   `json:"Regime"`
+}
+
+type RSIResponse struct {
+	Values []float64 `json:"Values"`
+	Last   float64   `json:"Last"`
+	Trend  string    `json:"Trend"`
+}
+
+type MDFAResponse struct {
+	LogFq map[string][]float64 `json:"LogFq"`
+	Hq    map[string]float64   `json:"Hq"`
+	LogS  map[string]float64   `json:"LogS"`
+}
+
+type MFSpectrumResponse struct {
+	Qsorted []float64 `json:"Qsorted"`
+	Tau     []float64 `json:"Tau"`
+	Alpha   []float64 `json:"Alpha"`
+	FAlpha  []float64 `json:"FAlpha"`
+}
+
+type FDIResponse struct {
+	Width     float64 `json:"Width"`
+	Asym      float64 `json:"Asym"`
+	Curvature float64 `json:"Curvature"`
+	FDI       float64 `json:"FDI"`
+}
+
+// WindowResponse ряды скользящего анализа, по одному значению на окно
+type WindowResponse struct {
+	FdiWind   []price_analysis.Fdi          `json:"FdiWind"`
+	HurstWind []float64                     `json:"HurstWind"`
+	NormFdi   []price_analysis.NormalizeFdi `json:"NormFdi"`
+}
+
+// RegimeResponse режим рынка по окнам скользящего анализа и его смены
+type RegimeResponse struct {
+	Current     price_analysis.RegimeWindow       `json:"Current"`
+	Transitions []price_analysis.RegimeTransition `json:"Transitions"`
+	Windows     []price_analysis.RegimeWindow     `json:"Windows"`
+	Thresholds  price_analysis.RegimeThresholds   `json:"Thresholds"`
+}
+
+func NewGetSignalsResponse(ticker string, signal price_analysis.Signal, fdiWind []price_analysis.Fdi,
+	normFdiWind []price_analysis.NormalizeFdi, hurstWind []float64, regimes price_analysis.RegimeAnalysis) GetSignalsResponse {
+	var lastRSI float64
+	if len(signal.RSI) > 0 {
+		lastRSI = signal.RSI[len(signal.RSI)-1]
+	}
+
+	return GetSignalsResponse{
+		TotalPrices: signal.Total,
+		ShortSma:    signal.ShortSMA,
+		LongSma:     signal.LongSMA,
+		TrendFactor: signal.TrendFactor,
+		Ticker:      ticker,
+		Hurst:       signal.Hurst,
+		RSI: RSIResponse{
+			Values: signal.RSI,
+			Last:   lastRSI,
+			Trend:  signal.RSITrend,
+		},
+		Indicators: signal.Indicators,
+		MDFA: MDFAResponse{
+			LogFq: signal.LogFq,
+			Hq:    signal.Hq,
+			LogS:  signal.LogS,
+		},
+		MFSpectrum: MFSpectrumResponse{
+			Qsorted: signal.Qsorted,
+			Tau:     signal.Tau,
+			Alpha:   signal.Alpha,
+			FAlpha:  signal.FAlpha,
+		},
+		FDIAnalysis: FDIResponse{
+			Width:     signal.Width,
+			Asym:      signal.Asym,
+			Curvature: signal.Curvature,
+			FDI:       signal.Fdi.Fdi,
+		},
+		NormFdi: FDIResponse{
+			Width:     signal.NormWidth,
+			Asym:      signal.NormAsym,
+			Curvature: signal.NormCurvature,
+			FDI:       signal.NormFdi,
+		},
+		Window: WindowResponse{
+			FdiWind:   fdiWind,
+			HurstWind: hurstWind,
+			NormFdi:   normFdiWind,
+		},
+		Regime: RegimeResponse{
+			Current:     regimes.Current,
+			Transitions: regimes.Transitions,
+			Windows:     regimes.Windows,
+			Thresholds:  regimes.Thresholds,
+		},
+	}
+}
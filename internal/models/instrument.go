@@ -0,0 +1,123 @@
+package models
+
+import "time"
+
+// Типы инструментов каталога
+const (
+	InstrumentTypeShare    = "share"
+	InstrumentTypeBond     = "bond"
+	InstrumentTypeEtf      = "etf"
+	InstrumentTypeCurrency = "currency"
+	InstrumentTypeFuture   = "future"
+)
+
+// InstrumentMethods метод InstrumentsService, которым загружается список инструментов типа
+var InstrumentMethods = map[string]string{
+	InstrumentTypeShare:    "Shares",
+	InstrumentTypeBond:     "Bonds",
+	InstrumentTypeEtf:      "Etfs",
+	InstrumentTypeCurrency: "Currencies",
+	InstrumentTypeFuture:   "Futures",
+}
+
+// NormalizeInstrumentType приводит instrumentType из GetInstrumentBy к типу каталога
+func NormalizeInstrumentType(instrumentType string) string {
+	if instrumentType == "futures" {
+		return InstrumentTypeFuture
+	}
+	return instrumentType
+}
+
+// InstrumentTypes типы в порядке синхронизации
+var InstrumentTypes = []string{
+	InstrumentTypeShare,
+	InstrumentTypeBond,
+	InstrumentTypeEtf,
+	InstrumentTypeCurrency,
+	InstrumentTypeFuture,
+}
+
+// Instrument общая таблица каталога инструментов всех типов.
+// Поля, специфичные для типа, лежат в отдельных таблицах деталей с тем же uid.
+type Instrument struct {
+	Uid                   string    `json:"uid" gorm:"primaryKey;type:VARCHAR(255)"`                               // Первичный ключ
+	Figi                  string    `json:"figi" gorm:"index;type:VARCHAR(255)"`                                   // FIGI
+	Ticker                string    `json:"ticker" gorm:"index;type:VARCHAR(255)"`                                 // Тикер
+	ClassCode             string    `json:"classCode" gorm:"type:VARCHAR(255)"`                                    // Режим торгов
+	Isin                  string    `json:"isin" gorm:"index;type:VARCHAR(255)"`                                   // ISIN
+	Name                  string    `json:"name" gorm:"type:VARCHAR(255)"`                                         // Название
+	InstrumentType        string    `json:"instrumentType" gorm:"index;type:VARCHAR(50)"`                          // share, bond, etf, currency, future
+	Lot                   int       `json:"lot" gorm:"type:INT"`                                                   // Лотность
+	Currency              string    `json:"currency" gorm:"type:VARCHAR(50)"`                                      // Валюта расчетов
+	Exchange              string    `json:"exchange" gorm:"type:VARCHAR(100)"`                                     // Торговая площадка
+	CountryOfRisk         string    `json:"countryOfRisk" gorm:"type:VARCHAR(100)"`                                // Страна риска
+	Sector                string    `json:"sector" gorm:"type:VARCHAR(255)"`                                       // Сектор
+	TradingStatus         string    `json:"tradingStatus" gorm:"type:VARCHAR(100)"`                                // Торговый статус
+	MinPriceIncrement     Quotation `json:"minPriceIncrement" gorm:"embedded;embeddedPrefix:min_price_increment_"` // Шаг цены
+	ApiTradeAvailableFlag bool      `json:"apiTradeAvailableFlag" gorm:"type:BOOLEAN"`                             // API-доступность
+	BuyAvailableFlag      bool      `json:"buyAvailableFlag" gorm:"type:BOOLEAN"`                                  // Флаг доступности покупки
+	SellAvailableFlag     bool      `json:"sellAvailableFlag" gorm:"type:BOOLEAN"`                                 // Флаг доступности продажи
+	ShortEnabledFlag      bool      `json:"shortEnabledFlag" gorm:"type:BOOLEAN"`                                  // Доступность шорта
+	ForIisFlag            bool      `json:"forIisFlag" gorm:"type:BOOLEAN"`                                        // Доступность для ИИС
+	ForQualInvestorFlag   bool      `json:"forQualInvestorFlag" gorm:"type:BOOLEAN"`                               // Только для квалифицированных инвесторов
+	AssetUid              string    `json:"assetUid" gorm:"type:VARCHAR(255)"`                                     // UID актива
+	PositionUid           string    `json:"positionUid" gorm:"type:VARCHAR(255)"`                                  // UID позиции
+	First1minCandleDate   time.Time `json:"first1minCandleDate"`                                                   // Дата первой минутной свечи
+	First1dayCandleDate   time.Time `json:"first1dayCandleDate"`                                                   // Дата первой дневной свечи
+	UpdatedAt             time.Time `json:"updatedAt"`                                                             // Время последнего изменения
+
+	ShareDetails    *ShareDetails    `json:"shareDetails,omitempty" gorm:"foreignKey:Uid;references:Uid;constraint:OnDelete:CASCADE"`
+	BondDetails     *BondDetails     `json:"bondDetails,omitempty" gorm:"foreignKey:Uid;references:Uid;constraint:OnDelete:CASCADE"`
+	EtfDetails      *EtfDetails      `json:"etfDetails,omitempty" gorm:"foreignKey:Uid;references:Uid;constraint:OnDelete:CASCADE"`
+	CurrencyDetails *CurrencyDetails `json:"currencyDetails,omitempty" gorm:"foreignKey:Uid;references:Uid;constraint:OnDelete:CASCADE"`
+	FutureDetails   *FutureDetails   `json:"futureDetails,omitempty" gorm:"foreignKey:Uid;references:Uid;constraint:OnDelete:CASCADE"`
+}
+
+type ShareDetails struct {
+	Uid           string     `json:"-" gorm:"primaryKey;type:VARCHAR(255)"`
+	ShareType     string     `json:"shareType" gorm:"type:VARCHAR(100)"`              // Тип акции
+	IssueSize     string     `json:"issueSize" gorm:"type:VARCHAR(100)"`              // Размер выпуска
+	IssueSizePlan string     `json:"issueSizePlan" gorm:"type:VARCHAR(100)"`          // Плановый размер выпуска
+	Nominal       MoneyValue `json:"nominal" gorm:"embedded;embeddedPrefix:nominal_"` // Номинал
+	DivYieldFlag  bool       `json:"divYieldFlag" gorm:"type:BOOLEAN"`                // Флаг дивидендной доходности
+}
+
+type BondDetails struct {
+	Uid                   string     `json:"-" gorm:"primaryKey;type:VARCHAR(255)"`
+	CouponQuantityPerYear int        `json:"couponQuantityPerYear" gorm:"type:INT"`                          // Купонов в год
+	MaturityDate          time.Time  `json:"maturityDate" gorm:"index"`                                      // Дата погашения
+	Nominal               MoneyValue `json:"nominal" gorm:"embedded;embeddedPrefix:nominal_"`                // Текущий номинал
+	InitialNominal        MoneyValue `json:"initialNominal" gorm:"embedded;embeddedPrefix:initial_nominal_"` // Номинал при размещении
+	AciValue              MoneyValue `json:"aciValue" gorm:"embedded;embeddedPrefix:aci_value_"`             // НКД
+	PlacementDate         time.Time  `json:"placementDate"`                                                  // Дата размещения
+	FloatingCouponFlag    bool       `json:"floatingCouponFlag" gorm:"type:BOOLEAN"`                         // Плавающий купон
+	PerpetualFlag         bool       `json:"perpetualFlag" gorm:"type:BOOLEAN"`                              // Бессрочная облигация
+	AmortizationFlag      bool       `json:"amortizationFlag" gorm:"type:BOOLEAN"`                           // Амортизация долга
+}
+
+type EtfDetails struct {
+	Uid             string    `json:"-" gorm:"primaryKey;type:VARCHAR(255)"`
+	FixedCommission Quotation `json:"fixedCommission" gorm:"embedded;embeddedPrefix:fixed_commission_"` // Комиссия фонда
+	FocusType       string    `json:"focusType" gorm:"type:VARCHAR(100)"`                               // Тип активов фонда
+	ReleasedDate    time.Time `json:"releasedDate"`                                                     // Дата выпуска
+	NumShares       Quotation `json:"numShares" gorm:"embedded;embeddedPrefix:num_shares_"`             // Количество паев
+	RebalancingFreq string    `json:"rebalancingFreq" gorm:"type:VARCHAR(100)"`                         // Частота ребалансировки
+}
+
+type CurrencyDetails struct {
+	Uid             string     `json:"-" gorm:"primaryKey;type:VARCHAR(255)"`
+	Nominal         MoneyValue `json:"nominal" gorm:"embedded;embeddedPrefix:nominal_"` // Номинал
+	IsoCurrencyName string     `json:"isoCurrencyName" gorm:"type:VARCHAR(50)"`         // Код валюты ISO
+}
+
+type FutureDetails struct {
+	Uid                   string    `json:"-" gorm:"primaryKey;type:VARCHAR(255)"`
+	FuturesType           string    `json:"futuresType" gorm:"type:VARCHAR(100)"`                            // Поставочный или расчетный
+	AssetType             string    `json:"assetType" gorm:"type:VARCHAR(100)"`                              // Тип базового актива
+	BasicAsset            string    `json:"basicAsset" gorm:"type:VARCHAR(255)"`                             // Базовый актив
+	BasicAssetSize        Quotation `json:"basicAssetSize" gorm:"embedded;embeddedPrefix:basic_asset_size_"` // Размер базового актива
+	BasicAssetPositionUid string    `json:"basicAssetPositionUid" gorm:"type:VARCHAR(255)"`                  // UID позиции базового актива
+	FirstTradeDate        time.Time `json:"firstTradeDate"`                                                  // Начало обращения
+	LastTradeDate         time.Time `json:"lastTradeDate"`                                                   // Окончание обращения
+	ExpirationDate        time.Time `json:"expirationDate" gorm:"index"`                                     // Дата экспирации
+}
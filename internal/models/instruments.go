@@ -0,0 +1,90 @@
+package models
+
+import "fmt"
+
+// ListInstrumentsRequest поиск по каталогу /api/v1/instruments. Q совпадает с тикером, ISIN или FIGI
+// без учета регистра либо входит в название; остальные фильтры — точные совпадения.
+type ListInstrumentsRequest struct {
+	Q      string `query:"q"`
+	Type   string `query:"type"`
+	Ticker string `query:"ticker"`
+	Isin   string `query:"isin"`
+	Figi   string `query:"figi"`
+	// Sector, Currency, Exchange: точное совпадение без учета регистра, например it, rub, MOEX
+	Sector   string `query:"sector"`
+	Currency string `query:"currency"`
+	Exchange string `query:"exchange"`
+	Pagination
+}
+
+func (r ListInstrumentsRequest) Validate() error {
+	var v ValidationError
+	if _, ok := InstrumentMethods[r.Type]; r.Type != "" && !ok {
+		v.Add("type", "must be one of share, bond, etf, currency, future")
+	}
+	r.Pagination.validate(&v)
+	return v.Err()
+}
+
+// IsEmpty true, если не задан ни один фильтр
+func (r ListInstrumentsRequest) IsEmpty() bool {
+	return r.Q == "" && r.Type == "" && r.Ticker == "" && r.Isin == "" && r.Figi == "" &&
+		r.Sector == "" && r.Currency == "" && r.Exchange == ""
+}
+
+// SyncInstrumentsRequest типы для синхронизации каталога, по умолчанию все
+type SyncInstrumentsRequest struct {
+	Types []string `json:"types" query:"type"`
+}
+
+func (r SyncInstrumentsRequest) Validate() error {
+	var v ValidationError
+	for i, t := range r.Types {
+		if _, ok := InstrumentMethods[t]; !ok {
+			v.Add(fmt.Sprintf("types[%d]", i), "must be one of share, bond, etf, currency, future")
+		}
+	}
+	return v.Err()
+}
+
+// SyncInstrumentsResponse итог синхронизации по типам инструментов
+type SyncInstrumentsResponse struct {
+	Synced map[string]UpsertStats `json:"synced"`
+}
+
+type InstrumentsPage struct {
+	Instruments []Instrument `json:"instruments"`
+	Page        PageInfo     `json:"page"`
+}
+
+// GetInstrumentCandlesRequest свечи /api/v1/instruments/{uid}/candles; InstrumentId берется из пути
+type GetInstrumentCandlesRequest struct {
+	GetCandlesRequest
+	Pagination
+}
+
+func (r GetInstrumentCandlesRequest) Validate() error {
+	var v ValidationError
+	r.GetCandlesRequest.validate(&v, false)
+	r.Pagination.validate(&v)
+	return v.Err()
+}
+
+type CandlesPage struct {
+	InstrumentId string   `json:"instrumentId"`
+	Interval     string   `json:"interval"`
+	Candles      []Candle `json:"candles"`
+	Page         PageInfo `json:"page"`
+}
+
+// GetInstrumentByRequest запрос InstrumentsService/GetInstrumentBy
+type GetInstrumentByRequest struct {
+	// IdType: INSTRUMENT_ID_TYPE_FIGI, INSTRUMENT_ID_TYPE_TICKER или INSTRUMENT_ID_TYPE_UID
+	IdType    string `json:"idType"`
+	ClassCode string `json:"classCode,omitempty"`
+	Id        string `json:"id"`
+}
+
+type GetInstrumentByResponse struct {
+	Instrument TinkoffInstrument `json:"instrument"`
+}
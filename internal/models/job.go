@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// JobState состояние задачи планировщика; сохраняется, чтобы после перезапуска не потерять
+// паузу и время последнего запуска
+type JobState struct {
+	Name           string    `json:"name" gorm:"primaryKey;size:100"`
+	Schedule       string    `json:"schedule" gorm:"-"`
+	Paused         bool      `json:"paused"`
+	Running        bool      `json:"running" gorm:"-"`
+	LastStatus     string    `json:"lastStatus" gorm:"size:20"`
+	LastError      string    `json:"lastError,omitempty"`
+	LastStartedAt  time.Time `json:"lastStartedAt"`
+	LastFinishedAt time.Time `json:"lastFinishedAt"`
+	LastDurationMs int64     `json:"lastDurationMs"`
+	NextRunAt      time.Time `json:"nextRunAt"`
+	Runs           int       `json:"runs"`
+	Failures       int       `json:"failures"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+type JobsResponse struct {
+	Jobs []JobState `json:"jobs"`
+}
+
+type JobResponse struct {
+	Job JobState `json:"job"`
+}
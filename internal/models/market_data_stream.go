@@ -0,0 +1,276 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	SubscriptionActionSubscribe = "SUBSCRIPTION_ACTION_SUBSCRIBE"
+	SubscriptionStatusSuccess   = "SUBSCRIPTION_STATUS_SUCCESS"
+)
+
+// SubscriptionIntervals интервал подписки на свечи MarketDataStream для интервала GetCandles
+var SubscriptionIntervals = map[string]string{
+	"CANDLE_INTERVAL_1_MIN":  "SUBSCRIPTION_INTERVAL_ONE_MINUTE",
+	"CANDLE_INTERVAL_2_MIN":  "SUBSCRIPTION_INTERVAL_2_MIN",
+	"CANDLE_INTERVAL_3_MIN":  "SUBSCRIPTION_INTERVAL_3_MIN",
+	"CANDLE_INTERVAL_5_MIN":  "SUBSCRIPTION_INTERVAL_FIVE_MINUTES",
+	"CANDLE_INTERVAL_10_MIN": "SUBSCRIPTION_INTERVAL_10_MIN",
+	"CANDLE_INTERVAL_15_MIN": "SUBSCRIPTION_INTERVAL_FIFTEEN_MINUTES",
+	"CANDLE_INTERVAL_30_MIN": "SUBSCRIPTION_INTERVAL_30_MIN",
+	"CANDLE_INTERVAL_HOUR":   "SUBSCRIPTION_INTERVAL_ONE_HOUR",
+	"CANDLE_INTERVAL_2_HOUR": "SUBSCRIPTION_INTERVAL_2_HOUR",
+	"CANDLE_INTERVAL_4_HOUR": "SUBSCRIPTION_INTERVAL_4_HOUR",
+	"CANDLE_INTERVAL_DAY":    "SUBSCRIPTION_INTERVAL_ONE_DAY",
+	"CANDLE_INTERVAL_WEEK":   "SUBSCRIPTION_INTERVAL_WEEK",
+	"CANDLE_INTERVAL_MONTH":  "SUBSCRIPTION_INTERVAL_MONTH",
+}
+
+// CandleInterval интервал GetCandles для интервала подписки или пустая строка
+func CandleInterval(subscriptionInterval string) string {
+	for candleInterval, interval := range SubscriptionIntervals {
+		if interval == subscriptionInterval {
+			return candleInterval
+		}
+	}
+	return ""
+}
+
+// OrderBookDepths допустимая глубина стакана в подписке
+var OrderBookDepths = []int{1, 10, 20, 30, 40, 50}
+
+// MarketDataServerSideStreamRequest запрос MarketDataStreamService/MarketDataServerSideStream:
+// подписки задаются один раз при открытии потока, для изменения поток открывается заново
+type MarketDataServerSideStreamRequest struct {
+	SubscribeCandlesRequest   *SubscribeCandlesRequest   `json:"subscribeCandlesRequest,omitempty"`
+	SubscribeOrderBookRequest *SubscribeOrderBookRequest `json:"subscribeOrderBookRequest,omitempty"`
+	SubscribeLastPriceRequest *SubscribeLastPriceRequest `json:"subscribeLastPriceRequest,omitempty"`
+	PingSettings              *PingDelaySettings         `json:"pingSettings,omitempty"`
+}
+
+type SubscribeCandlesRequest struct {
+	SubscriptionAction string             `json:"subscriptionAction"`
+	Instruments        []CandleInstrument `json:"instruments"`
+	// WaitingClose: свеча приходит один раз после закрытия интервала
+	WaitingClose bool `json:"waitingClose"`
+}
+
+type CandleInstrument struct {
+	InstrumentId string `json:"instrumentId"`
+	Interval     string `json:"interval"`
+}
+
+type SubscribeOrderBookRequest struct {
+	SubscriptionAction string                `json:"subscriptionAction"`
+	Instruments        []OrderBookInstrument `json:"instruments"`
+}
+
+type OrderBookInstrument struct {
+	InstrumentId string `json:"instrumentId"`
+	Depth        int    `json:"depth"`
+}
+
+type SubscribeLastPriceRequest struct {
+	SubscriptionAction string                `json:"subscriptionAction"`
+	Instruments        []LastPriceInstrument `json:"instruments"`
+}
+
+type LastPriceInstrument struct {
+	InstrumentId string `json:"instrumentId"`
+}
+
+type PingDelaySettings struct {
+	PingDelayMs int `json:"pingDelayMs"`
+}
+
+// MarketDataResponse сообщение потока; заполнено одно из полей
+type MarketDataResponse struct {
+	SubscribeCandlesResponse   *SubscriptionResponse `json:"subscribeCandlesResponse,omitempty"`
+	SubscribeOrderBookResponse *SubscriptionResponse `json:"subscribeOrderBookResponse,omitempty"`
+	SubscribeLastPriceResponse *SubscriptionResponse `json:"subscribeLastPriceResponse,omitempty"`
+	Candle                     *StreamCandle         `json:"candle,omitempty"`
+	Orderbook                  *OrderBook            `json:"orderbook,omitempty"`
+	LastPrice                  *LastPrice            `json:"lastPrice,omitempty"`
+	Ping                       *Ping                 `json:"ping,omitempty"`
+}
+
+// SubscriptionResponse результат подписки; список статусов в поле, соответствующем типу подписки
+type SubscriptionResponse struct {
+	TrackingId             string               `json:"trackingId"`
+	CandlesSubscriptions   []SubscriptionStatus `json:"candlesSubscriptions,omitempty"`
+	OrderBookSubscriptions []SubscriptionStatus `json:"orderBookSubscriptions,omitempty"`
+	LastPriceSubscriptions []SubscriptionStatus `json:"lastPriceSubscriptions,omitempty"`
+}
+
+// Statuses статусы подписок независимо от типа
+func (r SubscriptionResponse) Statuses() []SubscriptionStatus {
+	statuses := append([]SubscriptionStatus{}, r.CandlesSubscriptions...)
+	statuses = append(statuses, r.OrderBookSubscriptions...)
+	return append(statuses, r.LastPriceSubscriptions...)
+}
+
+type SubscriptionStatus struct {
+	InstrumentUid      string `json:"instrumentUid"`
+	Interval           string `json:"interval,omitempty"`
+	Depth              int    `json:"depth,omitempty"`
+	SubscriptionStatus string `json:"subscriptionStatus"`
+}
+
+// StreamCandle свеча из потока
+type StreamCandle struct {
+	Figi          string    `json:"figi"`
+	InstrumentUid string    `json:"instrumentUid"`
+	Interval      string    `json:"interval"`
+	Open          Quotation `json:"open"`
+	High          Quotation `json:"high"`
+	Low           Quotation `json:"low"`
+	Close         Quotation `json:"close"`
+	Volume        string    `json:"volume"`
+	Time          time.Time `json:"time"`
+	LastTradeTs   time.Time `json:"lastTradeTs"`
+}
+
+// ToCandle переводит свечу потока в формат хранилища
+func (c StreamCandle) ToCandle() (Candle, error) {
+	interval := CandleInterval(c.Interval)
+	if interval == "" {
+		return Candle{}, fmt.Errorf("unknown subscription interval %q", c.Interval)
+	}
+
+	candle := Candle{
+		InstrumentId: c.InstrumentUid,
+		Interval:     interval,
+		Time:         c.Time,
+		Open:         c.Open.Float64(),
+		High:         c.High.Float64(),
+		Low:          c.Low.Float64(),
+		Close:        c.Close.Float64(),
+	}
+
+	if c.Volume != "" {
+		volume, err := strconv.ParseInt(c.Volume, 10, 64)
+		if err != nil {
+			return Candle{}, fmt.Errorf("invalid volume %q: %w", c.Volume, err)
+		}
+		candle.Volume = volume
+	}
+
+	return candle, nil
+}
+
+type OrderBook struct {
+	Figi          string    `json:"figi"`
+	InstrumentUid string    `json:"instrumentUid"`
+	Depth         int       `json:"depth"`
+	IsConsistent  bool      `json:"isConsistent"`
+	Bids          []Order   `json:"bids"`
+	Asks          []Order   `json:"asks"`
+	Time          time.Time `json:"time"`
+	LimitUp       Quotation `json:"limitUp"`
+	LimitDown     Quotation `json:"limitDown"`
+}
+
+// Order уровень стакана: цена и количество в лотах
+type Order struct {
+	Price    Quotation `json:"price"`
+	Quantity string    `json:"quantity"`
+}
+
+type LastPrice struct {
+	Figi          string    `json:"figi"`
+	InstrumentUid string    `json:"instrumentUid"`
+	Price         Quotation `json:"price"`
+	Time          time.Time `json:"time"`
+}
+
+type Ping struct {
+	Time time.Time `json:"time"`
+}
+
+// StreamSubscriptions набор подписок потока рыночных данных
+type StreamSubscriptions struct {
+	Candles    []CandleInstrument    `json:"candles"`
+	OrderBooks []OrderBookInstrument `json:"orderBooks"`
+	LastPrices []LastPriceInstrument `json:"lastPrices"`
+}
+
+// Validate interval подписки на свечи задается как интервал GetCandles, например CANDLE_INTERVAL_1_MIN
+func (s StreamSubscriptions) Validate() error {
+	var v ValidationError
+	for i, c := range s.Candles {
+		if c.InstrumentId == "" {
+			v.Add(fmt.Sprintf("candles[%d].instrumentId", i), "is required")
+		}
+		if _, ok := SubscriptionIntervals[c.Interval]; !ok {
+			v.Add(fmt.Sprintf("candles[%d].interval", i), "unknown candle interval: %q", c.Interval)
+		}
+	}
+	for i, o := range s.OrderBooks {
+		if o.InstrumentId == "" {
+			v.Add(fmt.Sprintf("orderBooks[%d].instrumentId", i), "is required")
+		}
+		if !slices.Contains(OrderBookDepths, o.Depth) {
+			v.Add(fmt.Sprintf("orderBooks[%d].depth", i), "must be one of %v", OrderBookDepths)
+		}
+	}
+	for i, l := range s.LastPrices {
+		if l.InstrumentId == "" {
+			v.Add(fmt.Sprintf("lastPrices[%d].instrumentId", i), "is required")
+		}
+	}
+	return v.Err()
+}
+
+func (s StreamSubscriptions) IsEmpty() bool {
+	return len(s.Candles) == 0 && len(s.OrderBooks) == 0 && len(s.LastPrices) == 0
+}
+
+// Request запрос на открытие потока с подписками набора
+func (s StreamSubscriptions) Request(pingDelay time.Duration) MarketDataServerSideStreamRequest {
+	req := MarketDataServerSideStreamRequest{
+		PingSettings: &PingDelaySettings{PingDelayMs: int(pingDelay.Milliseconds())},
+	}
+	if len(s.Candles) > 0 {
+		instruments := make([]CandleInstrument, 0, len(s.Candles))
+		for _, c := range s.Candles {
+			instruments = append(instruments, CandleInstrument{
+				InstrumentId: c.InstrumentId,
+				Interval:     SubscriptionIntervals[c.Interval],
+			})
+		}
+		req.SubscribeCandlesRequest = &SubscribeCandlesRequest{
+			SubscriptionAction: SubscriptionActionSubscribe,
+			Instruments:        instruments,
+			WaitingClose:       true,
+		}
+	}
+	if len(s.OrderBooks) > 0 {
+		req.SubscribeOrderBookRequest = &SubscribeOrderBookRequest{
+			SubscriptionAction: SubscriptionActionSubscribe,
+			Instruments:        s.OrderBooks,
+		}
+	}
+	if len(s.LastPrices) > 0 {
+		req.SubscribeLastPriceRequest = &SubscribeLastPriceRequest{
+			SubscriptionAction: SubscriptionActionSubscribe,
+			Instruments:        s.LastPrices,
+		}
+	}
+	return req
+}
+
+// StreamStatus состояние потока рыночных данных
+type StreamStatus struct {
+	Connected     bool                `json:"connected"`
+	Subscriptions StreamSubscriptions `json:"subscriptions"`
+	ConnectedAt   time.Time           `json:"connectedAt"`
+	LastMessageAt time.Time           `json:"lastMessageAt"`
+	Reconnects    int                 `json:"reconnects"`
+	LastError     string              `json:"lastError,omitempty"`
+	Candles       int                 `json:"candles"`
+	LastPrices    int                 `json:"lastPrices"`
+	OrderBooks    int                 `json:"orderBooks"`
+}
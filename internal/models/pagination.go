@@ -0,0 +1,40 @@
+package models
+
+const (
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+// Pagination limit/offset из query параметров; нулевой limit заменяется DefaultPageLimit
+type Pagination struct {
+	Limit  int `json:"limit" query:"limit"`
+	Offset int `json:"offset" query:"offset"`
+}
+
+// PageInfo положение страницы в общем списке
+type PageInfo struct {
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+func (p Pagination) validate(v *ValidationError) {
+	if p.Limit < 0 || p.Limit > MaxPageLimit {
+		v.Add("limit", "must be in [0, %d]", MaxPageLimit)
+	}
+	if p.Offset < 0 {
+		v.Add("offset", "must not be negative")
+	}
+}
+
+// WithDefaults подставляет limit по умолчанию
+func (p Pagination) WithDefaults() Pagination {
+	if p.Limit == 0 {
+		p.Limit = DefaultPageLimit
+	}
+	return p
+}
+
+func (p Pagination) Info(total int64) PageInfo {
+	return PageInfo{Total: total, Limit: p.Limit, Offset: p.Offset}
+}
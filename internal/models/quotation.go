@@ -0,0 +1,275 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const nanoFactor = 1_000_000_000
+
+// Quotation котировка в формате Tinkoff: units + nano * 10^-9.
+// units и nano всегда одного знака, |nano| < 10^9.
+type Quotation struct {
+	Units int64 `json:"units"`
+	Nano  int32 `json:"nano"`
+}
+
+// MoneyValue денежная сумма в формате Tinkoff
+type MoneyValue struct {
+	Currency string `json:"currency"`
+	Units    int64  `json:"units"`
+	Nano     int32  `json:"nano"`
+}
+
+func NewQuotation(units int64, nano int32) (Quotation, error) {
+	if nano <= -nanoFactor || nano >= nanoFactor {
+		return Quotation{}, fmt.Errorf("nano out of range: %d", nano)
+	}
+	if units > 0 && nano < 0 || units < 0 && nano > 0 {
+		return Quotation{}, fmt.Errorf("units and nano must have the same sign: %d, %d", units, nano)
+	}
+	return Quotation{Units: units, Nano: nano}, nil
+}
+
+// ParseQuotation разбирает десятичную строку вида "-123.005"; знак не больше одного, знаков после точки
+// не больше девяти
+func ParseQuotation(s string) (Quotation, error) {
+	raw := s
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" || len(fracPart) > 9 ||
+		strings.ContainsAny(intPart, "+-") || strings.ContainsAny(fracPart, "+-") {
+		return Quotation{}, fmt.Errorf("invalid quotation %q", raw)
+	}
+
+	var units int64
+	if intPart != "" {
+		var err error
+		// Знак разбирается вместе с целой частью: иначе не разобрался бы минимальный int64
+		units, err = strconv.ParseInt(sign+intPart, 10, 64)
+		if err != nil {
+			return Quotation{}, fmt.Errorf("invalid quotation %q", raw)
+		}
+	}
+
+	var nano int64
+	if fracPart != "" {
+		var err error
+		nano, err = strconv.ParseInt(fracPart+strings.Repeat("0", 9-len(fracPart)), 10, 64)
+		if err != nil {
+			return Quotation{}, fmt.Errorf("invalid quotation %q", raw)
+		}
+	}
+
+	if sign == "-" {
+		nano = -nano
+	}
+	return Quotation{Units: units, Nano: int32(nano)}, nil
+}
+
+// QuotationFromFloat ближайшая к f котировка с точностью 10^-9
+func QuotationFromFloat(f float64) (Quotation, error) {
+	return ParseQuotation(strconv.FormatFloat(f, 'f', 9, 64))
+}
+
+// String точное десятичное представление без лишних нулей
+func (q Quotation) String() string {
+	sign := ""
+	units, nano := q.Units, int64(q.Nano)
+	if units < 0 || nano < 0 {
+		sign = "-"
+	}
+
+	intPart := strconv.FormatUint(absUint(units), 10)
+	if nano == 0 {
+		return sign + intPart
+	}
+
+	frac := strings.TrimRight(fmt.Sprintf("%09d", absUint(nano)), "0")
+	return sign + intPart + "." + frac
+}
+
+// Float64 ближайшее к котировке число float64
+func (q Quotation) Float64() float64 {
+	f, _ := strconv.ParseFloat(q.String(), 64)
+	return f
+}
+
+func (q Quotation) IsZero() bool {
+	return q.Units == 0 && q.Nano == 0
+}
+
+// Neg противоположная котировка; для минимального int64 units результат не помещается в int64
+func (q Quotation) Neg() (Quotation, error) {
+	return fromNanos(new(big.Int).Neg(q.nanos()))
+}
+
+// Add сумма; ошибка, если units не помещается в int64
+func (q Quotation) Add(other Quotation) (Quotation, error) {
+	return fromNanos(new(big.Int).Add(q.nanos(), other.nanos()))
+}
+
+// Sub разность; ошибка, если units не помещается в int64
+func (q Quotation) Sub(other Quotation) (Quotation, error) {
+	return fromNanos(new(big.Int).Sub(q.nanos(), other.nanos()))
+}
+
+// MulInt умножение на целое, например цены на число бумаг в лоте; ошибка, если units не помещается в int64
+func (q Quotation) MulInt(n int64) (Quotation, error) {
+	return fromNanos(new(big.Int).Mul(q.nanos(), big.NewInt(n)))
+}
+
+// Mul произведение котировок с округлением до 10^-9 (половина — от нуля)
+func (q Quotation) Mul(other Quotation) (Quotation, error) {
+	product := new(big.Int).Mul(q.nanos(), other.nanos())
+	return fromNanos(roundDiv(product, big.NewInt(nanoFactor)))
+}
+
+// Div частное котировок с округлением до 10^-9 (половина — от нуля)
+func (q Quotation) Div(other Quotation) (Quotation, error) {
+	if other.IsZero() {
+		return Quotation{}, fmt.Errorf("division by zero")
+	}
+	numerator := new(big.Int).Mul(q.nanos(), big.NewInt(nanoFactor))
+	return fromNanos(roundDiv(numerator, other.nanos()))
+}
+
+// Cmp -1, 0 или 1, если q меньше, равна или больше other
+func (q Quotation) Cmp(other Quotation) int {
+	return q.nanos().Cmp(other.nanos())
+}
+
+func (q Quotation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Units string `json:"units"`
+		Nano  int32  `json:"nano"`
+	}{
+		Units: strconv.FormatInt(q.Units, 10),
+		Nano:  q.Nano,
+	})
+}
+
+// UnmarshalJSON принимает units как строку (формат Tinkoff) или как число
+func (q *Quotation) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Units json.RawMessage `json:"units"`
+		Nano  int32           `json:"nano"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	units, err := parseUnits(raw.Units)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := NewQuotation(units, raw.Nano)
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+func (m MoneyValue) Quotation() Quotation {
+	return Quotation{Units: m.Units, Nano: m.Nano}
+}
+
+func (m MoneyValue) String() string {
+	return strings.TrimSpace(m.Quotation().String() + " " + m.Currency)
+}
+
+func (m MoneyValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Currency string `json:"currency"`
+		Units    string `json:"units"`
+		Nano     int32  `json:"nano"`
+	}{
+		Currency: m.Currency,
+		Units:    strconv.FormatInt(m.Units, 10),
+		Nano:     m.Nano,
+	})
+}
+
+func (m *MoneyValue) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var q Quotation
+	if err := q.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	*m = MoneyValue{Currency: raw.Currency, Units: q.Units, Nano: q.Nano}
+	return nil
+}
+
+func parseUnits(raw json.RawMessage) (int64, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+
+	s := string(raw)
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return 0, err
+		}
+		if s == "" {
+			return 0, nil
+		}
+	}
+
+	units, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid units %s: %w", raw, err)
+	}
+	return units, nil
+}
+
+func (q Quotation) nanos() *big.Int {
+	n := new(big.Int).Mul(big.NewInt(q.Units), big.NewInt(nanoFactor))
+	return n.Add(n, big.NewInt(int64(q.Nano)))
+}
+
+// fromNanos котировка из числа нано; ошибка, если units не помещается в int64
+func fromNanos(n *big.Int) (Quotation, error) {
+	units, nano := new(big.Int).QuoRem(n, big.NewInt(nanoFactor), new(big.Int))
+	if !units.IsInt64() {
+		return Quotation{}, fmt.Errorf("quotation overflow: %s nano", n)
+	}
+	return Quotation{Units: units.Int64(), Nano: int32(nano.Int64())}, nil
+}
+
+// roundDiv a / b с округлением половины от нуля
+func roundDiv(a, b *big.Int) *big.Int {
+	quo, rem := new(big.Int).QuoRem(a, b, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(new(big.Int).Abs(b)) >= 0 {
+		if a.Sign()*b.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo
+}
+
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return uint64(-v)
+	}
+	return uint64(v)
+}
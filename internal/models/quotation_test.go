@@ -0,0 +1,247 @@
+package models
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseQuotation(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Quotation
+	}{
+		{"0", Quotation{}},
+		{"-0", Quotation{}},
+		{"123", Quotation{Units: 123}},
+		{"+123", Quotation{Units: 123}},
+		{"-123.005", Quotation{Units: -123, Nano: -5_000_000}},
+		{"0.000000001", Quotation{Nano: 1}},
+		{"-0.5", Quotation{Nano: -500_000_000}},
+		{".25", Quotation{Nano: 250_000_000}},
+		{"7.", Quotation{Units: 7}},
+		{"9223372036854775807.999999999", Quotation{Units: math.MaxInt64, Nano: 999_999_999}},
+		{"-9223372036854775808.999999999", Quotation{Units: math.MinInt64, Nano: -999_999_999}},
+	}
+	for _, tt := range tests {
+		got, err := ParseQuotation(tt.in)
+		if err != nil {
+			t.Errorf("ParseQuotation(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseQuotation(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseQuotationInvalid(t *testing.T) {
+	for _, in := range []string{
+		"", "-", "+", ".", "-.", "-+5", "+-5", "--5", "++5", "5.-1", "5.+1", "1.0000000001",
+		"1e3", " 1", "1 ", "0x10", "1_000", "1.2.3", "abc", "9223372036854775808", "-9223372036854775809",
+	} {
+		if q, err := ParseQuotation(in); err == nil {
+			t.Errorf("ParseQuotation(%q) = %+v, want error", in, q)
+		}
+	}
+}
+
+func TestQuotationString(t *testing.T) {
+	tests := []struct {
+		q    Quotation
+		want string
+	}{
+		{Quotation{}, "0"},
+		{Quotation{Units: 5}, "5"},
+		{Quotation{Units: -5, Nano: -100_000_000}, "-5.1"},
+		{Quotation{Nano: -1}, "-0.000000001"},
+		{Quotation{Units: math.MinInt64, Nano: -999_999_999}, "-9223372036854775808.999999999"},
+	}
+	for _, tt := range tests {
+		if got := tt.q.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.q, got, tt.want)
+		}
+	}
+}
+
+// randomQuotation котировка с согласованными знаками units и nano, в том числе у границ int64
+func randomQuotation(rng *rand.Rand) Quotation {
+	var units int64
+	switch rng.Intn(4) {
+	case 0:
+		units = rng.Int63n(1000)
+	case 1:
+		units = rng.Int63()
+	case 2:
+		units = math.MaxInt64 - rng.Int63n(10)
+	default:
+		units = 0
+	}
+	nano := int32(rng.Int63n(nanoFactor))
+	if rng.Intn(2) == 0 {
+		units, nano = -units, -nano
+		if units == -math.MaxInt64 && rng.Intn(2) == 0 {
+			units = math.MinInt64
+		}
+	}
+	return Quotation{Units: units, Nano: nano}
+}
+
+func sameSign(q Quotation) bool {
+	return !(q.Units > 0 && q.Nano < 0 || q.Units < 0 && q.Nano > 0)
+}
+
+func TestQuotationRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 10000; i++ {
+		q := randomQuotation(rng)
+		if _, err := NewQuotation(q.Units, q.Nano); err != nil {
+			t.Fatalf("generator produced invalid %+v: %v", q, err)
+		}
+
+		s := q.String()
+		parsed, err := ParseQuotation(s)
+		if err != nil {
+			t.Fatalf("ParseQuotation(%q) of %+v: %v", s, q, err)
+		}
+		if parsed != q {
+			t.Fatalf("ParseQuotation(%q) = %+v, want %+v", s, parsed, q)
+		}
+		if !sameSign(parsed) {
+			t.Fatalf("units and nano signs differ: %+v", parsed)
+		}
+
+		// Строка с произвольными нулями в дробной части и явным плюсом разбирается в ту же котировку
+		if !strings.HasPrefix(s, "-") {
+			padded := "+" + s
+			if !strings.Contains(padded, ".") {
+				padded += "."
+			}
+			padded += strings.Repeat("0", 9-len(padded[strings.Index(padded, ".")+1:]))
+			if again, err := ParseQuotation(padded); err != nil || again != q {
+				t.Fatalf("ParseQuotation(%q) = %+v, %v; want %+v", padded, again, err, q)
+			}
+		}
+	}
+}
+
+func TestQuotationParseStringCanonical(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 10000; i++ {
+		// Десятичная строка: до 18 цифр целой части, до 9 дробной, случайный знак
+		s := strconv.FormatInt(rng.Int63n(1_000_000_000_000_000_000), 10)
+		if n := rng.Intn(10); n > 0 {
+			frac := make([]byte, n)
+			for j := range frac {
+				frac[j] = byte('0' + rng.Intn(10))
+			}
+			s += "." + string(frac)
+		}
+		if rng.Intn(2) == 0 {
+			s = "-" + s
+		}
+
+		q, err := ParseQuotation(s)
+		if err != nil {
+			t.Fatalf("ParseQuotation(%q): %v", s, err)
+		}
+		if !sameSign(q) {
+			t.Fatalf("ParseQuotation(%q) = %+v: signs differ", s, q)
+		}
+
+		want, _ := new(big.Rat).SetString(s)
+		got, _ := new(big.Rat).SetString(q.String())
+		if want.Cmp(got) != 0 {
+			t.Fatalf("ParseQuotation(%q).String() = %q", s, q.String())
+		}
+		if again, err := ParseQuotation(q.String()); err != nil || again != q {
+			t.Fatalf("String is not canonical for %q: %q", s, q.String())
+		}
+	}
+}
+
+func TestQuotationArithmetic(t *testing.T) {
+	q := func(s string) Quotation {
+		v, err := ParseQuotation(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	sum, err := q("1.7").Add(q("-2.9"))
+	if err != nil || sum != q("-1.2") {
+		t.Fatalf("1.7 + -2.9 = %v, %v", sum, err)
+	}
+	diff, err := q("0.000000001").Sub(q("0.000000002"))
+	if err != nil || diff != q("-0.000000001") {
+		t.Fatalf("sub = %v, %v", diff, err)
+	}
+	lot, err := q("250.35").MulInt(10)
+	if err != nil || lot != q("2503.5") {
+		t.Fatalf("MulInt = %v, %v", lot, err)
+	}
+	product, err := q("1.5").Mul(q("-0.000000001"))
+	if err != nil || product != q("-0.000000002") {
+		t.Fatalf("Mul half away from zero = %v, %v", product, err)
+	}
+	quotient, err := q("1").Div(q("3"))
+	if err != nil || quotient != q("0.333333333") {
+		t.Fatalf("Div = %v, %v", quotient, err)
+	}
+	if _, err := q("1").Div(Quotation{}); err == nil {
+		t.Fatal("Div by zero: want error")
+	}
+}
+
+func TestQuotationOverflow(t *testing.T) {
+	maxQ := Quotation{Units: math.MaxInt64, Nano: 999_999_999}
+	minQ := Quotation{Units: math.MinInt64, Nano: -999_999_999}
+	cent := Quotation{Nano: 10_000_000}
+
+	if _, err := maxQ.Add(cent); err == nil {
+		t.Error("max + 0.01: want overflow error")
+	}
+	if _, err := minQ.Sub(cent); err == nil {
+		t.Error("min - 0.01: want overflow error")
+	}
+	if _, err := minQ.Neg(); err == nil {
+		t.Error("-min: want overflow error")
+	}
+	if _, err := maxQ.MulInt(2); err == nil {
+		t.Error("max * 2: want overflow error")
+	}
+	if _, err := (Quotation{Units: math.MinInt64 / 2}).MulInt(-3); err == nil {
+		t.Error("min/2 * -3: want overflow error")
+	}
+	if _, err := maxQ.Mul(Quotation{Units: 2}); err == nil {
+		t.Error("max * 2.0: want overflow error")
+	}
+	if _, err := maxQ.Div(Quotation{Nano: 500_000_000}); err == nil {
+		t.Error("max / 0.5: want overflow error")
+	}
+
+	// Граница без переполнения
+	edge, err := (Quotation{Units: math.MaxInt64 - 1, Nano: 999_999_999}).Add(Quotation{Nano: 1})
+	if err != nil || edge != (Quotation{Units: math.MaxInt64}) {
+		t.Errorf("edge add = %+v, %v", edge, err)
+	}
+	for i := 0; i < 1000; i++ {
+		rng := rand.New(rand.NewSource(int64(i)))
+		a, b := randomQuotation(rng), randomQuotation(rng)
+		sum, err := a.Add(b)
+		exact := new(big.Int).Add(a.nanos(), b.nanos())
+		if err != nil {
+			if units := new(big.Int).Quo(exact, big.NewInt(nanoFactor)); units.IsInt64() {
+				t.Fatalf("%+v + %+v: unexpected error %v", a, b, err)
+			}
+			continue
+		}
+		if sum.nanos().Cmp(exact) != 0 || !sameSign(sum) {
+			t.Fatalf("%+v + %+v = %+v", a, b, sum)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"mamonolitmvp/internal/rules"
+)
+
+const (
+	// MaxScreenerInstruments наибольшее число инструментов одного прогона скринера
+	MaxScreenerInstruments = 500
+	DefaultScreenerSort    = "TrendFactor"
+)
+
+// ScreenerRequest сигналы по списку инструментов за период. Инструменты берутся из InstrumentIds,
+// иначе из каталога по фильтру Type/Sector/Currency/Exchange, иначе из WATCHLIST.
+type ScreenerRequest struct {
+	InstrumentIds []string `json:"instrumentIds" query:"instrumentId"`
+	Type          string   `json:"type" query:"type"`
+	Sector        string   `json:"sector" query:"sector"`
+	Currency      string   `json:"currency" query:"currency"`
+	Exchange      string   `json:"exchange" query:"exchange"`
+
+	Interval string `json:"interval" query:"interval"`
+	From     string `json:"from" query:"from"`
+	To       string `json:"to" query:"to"`
+
+	// SortBy числовое поле сигнала из GET /api/v1/rules/fields, по умолчанию TrendFactor
+	SortBy string `json:"sortBy" query:"sortBy"`
+	// Order asc или desc, по умолчанию desc
+	Order string `json:"order" query:"order"`
+	// Limit число строк рейтинга в ответе; 0 — все. Ошибки по инструментам возвращаются всегда.
+	Limit int `json:"limit" query:"limit"`
+}
+
+func (r ScreenerRequest) Validate() error {
+	var v ValidationError
+	v.validateRange(r.From, r.To, r.Interval, false)
+
+	if len(r.InstrumentIds) > MaxScreenerInstruments {
+		v.Add("instrumentIds", "at most %d instruments", MaxScreenerInstruments)
+	}
+	for i, id := range r.InstrumentIds {
+		if id == "" {
+			v.Add(fmt.Sprintf("instrumentIds[%d]", i), "is required")
+		}
+	}
+	if _, ok := InstrumentMethods[r.Type]; r.Type != "" && !ok {
+		v.Add("type", "must be one of share, bond, etf, currency, future")
+	}
+
+	if _, ok := rules.LookupScalar(r.SortBy); r.SortBy != "" && !ok {
+		v.Add("sortBy", "unknown numeric signal field %q, see GET /api/v1/rules/fields", r.SortBy)
+	}
+	if r.Order != "" && r.Order != "asc" && r.Order != "desc" {
+		v.Add("order", "must be asc or desc")
+	}
+	if r.Limit < 0 || r.Limit > MaxScreenerInstruments {
+		v.Add("limit", "must be in [0, %d]", MaxScreenerInstruments)
+	}
+	return v.Err()
+}
+
+// Catalogue фильтр каталога из запроса
+func (r ScreenerRequest) Catalogue() ListInstrumentsRequest {
+	return ListInstrumentsRequest{
+		Type:       r.Type,
+		Sector:     r.Sector,
+		Currency:   r.Currency,
+		Exchange:   r.Exchange,
+		Pagination: Pagination{Limit: MaxScreenerInstruments},
+	}
+}
+
+// ScreenerRow строка рейтинга; Fields — числовые поля сигнала по именам из GET /api/v1/rules/fields
+type ScreenerRow struct {
+	Rank         int                `json:"rank"`
+	InstrumentId string             `json:"instrumentId"`
+	Ticker       string             `json:"ticker"`
+	Name         string             `json:"name,omitempty"`
+	Candles      int                `json:"candles"`
+	Fields       map[string]float64 `json:"fields"`
+	RSITrend     string             `json:"rsiTrend"`
+	// Action решение стратегии сигналов бэктеста: BUY, SELL или HOLD
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ScreenerError инструмент, по которому сигнал не посчитан
+type ScreenerError struct {
+	InstrumentId string `json:"instrumentId"`
+	Ticker       string `json:"ticker,omitempty"`
+	Error        string `json:"error"`
+}
+
+type ScreenerResponse struct {
+	SortBy string `json:"sortBy"`
+	Order  string `json:"order"`
+	// Instruments число проверенных инструментов: строки рейтинга до Limit плюс ошибки
+	Instruments int             `json:"instruments"`
+	Rows        []ScreenerRow   `json:"rows"`
+	Errors      []ScreenerError `json:"errors"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"mamonolitmvp/internal/rules"
+	"time"
+)
+
+// StrategyRule правило стратегии на языке internal/rules, например
+// "Hurst > 0.6 AND TrendFactor > 0 AND NormFdi < 0.3 -> BUY".
+// Правила стратегии проверяются по возрастанию Priority, решение дает первое сработавшее.
+type StrategyRule struct {
+	Strategy    string    `json:"strategy" gorm:"primaryKey;size:100"`
+	Name        string    `json:"name" gorm:"primaryKey;size:100"`
+	Expression  string    `json:"expression" gorm:"type:TEXT"`
+	Priority    int       `json:"priority"`
+	Enabled     bool      `json:"enabled"`
+	Description string    `json:"description,omitempty" gorm:"type:TEXT"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type PutRuleRequest struct {
+	// Expression: "<условие> -> BUY|SELL|HOLD", поля и функции — GET /api/v1/rules/fields
+	Expression string `json:"expression"`
+	Priority   int    `json:"priority"`
+	// Enabled: по умолчанию true
+	Enabled     *bool  `json:"enabled"`
+	Description string `json:"description"`
+}
+
+func (r PutRuleRequest) Validate() error {
+	var v ValidationError
+	if r.Expression == "" {
+		v.Add("expression", "is required")
+	} else if _, err := rules.Parse(r.Expression); err != nil {
+		v.Add("expression", "%s", err)
+	}
+	return v.Err()
+}
+
+// ValidateRuleKey проверяет имя стратегии и правила из пути запроса
+func ValidateRuleKey(strategy, name string) error {
+	var v ValidationError
+	for field, value := range map[string]string{"strategy": strategy, "name": name} {
+		if value == "" {
+			v.Add(field, "is required")
+		} else if len(value) > 100 {
+			v.Add(field, "must be at most 100 characters")
+		}
+	}
+	return v.Err()
+}
+
+type RulesResponse struct {
+	Rules []StrategyRule `json:"rules"`
+}
+
+type RuleResponse struct {
+	Rule StrategyRule `json:"rule"`
+}
+
+type RuleFieldsResponse struct {
+	Fields    []rules.Field `json:"fields"`
+	Functions []string      `json:"functions"`
+}
+
+// RuleResult результат проверки одного правила: сработало ли условие и значения его сравнений
+type RuleResult struct {
+	Name       string         `json:"name"`
+	Expression string         `json:"expression"`
+	Action     string         `json:"action"`
+	Priority   int            `json:"priority"`
+	Fired      bool           `json:"fired"`
+	Clauses    []rules.Clause `json:"clauses"`
+	// Error правило не вычислено, например ряд короче окна функции; считается не сработавшим
+	Error string `json:"error,omitempty"`
+}
+
+// RuleEvaluation последнее решение стратегии по инструменту и интервалу свечей
+type RuleEvaluation struct {
+	Strategy     string `json:"strategy" gorm:"primaryKey;size:100"`
+	InstrumentId string `json:"instrumentId" gorm:"primaryKey;size:255"`
+	Interval     string `json:"interval" gorm:"primaryKey;size:50"`
+	Ticker       string `json:"ticker" gorm:"size:50"`
+	// Action BUY, SELL или HOLD; HOLD, если не сработало ни одно правило
+	Action string `json:"action" gorm:"size:10"`
+	// Rule правило, давшее решение
+	Rule        string       `json:"rule,omitempty" gorm:"size:100"`
+	Rules       []RuleResult `json:"rules" gorm:"serializer:json;type:JSONB"`
+	From        time.Time    `json:"from"`
+	To          time.Time    `json:"to"`
+	EvaluatedAt time.Time    `json:"evaluatedAt"`
+}
+
+type RuleEvaluationResponse struct {
+	Evaluation RuleEvaluation `json:"evaluation"`
+}
+
+type RuleEvaluationsResponse struct {
+	Evaluations []RuleEvaluation `json:"evaluations"`
+}
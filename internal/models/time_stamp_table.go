@@ -1,46 +1,106 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
 
+// HistoricCandle свеча в формате ответа Tinkoff GetCandles
 type HistoricCandle struct {
-	InstrumentId string    `json:"instrumentID" gorm:"primaryKey;size:255"`
-	Time         time.Time `json:"time" gorm:"primaryKey"`
+	InstrumentId string    `json:"instrumentID"`
+	Interval     string    `json:"interval"`
+	Time         time.Time `json:"time"`
 	Volume       string    `json:"volume"`
+	IsComplete   bool      `json:"isComplete"`
+
+	High  Quotation `json:"high"`
+	Low   Quotation `json:"low"`
+	Close Quotation `json:"close"`
+	Open  Quotation `json:"open"`
+}
+
+type HistoricCandles struct {
+	Candles []HistoricCandle `json:"candles"`
+}
 
-	High  High  `json:"high" gorm:"foreignKey:InstrumentId,Time;references:InstrumentId,Time"`
-	Low   Low   `json:"low" gorm:"foreignKey:InstrumentId,Time;references:InstrumentId,Time"`
-	Close Close `json:"close" gorm:"foreignKey:InstrumentId,Time;references:InstrumentId,Time"`
-	Open  Open  `json:"open" gorm:"foreignKey:InstrumentId,Time;references:InstrumentId,Time"`
+// Candle свеча в хранилище: одна строка hypertable candles с числовыми ценами
+type Candle struct {
+	InstrumentId string    `json:"instrumentId" gorm:"primaryKey;size:255"`
+	Interval     string    `json:"interval" gorm:"primaryKey;size:50"`
+	Time         time.Time `json:"time" gorm:"primaryKey"`
+	Open         float64   `json:"open" gorm:"type:NUMERIC(20,9);not null"`
+	High         float64   `json:"high" gorm:"type:NUMERIC(20,9);not null"`
+	Low          float64   `json:"low" gorm:"type:NUMERIC(20,9);not null"`
+	Close        float64   `json:"close" gorm:"type:NUMERIC(20,9);not null"`
+	Volume       int64     `json:"volume" gorm:"type:BIGINT;not null"`
 }
 
-type High struct {
-	InstrumentId string    `json:"-" gorm:"primaryKey;size:255"`
-	Time         time.Time `json:"-" gorm:"primaryKey"`
-	Units        string    `json:"units"`
-	Nano         int       `json:"nano"`
+// ToCandle переводит свечу Tinkoff в формат хранилища
+func (c HistoricCandle) ToCandle() (Candle, error) {
+	candle := Candle{
+		InstrumentId: c.InstrumentId,
+		Interval:     c.Interval,
+		Time:         c.Time,
+		Open:         c.Open.Float64(),
+		High:         c.High.Float64(),
+		Low:          c.Low.Float64(),
+		Close:        c.Close.Float64(),
+	}
+
+	if c.Volume != "" {
+		volume, err := strconv.ParseInt(c.Volume, 10, 64)
+		if err != nil {
+			return Candle{}, fmt.Errorf("invalid volume %q: %w", c.Volume, err)
+		}
+		candle.Volume = volume
+	}
+
+	return candle, nil
 }
 
-type Low struct {
-	InstrumentId string    `json:"-" gorm:"primaryKey;size:255"`
-	Time         time.Time `json:"-" gorm:"primaryKey"`
-	Units        string    `json:"units"`
-	Nano         int       `json:"nano"`
+func ToCandles(candles []HistoricCandle) ([]Candle, error) {
+	result := make([]Candle, 0, len(candles))
+	for _, c := range candles {
+		candle, err := c.ToCandle()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, candle)
+	}
+	return result, nil
 }
 
-type Close struct {
-	InstrumentId string    `json:"-" gorm:"primaryKey;size:255"`
-	Time         time.Time `json:"-" gorm:"primaryKey"`
-	Units        string    `json:"units"`
-	Nano         int       `json:"nano"`
+// candleDurations длительность свечи внутридневных интервалов
+var candleDurations = map[string]time.Duration{
+	"CANDLE_INTERVAL_1_MIN":  time.Minute,
+	"CANDLE_INTERVAL_2_MIN":  2 * time.Minute,
+	"CANDLE_INTERVAL_3_MIN":  3 * time.Minute,
+	"CANDLE_INTERVAL_5_MIN":  5 * time.Minute,
+	"CANDLE_INTERVAL_10_MIN": 10 * time.Minute,
+	"CANDLE_INTERVAL_15_MIN": 15 * time.Minute,
+	"CANDLE_INTERVAL_30_MIN": 30 * time.Minute,
+	"CANDLE_INTERVAL_HOUR":   time.Hour,
+	"CANDLE_INTERVAL_2_HOUR": 2 * time.Hour,
+	"CANDLE_INTERVAL_4_HOUR": 4 * time.Hour,
 }
 
-type Open struct {
-	InstrumentId string    `json:"-" gorm:"primaryKey;size:255"`
-	Time         time.Time `json:"-" gorm:"primaryKey"`
-	Units        string    `json:"units"`
-	Nano         int       `json:"nano"`
+// CandleEnd время закрытия свечи интервала, открытой в start; дни, недели и месяцы считаются по календарю
+func CandleEnd(interval string, start time.Time) (time.Time, bool) {
+	switch interval {
+	case "CANDLE_INTERVAL_DAY":
+		return start.AddDate(0, 0, 1), true
+	case "CANDLE_INTERVAL_WEEK":
+		return start.AddDate(0, 0, 7), true
+	case "CANDLE_INTERVAL_MONTH":
+		return start.AddDate(0, 1, 0), true
+	}
+	d, ok := candleDurations[interval]
+	return start.Add(d), ok
 }
 
-type HistoricCandles struct {
-	Candles []HistoricCandle `json:"candles"`
+// Closed свеча закрылась к моменту now; свеча неизвестного интервала считается закрытой
+func (c Candle) Closed(now time.Time) bool {
+	end, ok := CandleEnd(c.Interval, c.Time)
+	return !ok || !end.After(now)
 }
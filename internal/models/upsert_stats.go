@@ -0,0 +1,25 @@
+package models
+
+import "fmt"
+
+// UpsertStats итог сохранения: сколько строк вставлено, обновлено и оставлено без изменений
+type UpsertStats struct {
+	Inserted  int `json:"inserted"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+}
+
+func (s *UpsertStats) Add(other UpsertStats) {
+	s.Inserted += other.Inserted
+	s.Updated += other.Updated
+	s.Unchanged += other.Unchanged
+}
+
+// Total число обработанных строк
+func (s UpsertStats) Total() int {
+	return s.Inserted + s.Updated + s.Unchanged
+}
+
+func (s UpsertStats) String() string {
+	return fmt.Sprintf("%d inserted, %d updated, %d unchanged", s.Inserted, s.Updated, s.Unchanged)
+}
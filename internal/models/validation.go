@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"mamonolitmvp/internal/apperr"
+	"strings"
+	"time"
+)
+
+// FieldError ошибка в конкретном поле запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError список ошибок полей запроса, разворачивается в apperr.ErrInvalidArgument
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Field+": "+fe.Message)
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return apperr.ErrInvalidArgument
+}
+
+func (e *ValidationError) Add(field, format string, args ...any) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err nil, если ошибок нет
+func (e *ValidationError) Err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// parseTime разбирает обязательную RFC3339 метку времени поля
+func (e *ValidationError) parseTime(field, value string) (time.Time, bool) {
+	if value == "" {
+		e.Add(field, "is required")
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		e.Add(field, "must be an RFC3339 timestamp, e.g. 2025-02-16T17:33:53.311Z")
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func (e *ValidationError) interval(field, value string) bool {
+	if _, ok := CandleIntervalLimits[value]; !ok {
+		e.Add(field, "unknown candle interval %q", value)
+		return false
+	}
+	return true
+}
+
+// validateRange проверяет from < to; при withinLimit еще и то, что период укладывается в один запрос GetCandles
+func (e *ValidationError) validateRange(from, to, interval string, withinLimit bool) {
+	fromTime, fromOk := e.parseTime("from", from)
+	toTime, toOk := e.parseTime("to", to)
+	intervalOk := e.interval("interval", interval)
+	if !fromOk || !toOk {
+		return
+	}
+
+	if !fromTime.Before(toTime) {
+		e.Add("to", "must be after from")
+		return
+	}
+
+	if withinLimit && intervalOk {
+		if end := CandleIntervalLimits[interval].Add(fromTime); toTime.After(end) {
+			e.Add("to", "range exceeds the %s limit, must not be after %s", interval, end.Format(time.RFC3339))
+		}
+	}
+}
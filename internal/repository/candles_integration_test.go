@@ -0,0 +1,43 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/models"
+)
+
+func TestListCandlesPage(t *testing.T) {
+	repo := cleanRepository(t)
+	ctx := context.Background()
+	candles := testCandles(1, 2, 3, 4, 5)
+	if _, err := repo.CreateCandles(ctx, candles); err != nil {
+		t.Fatalf("CreateCandles: %v", err)
+	}
+	from, to := candles[0].Time, candles[4].Time.Add(time.Hour)
+
+	page, total, err := repo.ListCandles(ctx, "uid-1", "CANDLE_INTERVAL_HOUR", from, to, models.Pagination{Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListCandles: %v", err)
+	}
+	if total != 5 || len(page) != 2 || page[0].Close != 2 || page[1].Close != 3 {
+		t.Errorf("page = %+v, total %d; want closes 2, 3 of 5", page, total)
+	}
+
+	// Конец периода не входит
+	_, total, err = repo.ListCandles(ctx, "uid-1", "CANDLE_INTERVAL_HOUR", from, candles[4].Time, models.Pagination{Limit: 10})
+	if err != nil || total != 4 {
+		t.Errorf("total up to the last candle = %d, %v; want 4", total, err)
+	}
+
+	empty, total, err := repo.ListCandles(ctx, "uid-1", "CANDLE_INTERVAL_HOUR", to, to.Add(time.Hour), models.Pagination{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListCandles on an empty range: %v", err)
+	}
+	if total != 0 || empty == nil || len(empty) != 0 {
+		t.Errorf("empty range = %#v, total %d; want an empty non-nil slice", empty, total)
+	}
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"mamonolitmvp/internal/models"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -18,8 +20,11 @@ func NewInstrumentRepository(db *gorm.DB) *InstrumentRepository {
 	}
 }
 
-func (ir *InstrumentRepository) CreateInstruments(instruments []models.PlacementPrice) error {
+// CreateInstruments сохраняет инструменты вместе с деталями типа: новые вставляются, измененные обновляются,
+// совпадающие с сохраненными не трогаются. Инструмент считается обновленным, если изменились общие поля или детали.
+func (ir *InstrumentRepository) CreateInstruments(ctx context.Context, instruments []models.Instrument) (models.UpsertStats, error) {
 	batchSize := 100
+	var stats models.UpsertStats
 
 	for i := 0; i < len(instruments); i += batchSize {
 		end := i + batchSize
@@ -27,42 +32,95 @@ func (ir *InstrumentRepository) CreateInstruments(instruments []models.Placement
 			end = len(instruments)
 		}
 
-		batch := make([]*models.PlacementPrice, end-i)
+		batch := make([]*models.Instrument, end-i)
 		for j := 0; j < len(batch); j++ {
 			batch[j] = &instruments[i+j]
 		}
 
-		tx := ir.db.Begin()
-		if tx.Error != nil {
-			log.Printf("failed to start transaction: %v", tx.Error)
-			return tx.Error
-		}
-
 		batchNumber := (i / batchSize) + 1
 		totalBatches := (len(instruments) + batchSize - 1) / batchSize
 
-		log.Printf("Create batch %d of %d", batchNumber, totalBatches)
+		var batchStats models.UpsertStats
+		err := ir.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			rows, unique, err := upsert(tx, &batch, []string{"uid"}, "updated_at")
+			if err != nil {
+				log.Printf("failed to upsert instruments: %v", err)
+				return err
+			}
+
+			inserted := make(map[string]bool, len(rows))
+			for _, row := range rows {
+				inserted[row.Key] = row.Inserted
+			}
+
+			details := []any{
+				detailsOf(batch, func(in *models.Instrument) *models.ShareDetails { return in.ShareDetails }),
+				detailsOf(batch, func(in *models.Instrument) *models.BondDetails { return in.BondDetails }),
+				detailsOf(batch, func(in *models.Instrument) *models.EtfDetails { return in.EtfDetails }),
+				detailsOf(batch, func(in *models.Instrument) *models.CurrencyDetails { return in.CurrencyDetails }),
+				detailsOf(batch, func(in *models.Instrument) *models.FutureDetails { return in.FutureDetails }),
+			}
+			var touched []string
+			for _, d := range details {
+				detailRows, _, err := upsert(tx, d, []string{"uid"})
+				if err != nil {
+					log.Printf("failed to upsert instrument details: %v", err)
+					return err
+				}
+				for _, row := range detailRows {
+					if _, ok := inserted[row.Key]; !ok {
+						inserted[row.Key] = false
+						touched = append(touched, row.Key)
+					}
+				}
+			}
+
+			// Изменились только детали: отмечаем время изменения и у общей строки
+			if len(touched) > 0 {
+				err := tx.Model(&models.Instrument{}).Where("uid IN ?", touched).Update("updated_at", time.Now()).Error
+				if err != nil {
+					log.Printf("failed to touch instruments: %v", err)
+					return err
+				}
+			}
 
-		err := tx.Create(&batch).Error
+			for _, isNew := range inserted {
+				if isNew {
+					batchStats.Inserted++
+				} else {
+					batchStats.Updated++
+				}
+			}
+			batchStats.Unchanged = unique - len(inserted)
+			return nil
+		})
 		if err != nil {
-			tx.Rollback()
-			log.Printf("failed to insert batch: %v", err)
-			return err
+			return stats, err
 		}
 
-		if err := tx.Commit().Error; err != nil {
-			tx.Rollback()
-			log.Printf("failed to commit transaction: %v", err)
-			return err
-		}
+		log.Printf("Upsert instruments batch %d of %d: %s", batchNumber, totalBatches, batchStats)
+		stats.Add(batchStats)
 	}
 
-	log.Println("Instument create success")
-	return nil
+	log.Printf("Instruments upsert success: %s", stats)
+	return stats, nil
 }
 
-func (ir *InstrumentRepository) CreateCandles(candles []models.HistoricCandle) error {
+// detailsOf детали типа из batch; uid деталей совпадает с uid инструмента
+func detailsOf[T any](batch []*models.Instrument, get func(*models.Instrument) *T) []*T {
+	details := make([]*T, 0, len(batch))
+	for _, instrument := range batch {
+		if detail := get(instrument); detail != nil {
+			details = append(details, detail)
+		}
+	}
+	return details
+}
+
+// CreateCandles сохраняет свечи; перекрывающиеся загрузки обновляют уже сохраненные свечи, а не падают на ключе
+func (ir *InstrumentRepository) CreateCandles(ctx context.Context, candles []models.Candle) (models.UpsertStats, error) {
 	batchSize := 100
+	var stats models.UpsertStats
 
 	for i := 0; i < len(candles); i += batchSize {
 		end := i + batchSize
@@ -70,70 +128,392 @@ func (ir *InstrumentRepository) CreateCandles(candles []models.HistoricCandle) e
 			end = len(candles)
 		}
 
-		batch := make([]*models.HistoricCandle, end-i)
+		batch := make([]*models.Candle, end-i)
 		for j := 0; j < len(batch); j++ {
 			batch[j] = &candles[i+j]
 		}
 
-		tx := ir.db.Begin()
-		if tx.Error != nil {
-			log.Printf("failed to start transaction: %v", tx.Error)
-			return tx.Error
+		rows, unique, err := upsert(ir.db.WithContext(ctx), &batch, []string{"instrument_id", "interval", "time"})
+		if err != nil {
+			log.Printf("failed to upsert candles: %v", err)
+			return stats, err
 		}
+		stats.Add(upsertStats(rows, unique))
+	}
 
-		batchNumber := (i / batchSize) + 1
-		totalBatches := (len(candles) + batchSize - 1) / batchSize
+	log.Printf("Candles upsert success: %s", stats)
+	return stats, nil
+}
 
-		log.Printf("Create batch %d of %d", batchNumber, totalBatches)
+func (ir *InstrumentRepository) GetCoverage(ctx context.Context, instrumentUID, interval string) ([]models.CandleCoverage, error) {
+	var coverage []models.CandleCoverage
+	err := ir.db.WithContext(ctx).Where("instrument_id=? AND interval=?", instrumentUID, interval).Order("\"from\"").Find(&coverage).Error
+	if err != nil {
+		log.Printf("failed to Get Coverage: %v", err)
+		return nil, err
+	}
+	return coverage, nil
+}
 
-		err := tx.Create(&batch).Error
+// AddCoverage добавляет загруженный диапазон и склеивает его с пересекающимися и смежными
+func (ir *InstrumentRepository) AddCoverage(ctx context.Context, added models.CandleCoverage) error {
+	return ir.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []models.CandleCoverage
+		err := tx.Where("instrument_id=? AND interval=?", added.InstrumentId, added.Interval).
+			Where("\"from\"<=? AND \"to\">=?", added.To, added.From).
+			Find(&existing).Error
 		if err != nil {
-			tx.Rollback()
-			log.Printf("failed to insert batch: %v", err)
+			log.Printf("failed to Get Coverage: %v", err)
 			return err
 		}
 
-		if err := tx.Commit().Error; err != nil {
-			tx.Rollback()
-			log.Printf("failed to commit transaction: %v", err)
+		for _, c := range existing {
+			if c.From.Before(added.From) {
+				added.From = c.From
+			}
+			if c.To.After(added.To) {
+				added.To = c.To
+			}
+			if err := tx.Delete(&c).Error; err != nil {
+				log.Printf("failed to delete coverage: %v", err)
+				return err
+			}
+		}
+
+		if err := tx.Create(&added).Error; err != nil {
+			log.Printf("failed to insert coverage: %v", err)
 			return err
 		}
+		return nil
+	})
+}
+
+func (ir *InstrumentRepository) GetInstrument(ctx context.Context, instrumentUID string) (models.Instrument, error) {
+	var instrument models.Instrument
+	err := ir.preloadDetails(ir.db.WithContext(ctx)).Where("uid=?", instrumentUID).First(&instrument).Error
+	if err != nil {
+		log.Printf("failed to Get Instrument: %v", err)
+		return models.Instrument{}, err
 	}
+	return instrument, nil
+}
 
-	log.Println("Candles create success")
+func (ir *InstrumentRepository) GetCheckpoint(ctx context.Context, instrumentUID, interval string) (models.BackfillCheckpoint, error) {
+	var checkpoint models.BackfillCheckpoint
+	err := ir.db.WithContext(ctx).Where("instrument_id=? AND interval=?", instrumentUID, interval).First(&checkpoint).Error
+	if err != nil {
+		return models.BackfillCheckpoint{}, err
+	}
+	return checkpoint, nil
+}
+
+func (ir *InstrumentRepository) SaveCheckpoint(ctx context.Context, checkpoint models.BackfillCheckpoint) error {
+	err := ir.db.WithContext(ctx).Save(&checkpoint).Error
+	if err != nil {
+		log.Printf("failed to save checkpoint: %v", err)
+		return err
+	}
 	return nil
 }
 
-func (ir *InstrumentRepository) GetInstrumentUIDAndFigi(ticker string) (models.Ids, error) {
-	var ids models.Ids
-	err := ir.db.Model(&models.PlacementPrice{}).Select("uid", "figi").Where("ticker=?", ticker).Scan(&ids).Error
-	if err != nil || ids.Uid == "" && ids.Figi == "" {
-		err = gorm.ErrRecordNotFound
-		log.Printf("failed to Get InstrumentID: %v", err)
-		return ids, err
+func (ir *InstrumentRepository) CreateFundamentals(ctx context.Context, fundamentals []models.AssetFundamental) error {
+	if len(fundamentals) == 0 {
+		return nil
 	}
-	return ids, nil
+
+	err := ir.db.WithContext(ctx).CreateInBatches(fundamentals, 100).Error
+	if err != nil {
+		log.Printf("failed to insert fundamentals: %v", err)
+		return err
+	}
+
+	log.Println("Fundamentals create success")
+	return nil
 }
 
-func (ir *InstrumentRepository) GetCandles(instrumentUID string) ([]models.HistoricCandle, error) {
-	var candles []models.HistoricCandle
-	err := ir.db.Model(&models.HistoricCandle{}).Where("instrument_id=?", instrumentUID).Find(&candles).Error
-	fmt.Println("err2: ", err)
-	if err != nil || len(candles) == 0 {
+func (ir *InstrumentRepository) GetAssetUID(ctx context.Context, instrumentUID string) (string, error) {
+	var assetUID string
+	err := ir.db.WithContext(ctx).Model(&models.Instrument{}).Select("asset_uid").Where("uid=?", instrumentUID).Scan(&assetUID).Error
+	if err != nil || assetUID == "" {
 		err = gorm.ErrRecordNotFound
-		log.Printf("no candles found for instrument UID: %s", instrumentUID)
+		log.Printf("failed to Get AssetUID: %v", err)
+		return "", err
+	}
+	return assetUID, nil
+}
+
+// GetFundamentals последние сохраненные фундаментальные показатели актива
+func (ir *InstrumentRepository) GetFundamentals(ctx context.Context, assetUID string) (models.AssetFundamental, error) {
+	var fundamental models.AssetFundamental
+	err := ir.db.WithContext(ctx).Where("asset_uid=?", assetUID).Order("time DESC").First(&fundamental).Error
+	if err != nil {
+		log.Printf("failed to Get Fundamentals: %v", err)
+		return models.AssetFundamental{}, err
+	}
+	return fundamental, nil
+}
+
+// ListInstruments страница каталога по фильтрам запроса (пустой фильтр не ограничивает) и общее число найденных
+func (ir *InstrumentRepository) ListInstruments(ctx context.Context, req models.ListInstrumentsRequest) ([]models.Instrument, int64, error) {
+	query := ir.db.WithContext(ctx).Model(&models.Instrument{})
+	if req.Q != "" {
+		pattern := "%" + likeEscaper.Replace(req.Q) + "%"
+		query = query.Where("UPPER(ticker)=UPPER(?) OR UPPER(isin)=UPPER(?) OR UPPER(figi)=UPPER(?) OR name ILIKE ?",
+			req.Q, req.Q, req.Q, pattern)
+	}
+	if req.Type != "" {
+		query = query.Where("instrument_type=?", req.Type)
+	}
+	if req.Ticker != "" {
+		query = query.Where("ticker=?", req.Ticker)
+	}
+	if req.Isin != "" {
+		query = query.Where("isin=?", req.Isin)
+	}
+	if req.Figi != "" {
+		query = query.Where("figi=?", req.Figi)
+	}
+	if req.Sector != "" {
+		query = query.Where("LOWER(sector)=LOWER(?)", req.Sector)
+	}
+	if req.Currency != "" {
+		query = query.Where("LOWER(currency)=LOWER(?)", req.Currency)
+	}
+	if req.Exchange != "" {
+		query = query.Where("LOWER(exchange)=LOWER(?)", req.Exchange)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		log.Printf("failed to Count Instruments: %v", err)
+		return nil, 0, err
+	}
+
+	var instruments []models.Instrument
+	err := ir.preloadDetails(query).Order("ticker, uid").Limit(req.Limit).Offset(req.Offset).Find(&instruments).Error
+	if err != nil {
+		log.Printf("failed to List Instruments: %v", err)
+		return nil, 0, err
+	}
+	return instruments, total, nil
+}
+
+// likeEscaper экранирует спецсимволы LIKE в поисковой строке
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (ir *InstrumentRepository) preloadDetails(query *gorm.DB) *gorm.DB {
+	return query.Preload("ShareDetails").Preload("BondDetails").Preload("EtfDetails").
+		Preload("CurrencyDetails").Preload("FutureDetails")
+}
+
+func (ir *InstrumentRepository) GetCandlesInRange(ctx context.Context, instrumentUID, interval string, from, to time.Time) ([]models.Candle, error) {
+	var candles []models.Candle
+	err := ir.db.WithContext(ctx).Model(&models.Candle{}).
+		Where("instrument_id=? AND interval=? AND time>=? AND time<?", instrumentUID, interval, from, to).
+		Order("time").
+		Find(&candles).Error
+	if err != nil {
 		log.Printf("failed to Get Candles: %v", err)
 		return nil, err
 	}
+	if len(candles) == 0 {
+		log.Printf("no candles found for instrument UID: %s", instrumentUID)
+		return nil, gorm.ErrRecordNotFound
+	}
 	return candles, nil
 }
 
-func (ir *InstrumentRepository) GetTicker(instrumentUID string) (string, error) {
+// ListCandles страница свечей [from, to) по времени и общее число свечей периода; пустой период — не ошибка
+func (ir *InstrumentRepository) ListCandles(ctx context.Context, instrumentUID, interval string, from, to time.Time, page models.Pagination) ([]models.Candle, int64, error) {
+	query := ir.db.WithContext(ctx).Model(&models.Candle{}).
+		Where("instrument_id=? AND interval=? AND time>=? AND time<?", instrumentUID, interval, from, to)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		log.Printf("failed to Count Candles: %v", err)
+		return nil, 0, err
+	}
+
+	candles := []models.Candle{}
+	err := query.Order("time").Limit(page.Limit).Offset(page.Offset).Find(&candles).Error
+	if err != nil {
+		log.Printf("failed to List Candles: %v", err)
+		return nil, 0, err
+	}
+	return candles, total, nil
+}
+
+func (ir *InstrumentRepository) GetTicker(ctx context.Context, instrumentUID string) (string, error) {
 	var name string
-	err := ir.db.Model(&models.PlacementPrice{}).Select("ticker").Where("uid=?", instrumentUID).Scan(&name).Error
+	err := ir.db.WithContext(ctx).Model(&models.Instrument{}).Select("ticker").Where("uid=?", instrumentUID).Scan(&name).Error
 	if err != nil {
 		log.Printf("failed to Get Name: %v", err)
 		return "", err
 	}
 	return name, nil
 }
+
+func (ir *InstrumentRepository) GetJobStates(ctx context.Context) ([]models.JobState, error) {
+	var states []models.JobState
+	err := ir.db.WithContext(ctx).Find(&states).Error
+	if err != nil {
+		log.Printf("failed to Get Job States: %v", err)
+		return nil, err
+	}
+	return states, nil
+}
+
+func (ir *InstrumentRepository) SaveJobState(ctx context.Context, state models.JobState) error {
+	err := ir.db.WithContext(ctx).Save(&state).Error
+	if err != nil {
+		log.Printf("failed to save job state: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ListRules правила стратегии по приоритету; пустая strategy — правила всех стратегий
+func (ir *InstrumentRepository) ListRules(ctx context.Context, strategy string) ([]models.StrategyRule, error) {
+	query := ir.db.WithContext(ctx).Order("strategy, priority, name")
+	if strategy != "" {
+		query = query.Where("strategy = ?", strategy)
+	}
+
+	var rules []models.StrategyRule
+	err := query.Find(&rules).Error
+	if err != nil {
+		log.Printf("failed to List Rules: %v", err)
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (ir *InstrumentRepository) SaveRule(ctx context.Context, rule models.StrategyRule) error {
+	err := ir.db.WithContext(ctx).Save(&rule).Error
+	if err != nil {
+		log.Printf("failed to save rule: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (ir *InstrumentRepository) DeleteRule(ctx context.Context, strategy, name string) error {
+	result := ir.db.WithContext(ctx).Where("strategy = ? AND name = ?", strategy, name).Delete(&models.StrategyRule{})
+	if result.Error != nil {
+		log.Printf("failed to delete rule: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (ir *InstrumentRepository) SaveRuleEvaluation(ctx context.Context, evaluation models.RuleEvaluation) error {
+	err := ir.db.WithContext(ctx).Save(&evaluation).Error
+	if err != nil {
+		log.Printf("failed to save rule evaluation: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (ir *InstrumentRepository) ListRuleEvaluations(ctx context.Context, strategy string) ([]models.RuleEvaluation, error) {
+	var evaluations []models.RuleEvaluation
+	err := ir.db.WithContext(ctx).Where("strategy = ?", strategy).Order("instrument_id, interval").Find(&evaluations).Error
+	if err != nil {
+		log.Printf("failed to List Rule Evaluations: %v", err)
+		return nil, err
+	}
+	return evaluations, nil
+}
+
+// ListAlerts оповещения по инструменту и интервалу; пустые значения не фильтруют
+func (ir *InstrumentRepository) ListAlerts(ctx context.Context, instrumentUID, interval string) ([]models.Alert, error) {
+	query := ir.db.WithContext(ctx).Order("id")
+	if instrumentUID != "" {
+		query = query.Where("instrument_id = ?", instrumentUID)
+	}
+	if interval != "" {
+		query = query.Where("interval = ?", interval)
+	}
+
+	var alerts []models.Alert
+	err := query.Find(&alerts).Error
+	if err != nil {
+		log.Printf("failed to List Alerts: %v", err)
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (ir *InstrumentRepository) GetAlert(ctx context.Context, id uint) (models.Alert, error) {
+	var alert models.Alert
+	err := ir.db.WithContext(ctx).First(&alert, id).Error
+	if err != nil {
+		log.Printf("failed to Get Alert: %v", err)
+		return models.Alert{}, err
+	}
+	return alert, nil
+}
+
+// SaveAlert создает оповещение с нулевым Id или заменяет существующее
+func (ir *InstrumentRepository) SaveAlert(ctx context.Context, alert *models.Alert) error {
+	err := ir.db.WithContext(ctx).Save(alert).Error
+	if err != nil {
+		log.Printf("failed to save alert: %v", err)
+		return err
+	}
+	return nil
+}
+
+// SaveAlertState записывает только состояние проверок, чтобы не затереть определение, измененное во время проверки
+func (ir *InstrumentRepository) SaveAlertState(ctx context.Context, alert models.Alert) error {
+	err := ir.db.WithContext(ctx).Model(&models.Alert{Id: alert.Id}).
+		Select("last_state", "last_candle_time", "last_evaluated_at", "last_fired_at").
+		Updates(&alert).Error
+	if err != nil {
+		log.Printf("failed to save alert state: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteAlert удаляет оповещение вместе с его срабатываниями
+func (ir *InstrumentRepository) DeleteAlert(ctx context.Context, id uint) error {
+	return ir.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Alert{}, id)
+		if result.Error != nil {
+			log.Printf("failed to delete alert: %v", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		err := tx.Where("alert_id = ?", id).Delete(&models.AlertEvent{}).Error
+		if err != nil {
+			log.Printf("failed to delete alert events: %v", err)
+		}
+		return err
+	})
+}
+
+func (ir *InstrumentRepository) CreateAlertEvent(ctx context.Context, event *models.AlertEvent) error {
+	err := ir.db.WithContext(ctx).Create(event).Error
+	if err != nil {
+		log.Printf("failed to create alert event: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ListAlertEvents последние limit срабатываний оповещения, новые первыми
+func (ir *InstrumentRepository) ListAlertEvents(ctx context.Context, alertID uint, limit int) ([]models.AlertEvent, error) {
+	var events []models.AlertEvent
+	err := ir.db.WithContext(ctx).Where("alert_id = ?", alertID).Order("id DESC").Limit(limit).Find(&events).Error
+	if err != nil {
+		log.Printf("failed to List Alert Events: %v", err)
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,37 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"mamonolitmvp/internal/models"
+)
+
+func TestSaveRuleEvaluationPerInterval(t *testing.T) {
+	repo := cleanRepository(t)
+	ctx := context.Background()
+
+	for _, evaluation := range []models.RuleEvaluation{
+		{Strategy: "trend", InstrumentId: "uid-1", Interval: "CANDLE_INTERVAL_DAY", Action: "BUY"},
+		{Strategy: "trend", InstrumentId: "uid-1", Interval: "CANDLE_INTERVAL_HOUR", Action: "SELL"},
+		{Strategy: "trend", InstrumentId: "uid-1", Interval: "CANDLE_INTERVAL_DAY", Action: "HOLD"},
+	} {
+		if err := repo.SaveRuleEvaluation(ctx, evaluation); err != nil {
+			t.Fatalf("SaveRuleEvaluation: %v", err)
+		}
+	}
+
+	evaluations, err := repo.ListRuleEvaluations(ctx, "trend")
+	if err != nil {
+		t.Fatalf("ListRuleEvaluations: %v", err)
+	}
+	if len(evaluations) != 2 {
+		t.Fatalf("evaluations = %+v, want one per interval", evaluations)
+	}
+	if evaluations[0].Interval != "CANDLE_INTERVAL_DAY" || evaluations[0].Action != "HOLD" ||
+		evaluations[1].Interval != "CANDLE_INTERVAL_HOUR" || evaluations[1].Action != "SELL" {
+		t.Errorf("evaluations = %+v, want the latest HOLD for the day and SELL for the hour", evaluations)
+	}
+}
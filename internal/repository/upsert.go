@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"mamonolitmvp/internal/models"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// upsertedRow строка, которую вставил или изменил upsert
+type upsertedRow struct {
+	Key      string
+	Inserted bool
+}
+
+// upsert сохраняет batch одной командой INSERT ... ON CONFLICT (keys) DO UPDATE.
+// Существующая строка переписывается, только если изменилась хотя бы одна колонка кроме keys и ignored,
+// поэтому повторная загрузка тех же данных ничего не меняет. Строки batch с одинаковым ключом
+// (пересекающиеся страницы, поток и догрузка) схлопываются до последней: Postgres не дает одной команде
+// изменить строку дважды. Возвращает вставленные и измененные строки с первой колонкой ключа и число
+// уникальных строк; уникальные строки, которых нет в результате, остались без изменений.
+func upsert(tx *gorm.DB, batch any, keys []string, ignored ...string) ([]upsertedRow, int, error) {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(batch); err != nil {
+		return nil, 0, err
+	}
+	table := stmt.Schema.Table
+
+	batch, unique, err := dedupe(tx.Statement.Context, stmt.Schema, batch, keys)
+	if err != nil {
+		return nil, 0, err
+	}
+	if unique == 0 {
+		return nil, 0, nil
+	}
+
+	conflict := make([]clause.Column, 0, len(keys))
+	for _, key := range keys {
+		conflict = append(conflict, clause.Column{Name: key})
+	}
+
+	var updated, current, excluded []string
+	for _, column := range stmt.Schema.DBNames {
+		if slices.Contains(keys, column) {
+			continue
+		}
+		updated = append(updated, column)
+		if slices.Contains(ignored, column) {
+			continue
+		}
+		current = append(current, fmt.Sprintf(`%q.%q`, table, column))
+		excluded = append(excluded, fmt.Sprintf(`EXCLUDED.%q`, column))
+	}
+
+	onConflict := clause.OnConflict{Columns: conflict, DoNothing: len(updated) == 0}
+	if len(updated) > 0 {
+		onConflict.DoUpdates = clause.AssignmentColumns(updated)
+	}
+	if len(current) > 0 {
+		onConflict.Where = clause.Where{Exprs: []clause.Expression{clause.Expr{
+			SQL: fmt.Sprintf("(%s) IS DISTINCT FROM (%s)", strings.Join(current, ", "), strings.Join(excluded, ", ")),
+		}}}
+	}
+
+	// gorm не отдает RETURNING в произвольную структуру, поэтому INSERT собирается без выполнения
+	// и дополняется ключом строки и признаком вставки (xmax = 0 только у новой версии без конфликта)
+	dry := tx.Session(&gorm.Session{DryRun: true, SkipDefaultTransaction: true, NewDB: true}).Omit(clause.Associations).Clauses(onConflict).Create(batch)
+	if dry.Error != nil {
+		return nil, 0, dry.Error
+	}
+	query := fmt.Sprintf(`%s RETURNING %q.%q::text AS key, (xmax = 0) AS inserted`,
+		dry.Statement.SQL.String(), table, keys[0])
+
+	var rows []upsertedRow
+	if err := tx.Raw(query, dry.Statement.Vars...).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, unique, nil
+}
+
+// dedupe оставляет из строк с одинаковым ключом последнюю, сохраняя порядок остальных.
+// batch — срез или указатель на срез моделей либо указателей на модели; без повторов возвращается как есть.
+func dedupe(ctx context.Context, s *schema.Schema, batch any, keys []string) (any, int, error) {
+	rows := reflect.Indirect(reflect.ValueOf(batch))
+	if rows.Kind() != reflect.Slice {
+		return batch, 1, nil
+	}
+
+	fields := make([]*schema.Field, len(keys))
+	for i, key := range keys {
+		if fields[i] = s.LookUpField(key); fields[i] == nil {
+			return nil, 0, fmt.Errorf("upsert %s: unknown key column %q", s.Table, key)
+		}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rowKeys := make([]string, rows.Len())
+	last := make(map[string]int, rows.Len())
+	for i := range rowKeys {
+		row := reflect.Indirect(rows.Index(i))
+		parts := make([]string, len(fields))
+		for j, field := range fields {
+			value, _ := field.ValueOf(ctx, row)
+			if t, ok := value.(time.Time); ok {
+				value = t.UTC().Format(time.RFC3339Nano)
+			}
+			parts[j] = fmt.Sprint(value)
+		}
+		rowKeys[i] = strings.Join(parts, "\x00")
+		last[rowKeys[i]] = i
+	}
+	if len(last) == rows.Len() {
+		return batch, rows.Len(), nil
+	}
+
+	unique := reflect.MakeSlice(rows.Type(), 0, len(last))
+	for i, key := range rowKeys {
+		if last[key] == i {
+			unique = reflect.Append(unique, rows.Index(i))
+		}
+	}
+	ptr := reflect.New(rows.Type())
+	ptr.Elem().Set(unique)
+	return ptr.Interface(), len(last), nil
+}
+
+// upsertStats итог upsert для batch из total строк
+func upsertStats(rows []upsertedRow, total int) models.UpsertStats {
+	var stats models.UpsertStats
+	for _, row := range rows {
+		if row.Inserted {
+			stats.Inserted++
+		} else {
+			stats.Updated++
+		}
+	}
+	stats.Unchanged = total - stats.Inserted - stats.Updated
+	return stats
+}
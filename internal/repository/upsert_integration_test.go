@@ -0,0 +1,175 @@
+//go:build integration
+
+// Интеграционные тесты upsert на настоящем Postgres: go test -tags integration ./internal/repository.
+// База поднимается через testdb: embedded-postgres или TEST_POSTGRES_DSN.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/internal/testdb"
+
+	"gorm.io/gorm"
+)
+
+var testDB *gorm.DB
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Run(m, "upsert-pg", func(db *gorm.DB) error {
+		testDB = db
+		return db.AutoMigrate(&models.Candle{}, &models.Instrument{}, &models.ShareDetails{}, &models.BondDetails{},
+			&models.EtfDetails{}, &models.CurrencyDetails{}, &models.FutureDetails{}, &models.RuleEvaluation{})
+	}))
+}
+
+// cleanRepository пустые таблицы для теста
+func cleanRepository(t *testing.T) *InstrumentRepository {
+	t.Helper()
+	for _, table := range []string{"candles", "share_details", "bond_details", "etf_details",
+		"currency_details", "future_details", "instruments", "rule_evaluations"} {
+		if err := testDB.Exec(fmt.Sprintf("TRUNCATE %q CASCADE", table)).Error; err != nil {
+			t.Fatalf("truncate %s: %v", table, err)
+		}
+	}
+	return NewInstrumentRepository(testDB)
+}
+
+func testCandles(closes ...float64) []models.Candle {
+	start := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+	candles := make([]models.Candle, len(closes))
+	for i, c := range closes {
+		candles[i] = models.Candle{
+			InstrumentId: "uid-1",
+			Interval:     "CANDLE_INTERVAL_HOUR",
+			Time:         start.Add(time.Duration(i) * time.Hour),
+			Open:         c, High: c + 1, Low: c - 1, Close: c, Volume: 10,
+		}
+	}
+	return candles
+}
+
+func assertStats(t *testing.T, got models.UpsertStats, inserted, updated, unchanged int) {
+	t.Helper()
+	want := models.UpsertStats{Inserted: inserted, Updated: updated, Unchanged: unchanged}
+	if got != want {
+		t.Fatalf("stats = %+v, want %+v", got, want)
+	}
+}
+
+func storedCloses(t *testing.T) []float64 {
+	t.Helper()
+	var closes []float64
+	if err := testDB.Model(&models.Candle{}).Order("time").Pluck("close", &closes).Error; err != nil {
+		t.Fatal(err)
+	}
+	return closes
+}
+
+func TestCreateCandlesUpsert(t *testing.T) {
+	repo := cleanRepository(t)
+	ctx := context.Background()
+
+	stats, err := repo.CreateCandles(ctx, testCandles(100, 101, 102))
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	assertStats(t, stats, 3, 0, 0)
+
+	stats, err = repo.CreateCandles(ctx, testCandles(100, 101, 102))
+	if err != nil {
+		t.Fatalf("unchanged re-insert: %v", err)
+	}
+	assertStats(t, stats, 0, 0, 3)
+
+	stats, err = repo.CreateCandles(ctx, testCandles(100, 105, 102, 103))
+	if err != nil {
+		t.Fatalf("changed update: %v", err)
+	}
+	assertStats(t, stats, 1, 1, 2)
+
+	closes := storedCloses(t)
+	want := []float64{100, 105, 102, 103}
+	if fmt.Sprint(closes) != fmt.Sprint(want) {
+		t.Fatalf("stored closes = %v, want %v", closes, want)
+	}
+}
+
+func TestCreateCandlesInBatchDuplicate(t *testing.T) {
+	repo := cleanRepository(t)
+	ctx := context.Background()
+
+	// Одна и та же свеча дважды в пачке: незакрытая и закрытая версии из потока
+	candles := testCandles(100, 101)
+	duplicate := candles[1]
+	duplicate.Close = 107
+	candles = append(candles, duplicate)
+
+	stats, err := repo.CreateCandles(ctx, candles)
+	if err != nil {
+		t.Fatalf("upsert with duplicate key: %v", err)
+	}
+	assertStats(t, stats, 2, 0, 0)
+
+	closes := storedCloses(t)
+	want := []float64{100, 107}
+	if fmt.Sprint(closes) != fmt.Sprint(want) {
+		t.Fatalf("stored closes = %v, want %v (last duplicate wins)", closes, want)
+	}
+
+	// Повтор той же пачки: дубликат уже совпадает с сохраненной строкой
+	stats, err = repo.CreateCandles(ctx, candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStats(t, stats, 0, 1, 1)
+}
+
+func TestCreateInstrumentsUpsert(t *testing.T) {
+	repo := cleanRepository(t)
+	ctx := context.Background()
+
+	share := func(uid, name, shareType string) models.Instrument {
+		return models.Instrument{Uid: uid, Ticker: uid, Name: name, InstrumentType: "share",
+			ShareDetails: &models.ShareDetails{Uid: uid, ShareType: shareType}}
+	}
+
+	stats, err := repo.CreateInstruments(ctx, []models.Instrument{share("a", "A", "common"), share("b", "B", "common")})
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	assertStats(t, stats, 2, 0, 0)
+
+	stats, err = repo.CreateInstruments(ctx, []models.Instrument{share("a", "A", "common"), share("b", "B", "common")})
+	if err != nil {
+		t.Fatalf("unchanged re-insert: %v", err)
+	}
+	assertStats(t, stats, 0, 0, 2)
+
+	// a меняет только детали, b — общие поля и встречается в пачке дважды
+	stats, err = repo.CreateInstruments(ctx, []models.Instrument{
+		share("a", "A", "preferred"), share("b", "B old", "common"), share("b", "B new", "common"),
+	})
+	if err != nil {
+		t.Fatalf("changed update with duplicate: %v", err)
+	}
+	assertStats(t, stats, 0, 2, 0)
+
+	var got models.Instrument
+	if err := testDB.Preload("ShareDetails").First(&got, "uid = ?", "b").Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "B new" {
+		t.Fatalf("name = %q, want last duplicate %q", got.Name, "B new")
+	}
+	if err := testDB.Preload("ShareDetails").First(&got, "uid = ?", "a").Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.ShareDetails == nil || got.ShareDetails.ShareType != "preferred" {
+		t.Fatalf("share details = %+v, want preferred", got.ShareDetails)
+	}
+}
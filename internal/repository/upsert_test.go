@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/models"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestDedupeKeepsLastByKey(t *testing.T) {
+	s, err := schema.Parse(&models.Candle{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	moscow := time.FixedZone("MSK", 3*60*60)
+	batch := []models.Candle{
+		{InstrumentId: "a", Interval: "CANDLE_INTERVAL_HOUR", Time: at, Close: 1},
+		{InstrumentId: "b", Interval: "CANDLE_INTERVAL_HOUR", Time: at, Close: 2},
+		{InstrumentId: "a", Interval: "CANDLE_INTERVAL_HOUR", Time: at.In(moscow), Close: 3},
+		{InstrumentId: "a", Interval: "CANDLE_INTERVAL_DAY", Time: at, Close: 4},
+	}
+
+	deduped, n, err := dedupe(context.Background(), s, &batch, []string{"instrument_id", "interval", "time"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("unique = %d, want 3", n)
+	}
+	got := *deduped.(*[]models.Candle)
+	want := []float64{2, 3, 4}
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(got), len(want))
+	}
+	for i, c := range got {
+		if c.Close != want[i] {
+			t.Errorf("row %d close = %v, want %v", i, c.Close, want[i])
+		}
+	}
+	if len(batch) != 4 {
+		t.Errorf("input batch modified: %d rows", len(batch))
+	}
+}
+
+func TestDedupeWithoutDuplicates(t *testing.T) {
+	s, err := schema.Parse(&models.ShareDetails{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := []*models.ShareDetails{{Uid: "a"}, {Uid: "b"}}
+
+	deduped, n, err := dedupe(context.Background(), s, batch, []string{"uid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("unique = %d, want 2", n)
+	}
+	if got := deduped.([]*models.ShareDetails); &got[0] != &batch[0] {
+		t.Error("batch without duplicates must be passed through")
+	}
+}
+
+func TestDedupeUnknownKey(t *testing.T) {
+	s, err := schema.Parse(&models.Candle{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := dedupe(context.Background(), s, []models.Candle{{}}, []string{"uid"}); err == nil {
+		t.Fatal("expected error for unknown key column")
+	}
+}
@@ -0,0 +1,385 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Clause сравнение из условия правила и его значение при вычислении
+type Clause struct {
+	Clause string  `json:"clause"`
+	Left   float64 `json:"left"`
+	Right  float64 `json:"right"`
+	Fired  bool    `json:"fired"`
+}
+
+// Eval вычисляет условие правила. Вычисляются все сравнения, даже если результат уже известен,
+// чтобы объяснение показывало каждое из них.
+func (r *Rule) Eval(env *Env) (bool, []Clause, error) {
+	var clauses []Clause
+	v, err := r.condition.eval(env, &clauses)
+	if err != nil {
+		return false, nil, err
+	}
+	return v.b, clauses, nil
+}
+
+type kind int
+
+const (
+	kindNumber kind = iota
+	kindBool
+	kindSeries
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindBool:
+		return "comparison"
+	case kindSeries:
+		return "series"
+	}
+	return "number"
+}
+
+type value struct {
+	num    float64
+	b      bool
+	series []float64
+}
+
+type node interface {
+	String() string
+	check() (kind, error)
+	eval(env *Env, clauses *[]Clause) (value, error)
+}
+
+// expect проверяет тип операнда
+func expect(n node, want kind) error {
+	got, err := n.check()
+	if err != nil {
+		return err
+	}
+	if got != want {
+		if got == kindSeries {
+			return fmt.Errorf("%s is a series, use a function such as last(%s) or avg(%s, n)", n, n, n)
+		}
+		return fmt.Errorf("%s: expected %s, got %s", n, want, got)
+	}
+	return nil
+}
+
+type numberNode struct {
+	value float64
+	text  string
+}
+
+func (n *numberNode) String() string       { return n.text }
+func (n *numberNode) check() (kind, error) { return kindNumber, nil }
+func (n *numberNode) eval(*Env, *[]Clause) (value, error) {
+	return value{num: n.value}, nil
+}
+
+type fieldNode struct {
+	field Field
+}
+
+func newFieldNode(t token) (node, error) {
+	field, ok := fieldsByName[strings.ToLower(t.text)]
+	if !ok {
+		return nil, fmt.Errorf("position %d: unknown field %q", t.pos+1, t.text)
+	}
+	return &fieldNode{field: field}, nil
+}
+
+func (n *fieldNode) String() string { return n.field.Name }
+
+func (n *fieldNode) check() (kind, error) {
+	if n.field.Series {
+		return kindSeries, nil
+	}
+	return kindNumber, nil
+}
+
+func (n *fieldNode) eval(env *Env, _ *[]Clause) (value, error) {
+	key := strings.ToLower(n.field.Name)
+	if n.field.Series {
+		return value{series: env.series[key]}, nil
+	}
+	return value{num: env.scalars[key]}, nil
+}
+
+type parenNode struct {
+	inner node
+}
+
+func (n *parenNode) String() string       { return "(" + n.inner.String() + ")" }
+func (n *parenNode) check() (kind, error) { return n.inner.check() }
+func (n *parenNode) eval(env *Env, clauses *[]Clause) (value, error) {
+	return n.inner.eval(env, clauses)
+}
+
+type negNode struct {
+	operand node
+}
+
+func (n *negNode) String() string { return "-" + n.operand.String() }
+
+func (n *negNode) check() (kind, error) {
+	return kindNumber, expect(n.operand, kindNumber)
+}
+
+func (n *negNode) eval(env *Env, clauses *[]Clause) (value, error) {
+	v, err := n.operand.eval(env, clauses)
+	return value{num: -v.num}, err
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) String() string { return "NOT " + n.operand.String() }
+
+func (n *notNode) check() (kind, error) {
+	return kindBool, expect(n.operand, kindBool)
+}
+
+func (n *notNode) eval(env *Env, clauses *[]Clause) (value, error) {
+	v, err := n.operand.eval(env, clauses)
+	return value{b: !v.b}, err
+}
+
+// binaryNode арифметика над числами или AND/OR над сравнениями
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) String() string {
+	return n.left.String() + " " + n.op + " " + n.right.String()
+}
+
+func (n *binaryNode) check() (kind, error) {
+	want := kindNumber
+	if n.op == "AND" || n.op == "OR" {
+		want = kindBool
+	}
+	if err := expect(n.left, want); err != nil {
+		return 0, err
+	}
+	if err := expect(n.right, want); err != nil {
+		return 0, err
+	}
+	return want, nil
+}
+
+func (n *binaryNode) eval(env *Env, clauses *[]Clause) (value, error) {
+	l, err := n.left.eval(env, clauses)
+	if err != nil {
+		return value{}, err
+	}
+	r, err := n.right.eval(env, clauses)
+	if err != nil {
+		return value{}, err
+	}
+
+	switch n.op {
+	case "AND":
+		return value{b: l.b && r.b}, nil
+	case "OR":
+		return value{b: l.b || r.b}, nil
+	case "+":
+		return value{num: l.num + r.num}, nil
+	case "-":
+		return value{num: l.num - r.num}, nil
+	case "*":
+		return value{num: l.num * r.num}, nil
+	}
+	return value{num: l.num / r.num}, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) String() string {
+	return n.left.String() + " " + n.op + " " + n.right.String()
+}
+
+func (n *compareNode) check() (kind, error) {
+	if err := expect(n.left, kindNumber); err != nil {
+		return 0, err
+	}
+	if err := expect(n.right, kindNumber); err != nil {
+		return 0, err
+	}
+	return kindBool, nil
+}
+
+func (n *compareNode) eval(env *Env, clauses *[]Clause) (value, error) {
+	l, err := n.left.eval(env, clauses)
+	if err != nil {
+		return value{}, err
+	}
+	r, err := n.right.eval(env, clauses)
+	if err != nil {
+		return value{}, err
+	}
+	if !isFinite(l.num) || !isFinite(r.num) {
+		return value{}, fmt.Errorf("%s: value is not finite (%v %s %v)", n, l.num, n.op, r.num)
+	}
+
+	var fired bool
+	switch n.op {
+	case ">":
+		fired = l.num > r.num
+	case ">=":
+		fired = l.num >= r.num
+	case "<":
+		fired = l.num < r.num
+	case "<=":
+		fired = l.num <= r.num
+	case "==":
+		fired = l.num == r.num
+	case "!=":
+		fired = l.num != r.num
+	}
+
+	*clauses = append(*clauses, Clause{Clause: n.String(), Left: l.num, Right: r.num, Fired: fired})
+	return value{b: fired}, nil
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// function функция над рядом: n — число последних значений, 0 — весь ряд
+type function struct {
+	name string
+	// count второй аргумент: число значений (avg, min, max) или шаг назад (prev, change)
+	count string
+	calc  func(series []float64, n int) float64
+}
+
+var functions = map[string]function{
+	"last": {name: "last", calc: func(s []float64, _ int) float64 { return s[len(s)-1] }},
+	"prev": {name: "prev", count: "steps back", calc: func(s []float64, n int) float64 { return s[len(s)-1-n] }},
+	"change": {name: "change", count: "steps back", calc: func(s []float64, n int) float64 {
+		return s[len(s)-1] - s[len(s)-1-n]
+	}},
+	"avg": {name: "avg", count: "window", calc: func(s []float64, n int) float64 {
+		var sum float64
+		for _, v := range s[len(s)-n:] {
+			sum += v
+		}
+		return sum / float64(n)
+	}},
+	"min": {name: "min", count: "window", calc: func(s []float64, n int) float64 {
+		m := math.Inf(1)
+		for _, v := range s[len(s)-n:] {
+			m = math.Min(m, v)
+		}
+		return m
+	}},
+	"max": {name: "max", count: "window", calc: func(s []float64, n int) float64 {
+		m := math.Inf(-1)
+		for _, v := range s[len(s)-n:] {
+			m = math.Max(m, v)
+		}
+		return m
+	}},
+}
+
+// Functions функции над рядами и числами для справки по языку
+var Functions = []string{
+	"last(series)",
+	"prev(series, k): значение k шагов назад",
+	"change(series, k): last(series) - prev(series, k)",
+	"avg(series[, n]): среднее последних n значений или всего ряда",
+	"min(series[, n])",
+	"max(series[, n])",
+	"abs(x)",
+}
+
+// callNode вызов функции: last(s), prev(s, k), change(s, k), avg(s[, n]), min(s[, n]), max(s[, n]) или abs(x)
+type callNode struct {
+	fn   string
+	args []node
+	n    int
+}
+
+func newCallNode(t token, args []node) (node, error) {
+	name := strings.ToLower(t.text)
+	if name == "abs" {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("position %d: abs expects 1 argument, got %d", t.pos+1, len(args))
+		}
+		return &callNode{fn: name, args: args}, nil
+	}
+
+	fn, ok := functions[name]
+	if !ok {
+		return nil, fmt.Errorf("position %d: unknown function %q", t.pos+1, t.text)
+	}
+
+	call := &callNode{fn: fn.name, args: args}
+	switch {
+	case len(args) == 1 && (fn.count == "" || fn.count == "window"):
+	case len(args) == 2 && fn.count != "":
+		number, ok := args[1].(*numberNode)
+		if !ok || number.value != math.Trunc(number.value) || number.value < 0 || number.value == 0 && fn.count == "window" {
+			return nil, fmt.Errorf("position %d: %s: %s must be a positive integer", t.pos+1, fn.name, fn.count)
+		}
+		call.n = int(number.value)
+	default:
+		return nil, fmt.Errorf("position %d: %s: wrong number of arguments: %d", t.pos+1, fn.name, len(args))
+	}
+	return call, nil
+}
+
+func (n *callNode) String() string {
+	args := make([]string, len(n.args))
+	for i, arg := range n.args {
+		args[i] = arg.String()
+	}
+	return n.fn + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (n *callNode) check() (kind, error) {
+	if n.fn == "abs" {
+		return kindNumber, expect(n.args[0], kindNumber)
+	}
+	if got, err := n.args[0].check(); err != nil || got != kindSeries {
+		if err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("%s: first argument must be a series", n)
+	}
+	return kindNumber, nil
+}
+
+func (n *callNode) eval(env *Env, clauses *[]Clause) (value, error) {
+	arg, err := n.args[0].eval(env, clauses)
+	if err != nil {
+		return value{}, err
+	}
+	if n.fn == "abs" {
+		return value{num: math.Abs(arg.num)}, nil
+	}
+
+	fn := functions[n.fn]
+	count := n.n
+	if fn.count == "window" && count == 0 {
+		count = len(arg.series)
+	}
+	need := count
+	if fn.count != "window" {
+		need = count + 1
+	}
+	if len(arg.series) == 0 || len(arg.series) < need {
+		return value{}, fmt.Errorf("%s: series has %d values, need %d", n, len(arg.series), max(need, 1))
+	}
+	return value{num: fn.calc(arg.series, count)}, nil
+}
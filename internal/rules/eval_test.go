@@ -0,0 +1,134 @@
+package rules
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func testEnv() *Env {
+	return &Env{
+		scalars: map[string]float64{
+			"price":       5,
+			"hurst":       0.7,
+			"rsi":         25,
+			"trendfactor": -0.1,
+			"fdi":         math.NaN(),
+		},
+		series: map[string][]float64{
+			"prices":      {1, 2, 3, 4, 5},
+			"hurstseries": {0.4, 0.5, 0.6},
+			"rsiseries":   {},
+		},
+	}
+}
+
+func eval(t *testing.T, src string) (bool, []Clause, error) {
+	t.Helper()
+	rule, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", src, err)
+	}
+	return rule.Eval(testEnv())
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		// NOT связывает сильнее AND, AND — сильнее OR
+		{src: "NOT Hurst > 1 AND RSI < 30 OR Price > 10 -> BUY", want: true},
+		{src: "NOT Hurst > 0.5 AND RSI < 30 OR Price > 10 -> BUY", want: false},
+		{src: "NOT Hurst > 0.5 AND RSI < 30 OR Price > 4 -> BUY", want: true},
+		{src: "NOT (Hurst > 0.5 OR Price > 4) -> BUY", want: false},
+		{src: "Price - 1 * 2 == 3 -> BUY", want: true},
+		{src: "(Price - 1) * 2 == 8 -> BUY", want: true},
+		{src: "-TrendFactor * 10 == 1 -> BUY", want: true},
+		{src: "abs(TrendFactor) == 0.1 -> BUY", want: true},
+
+		// Функции над рядами
+		{src: "last(Prices) == 5 -> BUY", want: true},
+		{src: "prev(Prices, 0) == 5 -> BUY", want: true},
+		{src: "prev(Prices, 1) == 4 -> BUY", want: true},
+		{src: "prev(Prices, 4) == 1 -> BUY", want: true},
+		{src: "change(Prices, 4) == 4 -> BUY", want: true},
+		{src: "avg(Prices) == 3 -> BUY", want: true},
+		{src: "avg(Prices, 2) == 4.5 -> BUY", want: true},
+		{src: "avg(Prices, 5) == 3 -> BUY", want: true},
+		{src: "min(Prices, 3) == 3 -> BUY", want: true},
+		{src: "max(HurstSeries) == 0.6 -> BUY", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			got, _, err := eval(t, tt.src)
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		// Границы окон и шагов
+		{src: "prev(Prices, 5) > 0 -> BUY", want: "prev(Prices, 5): series has 5 values, need 6"},
+		{src: "change(HurstSeries, 3) > 0 -> BUY", want: "series has 3 values, need 4"},
+		{src: "avg(Prices, 6) > 0 -> BUY", want: "avg(Prices, 6): series has 5 values, need 6"},
+		{src: "min(HurstSeries, 4) > 0 -> BUY", want: "series has 3 values, need 4"},
+		{src: "last(RSISeries) > 0 -> BUY", want: "series has 0 values, need 1"},
+		{src: "avg(RSISeries) > 0 -> BUY", want: "series has 0 values, need 1"},
+		{src: "last(NormFdiSeries) > 0 -> BUY", want: "series has 0 values, need 1"},
+
+		// Деление на ноль и нечисловые значения
+		{src: "Price / 0 > 1 -> BUY", want: "value is not finite (+Inf > 1)"},
+		{src: "-Price / 0 < 1 -> BUY", want: "value is not finite (-Inf < 1)"},
+		{src: "0 / 0 == 0 -> BUY", want: "value is not finite (NaN == 0)"},
+		{src: "Price / (RSI - 25) != 0 -> BUY", want: "value is not finite"},
+		{src: "Fdi > 0 -> BUY", want: "Fdi > 0: value is not finite"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			got, clauses, err := eval(t, tt.src)
+			if err == nil {
+				t.Fatalf("Eval = %v, %+v, want error", got, clauses)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Eval error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalReportsEveryClause(t *testing.T) {
+	// OR уже истинно после первого сравнения, но объяснение содержит все три
+	fired, clauses, err := eval(t, "Price > 4 OR RSI > 50 AND NOT Hurst < 0.5 -> BUY")
+	if err != nil {
+		t.Fatalf("Eval: %v", err)
+	}
+	if !fired {
+		t.Error("Eval = false, want true")
+	}
+
+	want := []Clause{
+		{Clause: "Price > 4", Left: 5, Right: 4, Fired: true},
+		{Clause: "RSI > 50", Left: 25, Right: 50, Fired: false},
+		{Clause: "Hurst < 0.5", Left: 0.7, Right: 0.5, Fired: false},
+	}
+	if len(clauses) != len(want) {
+		t.Fatalf("clauses = %+v, want %+v", clauses, want)
+	}
+	for i := range want {
+		if clauses[i] != want[i] {
+			t.Errorf("clause %d = %+v, want %+v", i, clauses[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package rules
+
+import (
+	"mamonolitmvp/internal/math/price_analysis"
+	"strings"
+)
+
+// Field поле, доступное в условиях: число или ряд
+type Field struct {
+	Name        string `json:"name"`
+	Series      bool   `json:"series"`
+	Description string `json:"description"`
+}
+
+// Fields поля сигнала и скользящего анализа. Ряды упорядочены по времени, последнее значение — текущее.
+var Fields = []Field{
+	{Name: "Price", Description: "последняя цена закрытия"},
+	{Name: "Hurst", Description: "показатель Херста по всем свечам"},
+	{Name: "TrendFactor", Description: "(ShortSMA - LongSMA) / LongSMA"},
+	{Name: "RSI", Description: "последнее значение RSI"},
+	{Name: "ShortSMA", Description: "последнее значение короткой SMA"},
+	{Name: "LongSMA", Description: "последнее значение длинной SMA"},
+	{Name: "Width", Description: "ширина мультифрактального спектра"},
+	{Name: "Asym", Description: "асимметрия спектра"},
+	{Name: "Curvature", Description: "кривизна спектра"},
+	{Name: "Fdi", Description: "индекс фрактальной размерности"},
+	{Name: "NormWidth", Description: "нормированная ширина спектра"},
+	{Name: "NormAsym", Description: "нормированная асимметрия спектра"},
+	{Name: "NormCurvature", Description: "нормированная кривизна спектра"},
+	{Name: "NormFdi", Description: "нормированный FDI"},
+	{Name: "Prices", Series: true, Description: "цены закрытия"},
+	{Name: "RSISeries", Series: true, Description: "ряд RSI"},
+	{Name: "ShortSMASeries", Series: true, Description: "ряд короткой SMA"},
+	{Name: "LongSMASeries", Series: true, Description: "ряд длинной SMA"},
+	{Name: "HurstSeries", Series: true, Description: "показатель Херста по скользящим окнам"},
+	{Name: "FdiSeries", Series: true, Description: "FDI по скользящим окнам"},
+	{Name: "NormFdiSeries", Series: true, Description: "FDI по скользящим окнам, нормированный по всем окнам"},
+}
+
+// fieldsByName поля по имени в нижнем регистре
+var fieldsByName = func() map[string]Field {
+	m := make(map[string]Field, len(Fields))
+	for _, f := range Fields {
+		m[strings.ToLower(f.Name)] = f
+	}
+	return m
+}()
+
+// Env значения полей для одного инструмента
+type Env struct {
+	scalars map[string]float64
+	series  map[string][]float64
+}
+
+// NewEnv значения полей по ценам закрытия, сигналу по ним и скользящему анализу
+func NewEnv(prices []float64, signal price_analysis.Signal, window price_analysis.SlidingWindow, normFdi []price_analysis.NormalizeFdi) *Env {
+	fdiSeries := make([]float64, len(window.FdiSeries))
+	for i, f := range window.FdiSeries {
+		fdiSeries[i] = f.Fdi
+	}
+	normFdiSeries := make([]float64, len(normFdi))
+	for i, f := range normFdi {
+		normFdiSeries[i] = f.NormFdi
+	}
+
+	scalars := make(map[string]float64, len(Fields))
+	for name, value := range Scalars(prices, signal) {
+		scalars[strings.ToLower(name)] = value
+	}
+
+	return &Env{
+		scalars: scalars,
+		series: map[string][]float64{
+			"prices":         prices,
+			"rsiseries":      signal.RSI,
+			"shortsmaseries": signal.ShortSMA,
+			"longsmaseries":  signal.LongSMA,
+			"hurstseries":    window.HurstSeries,
+			"fdiseries":      fdiSeries,
+			"normfdiseries":  normFdiSeries,
+		},
+	}
+}
+
+// Scalars значения числовых полей по именам из Fields
+func Scalars(prices []float64, signal price_analysis.Signal) map[string]float64 {
+	return map[string]float64{
+		"Price":         last(prices),
+		"Hurst":         signal.Hurst,
+		"TrendFactor":   signal.TrendFactor,
+		"RSI":           last(signal.RSI),
+		"ShortSMA":      last(signal.ShortSMA),
+		"LongSMA":       last(signal.LongSMA),
+		"Width":         signal.Width,
+		"Asym":          signal.Asym,
+		"Curvature":     signal.Curvature,
+		"Fdi":           signal.Fdi.Fdi,
+		"NormWidth":     signal.NormWidth,
+		"NormAsym":      signal.NormAsym,
+		"NormCurvature": signal.NormCurvature,
+		"NormFdi":       signal.NormalizeFdi.NormFdi,
+	}
+}
+
+// LookupScalar числовое поле по имени без учета регистра
+func LookupScalar(name string) (Field, bool) {
+	field, ok := fieldsByName[strings.ToLower(name)]
+	return field, ok && !field.Series
+}
+
+func last(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
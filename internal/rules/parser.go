@@ -0,0 +1,359 @@
+// Package rules язык правил над полями сигнала и рядами скользящего анализа:
+//
+//	Hurst > 0.6 AND TrendFactor > 0 AND NormFdi < 0.3 -> BUY
+//
+// Условие строится из сравнений (> >= < <= == !=), AND, OR, NOT, скобок, арифметики (+ - * /)
+// и функций над рядами, например avg(HurstSeries, 5) > 0.55. Ключевые слова и имена полей
+// не зависят от регистра; вместо -> можно писать → или THEN.
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	Buy  = "BUY"
+	Sell = "SELL"
+	Hold = "HOLD"
+)
+
+// actions действие правила; LONG и SHORT — синонимы BUY и SELL
+var actions = map[string]string{
+	"BUY":   Buy,
+	"LONG":  Buy,
+	"SELL":  Sell,
+	"SHORT": Sell,
+	"HOLD":  Hold,
+}
+
+// Rule разобранное правило: условие и действие при его выполнении
+type Rule struct {
+	Source    string
+	Action    string
+	condition node
+}
+
+// Parse разбирает правило "<условие> -> <действие>" и проверяет типы: условие должно быть логическим,
+// поля — известными, ряды — использоваться только через функции
+func Parse(src string) (*Rule, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	condition, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.next(); t.kind != tokenArrow {
+		return nil, p.errorf(t, "expected -> and action")
+	}
+	t := p.next()
+	action, ok := actions[strings.ToUpper(t.text)]
+	if t.kind != tokenIdent || !ok {
+		return nil, p.errorf(t, "expected action BUY, SELL or HOLD")
+	}
+	if t := p.next(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected %q after action", t.text)
+	}
+
+	kind, err := condition.check()
+	if err != nil {
+		return nil, err
+	}
+	if kind != kindBool {
+		return nil, fmt.Errorf("condition %s is not a comparison", condition)
+	}
+
+	return &Rule{
+		Source:    src,
+		Action:    action,
+		condition: condition,
+	}, nil
+}
+
+// Condition условие правила в нормализованной записи
+func (r *Rule) Condition() string {
+	return r.condition.String()
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenArrow
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '→':
+			tokens = append(tokens, token{tokenArrow, "->", i})
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '>':
+			tokens = append(tokens, token{tokenArrow, "->", i})
+			i += 2
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			text := string(runes[start:i])
+			if strings.EqualFold(text, "THEN") {
+				tokens = append(tokens, token{tokenArrow, "->", start})
+			} else {
+				tokens = append(tokens, token{tokenIdent, text, start})
+			}
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case strings.ContainsRune("<>=!", r):
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "=" || op == "!" {
+				return nil, fmt.Errorf("position %d: unexpected %q, use == or !=", i+1, op)
+			}
+			tokens = append(tokens, token{tokenOp, op, i})
+			i += len(op)
+		case strings.ContainsRune("+-*/", r):
+			tokens = append(tokens, token{tokenOp, string(r), i})
+			i++
+		default:
+			return nil, fmt.Errorf("position %d: unexpected character %q", i+1, r)
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) keyword(word string) bool {
+	t := p.peek()
+	if t.kind == tokenIdent && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) op(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("end of rule: "+format, args...)
+	}
+	return fmt.Errorf("position %d: "+format, append([]any{t.pos + 1}, args...)...)
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) not() (node, error) {
+	if p.keyword("NOT") {
+		operand, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (node, error) {
+	left, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if op, ok := p.op(">", ">=", "<", "<=", "==", "!="); ok {
+		right, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		return &compareNode{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) sum() (node, error) {
+	left, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.op("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) product() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.op("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if _, ok := p.op("-"); ok {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &negNode{operand: operand}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid number %q", t.text)
+		}
+		return &numberNode{value: v, text: t.text}, nil
+	case tokenLParen:
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, p.errorf(t, "expected )")
+		}
+		return &parenNode{inner: inner}, nil
+	case tokenIdent:
+		if p.peek().kind != tokenLParen {
+			return newFieldNode(t)
+		}
+		p.next()
+		var args []node
+		if p.peek().kind != tokenRParen {
+			for {
+				arg, err := p.sum()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if p.peek().kind != tokenComma {
+					break
+				}
+				p.next()
+			}
+		}
+		if end := p.next(); end.kind != tokenRParen {
+			return nil, p.errorf(end, "expected ) after arguments")
+		}
+		return newCallNode(t, args)
+	}
+	return nil, p.errorf(t, "unexpected %q", t.text)
+}
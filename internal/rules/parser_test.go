@@ -0,0 +1,168 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// tree запись условия с явными скобками вокруг каждого узла, чтобы проверять приоритеты
+func tree(n node) string {
+	switch n := n.(type) {
+	case *binaryNode:
+		return fmt.Sprintf("(%s %s %s)", tree(n.left), n.op, tree(n.right))
+	case *compareNode:
+		return fmt.Sprintf("(%s %s %s)", tree(n.left), n.op, tree(n.right))
+	case *notNode:
+		return "(NOT " + tree(n.operand) + ")"
+	case *negNode:
+		return "(-" + tree(n.operand) + ")"
+	case *parenNode:
+		return tree(n.inner)
+	case *callNode:
+		args := make([]string, len(n.args))
+		for i, arg := range n.args {
+			args[i] = tree(arg)
+		}
+		return n.fn + "(" + strings.Join(args, ", ") + ")"
+	}
+	return n.String()
+}
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{
+			src:  "NOT Hurst > 1 AND RSI < 2 OR Price > 3 -> BUY",
+			want: "(((NOT (Hurst > 1)) AND (RSI < 2)) OR (Price > 3))",
+		},
+		{
+			src:  "Hurst > 1 OR RSI < 2 AND Price > 3 -> BUY",
+			want: "((Hurst > 1) OR ((RSI < 2) AND (Price > 3)))",
+		},
+		{
+			src:  "NOT (Hurst > 1 OR RSI < 2) -> SELL",
+			want: "(NOT ((Hurst > 1) OR (RSI < 2)))",
+		},
+		{
+			src:  "NOT NOT Hurst > 1 -> BUY",
+			want: "(NOT (NOT (Hurst > 1)))",
+		},
+		{
+			src:  "Price - 1 * 2 > -RSI / 4 -> BUY",
+			want: "((Price - (1 * 2)) > ((-RSI) / 4))",
+		},
+		{
+			src:  "Price - 1 - 2 > 8 / 4 / 2 -> BUY",
+			want: "(((Price - 1) - 2) > ((8 / 4) / 2))",
+		},
+		{
+			src:  "(Price - 1) * 2 > abs(change(Prices, 1)) -> BUY",
+			want: "(((Price - 1) * 2) > abs(change(Prices, 1)))",
+		},
+		{
+			src:  "avg(HurstSeries, 5) + 0.1 >= last(HurstSeries) -> HOLD",
+			want: "((avg(HurstSeries, 5) + 0.1) >= last(HurstSeries))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			rule, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := tree(rule.condition); got != tt.want {
+				t.Errorf("tree = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSyntax(t *testing.T) {
+	tests := []struct {
+		src       string
+		action    string
+		condition string
+	}{
+		{src: "hurst > 0.6 and trendfactor > 0 -> buy", action: Buy, condition: "Hurst > 0.6 AND TrendFactor > 0"},
+		{src: "Hurst > 0.6 THEN LONG", action: Buy, condition: "Hurst > 0.6"},
+		{src: "Hurst < 0.4 → SHORT", action: Sell, condition: "Hurst < 0.4"},
+		{src: "RSI != 50 -> HOLD", action: Hold, condition: "RSI != 50"},
+		{src: "  RSI==50->SELL  ", action: Sell, condition: "RSI == 50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			rule, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if rule.Action != tt.action || rule.Condition() != tt.condition || rule.Source != tt.src {
+				t.Errorf("rule = %q %s from %q, want %q %s", rule.Condition(), rule.Action, rule.Source, tt.condition, tt.action)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		// Лексика и синтаксис
+		{name: "single equals", src: "RSI = 50 -> BUY", want: "use == or !="},
+		{name: "bang without equals", src: "RSI ! 50 -> BUY", want: "use == or !="},
+		{name: "unknown character", src: "RSI > 50 # BUY", want: `unexpected character '#'`},
+		{name: "missing arrow", src: "RSI > 50 BUY", want: "expected -> and action"},
+		{name: "missing action", src: "RSI > 50 ->", want: "end of rule: expected action"},
+		{name: "unknown action", src: "RSI > 50 -> PANIC", want: "expected action BUY, SELL or HOLD"},
+		{name: "trailing tokens", src: "RSI > 50 -> BUY now", want: `unexpected "now" after action`},
+		{name: "unclosed paren", src: "(RSI > 50 -> BUY", want: "expected )"},
+		{name: "empty condition", src: "-> BUY", want: `unexpected "->"`},
+		{name: "invalid number", src: "RSI > 1.2.3 -> BUY", want: `invalid number "1.2.3"`},
+		{name: "unknown field", src: "Volume > 1 -> BUY", want: `unknown field "Volume"`},
+		{name: "unknown function", src: "median(Prices) > 1 -> BUY", want: `unknown function "median"`},
+
+		// Типы
+		{name: "number condition", src: "Price -> BUY", want: "is not a comparison"},
+		{name: "arithmetic condition", src: "Price + 1 -> BUY", want: "is not a comparison"},
+		{name: "OR with a number", src: "NOT Hurst > 1 AND RSI < 2 OR Price -> BUY", want: "Price: expected comparison, got number"},
+		{name: "AND with a number", src: "Hurst > 1 AND 2 -> BUY", want: "2: expected comparison, got number"},
+		{name: "NOT of a number", src: "NOT Price -> BUY", want: "Price: expected comparison, got number"},
+		{name: "compare a comparison", src: "Price > (RSI > 1) -> BUY", want: "expected number, got comparison"},
+		{name: "arithmetic on a comparison", src: "(RSI > 1) + 1 > 0 -> BUY", want: "expected number, got comparison"},
+		{name: "negate a comparison", src: "-(RSI > 1) > 0 -> BUY", want: "expected number, got comparison"},
+		{name: "series in comparison", src: "Prices > 1 -> BUY", want: "Prices is a series, use a function"},
+		{name: "series in arithmetic", src: "HurstSeries * 2 > 1 -> BUY", want: "HurstSeries is a series"},
+		{name: "scalar as series argument", src: "last(Price) > 1 -> BUY", want: "first argument must be a series"},
+		{name: "abs of a series", src: "abs(Prices) > 1 -> BUY", want: "Prices is a series"},
+
+		// Число аргументов
+		{name: "last with count", src: "last(Prices, 1) > 1 -> BUY", want: "last: wrong number of arguments: 2"},
+		{name: "prev without steps", src: "prev(Prices) > 1 -> BUY", want: "prev: wrong number of arguments: 1"},
+		{name: "change without steps", src: "change(Prices) > 1 -> BUY", want: "change: wrong number of arguments: 1"},
+		{name: "avg without arguments", src: "avg() > 1 -> BUY", want: "avg: wrong number of arguments: 0"},
+		{name: "max with three arguments", src: "max(Prices, 1, 2) > 1 -> BUY", want: "max: wrong number of arguments: 3"},
+		{name: "abs with two arguments", src: "abs(Price, 1) > 1 -> BUY", want: "abs expects 1 argument, got 2"},
+		{name: "zero window", src: "avg(Prices, 0) > 1 -> BUY", want: "avg: window must be a positive integer"},
+		{name: "fractional window", src: "min(Prices, 2.5) > 1 -> BUY", want: "min: window must be a positive integer"},
+		{name: "negative steps", src: "prev(Prices, -1) > 1 -> BUY", want: "prev: steps back must be a positive integer"},
+		{name: "field as steps", src: "prev(Prices, RSI) > 1 -> BUY", want: "prev: steps back must be a positive integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.src)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %q, want error", tt.src, rule.Condition())
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.src, err, tt.want)
+			}
+		})
+	}
+}
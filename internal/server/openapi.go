@@ -0,0 +1,318 @@
+package server
+
+import (
+	"mamonolitmvp/internal/handlers/openapi"
+	"mamonolitmvp/internal/math/coefficients_calculation"
+	"mamonolitmvp/internal/models"
+	"net/http"
+)
+
+const apiVersion = "1.0.0"
+
+// apiDocument OpenAPI документ, который отдает /api/v1/openapi.json
+func apiDocument() map[string]any {
+	return openapi.Document(openapi.Info{
+		Title:   "mamonolitmvp API",
+		Version: apiVersion,
+	}, apiOperations())
+}
+
+// apiOperations описание маршрутов из registerRoutes для /api/v1/openapi.json
+func apiOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/sig/getSignals",
+			Summary:  "Сигналы, фрактальный и мультифрактальный анализ цены инструмента",
+			Tag:      "analyzer",
+			Query:    models.GetSignalsRequest{},
+			Response: models.GetSignalsResponse{},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/sig/indicators",
+			Summary: "Список доступных индикаторов и их параметров",
+			Tag:     "analyzer",
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/risk",
+			Summary:  "VaR, Expected Shortfall, просадка и коэффициенты доходности",
+			Tag:      "analyzer",
+			Query:    models.GetRiskRequest{},
+			Response: models.GetRiskResponse{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/correlations",
+			Summary:  "Матрица, скользящие корреляции и кластеризация инструментов",
+			Tag:      "analyzer",
+			Query:    models.GetCorrelationsRequest{},
+			Response: models.GetCorrelationsResponse{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/backtest",
+			Summary:  "Прогон стратегии по сигналам на истории свечей: кривая капитала, сделки и метрики риска",
+			Tag:      "analyzer",
+			Body:     models.BacktestRequest{},
+			Response: models.BacktestResponse{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/screener",
+			Summary:  "Сигналы по watchlist или фильтру каталога: рейтинг по полю сигнала и ошибки по инструментам",
+			Tag:      "analyzer",
+			Query:    models.ScreenerRequest{},
+			Response: models.ScreenerResponse{},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/rules",
+			Summary: "Правила стратегий",
+			Tag:     "rules",
+			Query: struct {
+				Strategy string `query:"strategy"`
+			}{},
+			Response: models.RulesResponse{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/rules/fields",
+			Summary:  "Поля сигнала и функции, доступные в правилах",
+			Tag:      "rules",
+			Response: models.RuleFieldsResponse{},
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/api/v1/rules/{strategy}/{name}",
+			Summary:  "Создание или замена правила стратегии",
+			Tag:      "rules",
+			Body:     models.PutRuleRequest{},
+			Response: models.RuleResponse{},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/api/v1/rules/{strategy}/{name}",
+			Summary: "Удаление правила стратегии",
+			Tag:     "rules",
+			Status:  http.StatusNoContent,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/rules/{strategy}/evaluate",
+			Summary:  "Решение BUY/SELL/HOLD стратегии по инструменту с объяснением сработавших условий",
+			Tag:      "rules",
+			Query:    models.GetCandlesRequest{},
+			Response: models.RuleEvaluationResponse{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/rules/{strategy}/evaluations",
+			Summary:  "Последние решения стратегии по инструментам, в том числе по расписанию",
+			Tag:      "rules",
+			Response: models.RuleEvaluationsResponse{},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/alerts",
+			Summary: "Оповещения: все или по инструменту",
+			Tag:     "alerts",
+			Query: struct {
+				InstrumentId string `query:"instrumentId"`
+			}{},
+			Response: models.AlertsResponse{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/alerts",
+			Summary:  "Создание оповещения о смене режима, пересечении порога Hurst или SMA",
+			Tag:      "alerts",
+			Status:   http.StatusCreated,
+			Body:     models.AlertRequest{},
+			Response: models.AlertResponse{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/alerts/{id}",
+			Summary:  "Оповещение и состояние последней проверки",
+			Tag:      "alerts",
+			Response: models.AlertResponse{},
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/api/v1/alerts/{id}",
+			Summary:  "Замена определения оповещения; состояние проверок сбрасывается",
+			Tag:      "alerts",
+			Body:     models.AlertRequest{},
+			Response: models.AlertResponse{},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/api/v1/alerts/{id}",
+			Summary: "Удаление оповещения и его срабатываний",
+			Tag:     "alerts",
+			Status:  http.StatusNoContent,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/alerts/{id}/events",
+			Summary:  "Последние срабатывания оповещения, в том числе подавленные паузой",
+			Tag:      "alerts",
+			Query:    models.ListAlertEventsRequest{},
+			Response: models.AlertEventsResponse{},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/alerts/{id}/test",
+			Summary: "Пробное сообщение в канал оповещения",
+			Tag:     "alerts",
+			Status:  http.StatusNoContent,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/fundamentals",
+			Summary: "Последние фундаментальные показатели и мультипликаторы инструмента",
+			Tag:     "etl",
+			Query: struct {
+				InstrumentId string `query:"instrumentId"`
+			}{},
+			Response: struct {
+				InstrumentID string                                  `json:"instrumentId"`
+				Fundamentals models.AssetFundamental                 `json:"fundamentals"`
+				Ratios       coefficients_calculation.FinancialRatio `json:"ratios"`
+			}{},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/fundamentals/sync",
+			Summary: "Загрузка фундаментальных показателей активов из Tinkoff",
+			Tag:     "etl",
+			Body:    models.GetAssetFundamentalsRequest{},
+			Response: struct {
+				Fundamentals []models.AssetFundamental `json:"fundamentals"`
+			}{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/instruments",
+			Summary:  "Поиск по каталогу инструментов, загруженному синхронизацией",
+			Tag:      "instruments",
+			Query:    models.ListInstrumentsRequest{},
+			Response: models.InstrumentsPage{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/instruments/sync",
+			Summary:  "Обновление каталога инструментов из Tinkoff",
+			Tag:      "instruments",
+			Query:    models.SyncInstrumentsRequest{},
+			Response: models.SyncInstrumentsResponse{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/instruments/{uid}",
+			Summary:  "Инструмент из базы или из Tinkoff, если его нет в базе",
+			Tag:      "instruments",
+			Response: models.Instrument{},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/instruments/{uid}/candles",
+			Summary: "Свечи инструмента; недостающие диапазоны докачиваются из Tinkoff",
+			Tag:     "instruments",
+			Query: struct {
+				From     string `query:"from"`
+				To       string `query:"to"`
+				Interval string `query:"interval"`
+				models.Pagination
+			}{},
+			Response: models.CandlesPage{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/instruments/{uid}/close",
+			Summary:  "Цена закрытия инструмента из Tinkoff",
+			Tag:      "instruments",
+			Response: models.ClosePrice{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/instruments/{uid}/last-price",
+			Summary:  "Последняя цена инструмента из потока рыночных данных",
+			Tag:      "stream",
+			Response: models.LastPrice{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/instruments/{uid}/orderbook",
+			Summary:  "Последний стакан инструмента из потока рыночных данных",
+			Tag:      "stream",
+			Response: models.OrderBook{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/backfill",
+			Summary:  "Запуск фоновой загрузки истории свечей",
+			Tag:      "etl",
+			Body:     models.BackfillRequest{},
+			Status:   http.StatusAccepted,
+			Response: models.BackfillResponse{},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/backfill",
+			Summary: "Состояние загрузки истории свечей",
+			Tag:     "etl",
+			Query: struct {
+				InstrumentId string `query:"instrumentId"`
+				Interval     string `query:"interval"`
+			}{},
+			Response: models.BackfillResponse{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/jobs",
+			Summary:  "Задачи планировщика и их последние запуски",
+			Tag:      "jobs",
+			Response: models.JobsResponse{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/jobs/{name}/run",
+			Summary:  "Внеочередной запуск задачи",
+			Tag:      "jobs",
+			Status:   http.StatusAccepted,
+			Response: models.JobResponse{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/jobs/{name}/pause",
+			Summary:  "Приостановка запусков задачи по расписанию",
+			Tag:      "jobs",
+			Response: models.JobResponse{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/jobs/{name}/resume",
+			Summary:  "Возобновление запусков задачи по расписанию",
+			Tag:      "jobs",
+			Response: models.JobResponse{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/stream",
+			Summary:  "Состояние потока рыночных данных",
+			Tag:      "stream",
+			Response: models.StreamStatus{},
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/api/v1/stream/subscriptions",
+			Summary:  "Замена подписок потока рыночных данных",
+			Tag:      "stream",
+			Body:     models.StreamSubscriptions{},
+			Response: models.StreamStatus{},
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "перезаписать golden-файлы")
+
+// TestOpenAPIGolden сверяет документ с testdata/openapi.golden.json;
+// после намеренного изменения API: go test ./internal/server -run OpenAPIGolden -update
+func TestOpenAPIGolden(t *testing.T) {
+	got, err := json.MarshalIndent(apiDocument(), "", "  ")
+	if err != nil {
+		t.Fatalf("marshal document: %v", err)
+	}
+	got = append(got, '\n')
+
+	golden := filepath.Join("testdata", "openapi.golden.json")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("OpenAPI document differs from %s; rerun with -update if the change is intended", golden)
+	}
+}
@@ -9,25 +9,48 @@ import (
 	"log"
 	"mamonolitmvp/config"
 	"mamonolitmvp/internal/handlers/analyzer"
+	"mamonolitmvp/internal/handlers/etl"
+	"mamonolitmvp/internal/handlers/openapi"
+	"mamonolitmvp/internal/models"
 	"mamonolitmvp/internal/repository"
 	"mamonolitmvp/internal/storage/timescale"
+	"mamonolitmvp/pkg/notify"
+	"mamonolitmvp/pkg/scheduler"
+	"net"
 
 	//"mamonolitmvp/internal/repository"
 	"mamonolitmvp/internal/services"
 	//"mamonolitmvp/internal/storage/timescale"
 	"net/http"
+	"time"
 )
 
 type Server struct {
 	cfg *config.Config
 	e   *echo.Echo
 	db  *gorm.DB
+
+	scheduler *services.Scheduler
+	stream    *services.MarketDataStream
+	alerts    *services.AlertService
+
+	// ctx отменяется при остановке сервера: от него наследуются запросы и фоновые задачи
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func NewServer() *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := echo.New()
+	e.Server.BaseContext = func(net.Listener) context.Context {
+		return ctx
+	}
+
 	return &Server{
-		cfg: config.LoadConfig(),
-		e:   echo.New(),
+		cfg:    config.LoadConfig(),
+		e:      e,
+		ctx:    ctx,
+		cancel: cancel,
 	}
 }
 
@@ -42,7 +65,12 @@ func (s *Server) initializeMiddleware() {
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.e.Shutdown(ctx)
+	s.cancel()
+	err := s.e.Shutdown(ctx)
+	if s.scheduler != nil {
+		s.scheduler.Wait()
+	}
+	return err
 }
 
 func (s *Server) initializeDatabase() error {
@@ -61,19 +89,154 @@ func (s *Server) initializeRepository(db *gorm.DB) *repository.InstrumentReposit
 	return repository.NewInstrumentRepository(db)
 }
 
+// initializeScheduler регистрирует ночное обновление каталога, догрузку свечей watchlist по интервалам
+// и проверку правил стратегий по watchlist
+func (s *Server) initializeScheduler(service *services.TinkoffService, repo *repository.InstrumentRepository) *services.Scheduler {
+	jobs := services.NewScheduler(repo)
+	jitter := time.Duration(s.cfg.JobJitterSeconds) * time.Second
+
+	catalogueSchedule, err := scheduler.ParseCron(s.cfg.CatalogueSyncCron)
+	if err != nil {
+		log.Printf("invalid CATALOGUE_SYNC_CRON, catalogue sync is not scheduled: %v", err)
+	} else {
+		jobs.Add(services.CatalogueSyncJob(service, catalogueSchedule, jitter))
+	}
+
+	if len(s.cfg.Watchlist) > 0 {
+		for _, interval := range s.cfg.WatchlistIntervals {
+			job, err := services.CandleSyncJob(service, interval, s.cfg.Watchlist, jitter)
+			if err != nil {
+				log.Printf("invalid WATCHLIST_INTERVALS, candle sync is not scheduled: %v", err)
+				continue
+			}
+			jobs.Add(job)
+		}
+
+		rulesSchedule, err := scheduler.ParseCron(s.cfg.RulesCron)
+		if err != nil {
+			log.Printf("invalid RULES_CRON, rule evaluation is not scheduled: %v", err)
+		} else if job, err := services.RuleEvaluationJob(service, rulesSchedule, s.cfg.RulesInterval, s.cfg.Watchlist, jitter); err != nil {
+			log.Printf("invalid RULES_INTERVAL, rule evaluation is not scheduled: %v", err)
+		} else {
+			jobs.Add(job)
+		}
+	}
+
+	return jobs
+}
+
+// initializeStream подписывает поток рыночных данных на свечи, последние цены и стаканы watchlist
+func (s *Server) initializeStream(service *services.TinkoffService) *services.MarketDataStream {
+	var subscriptions models.StreamSubscriptions
+	for _, instrumentUID := range s.cfg.Watchlist {
+		subscriptions.Candles = append(subscriptions.Candles, models.CandleInstrument{
+			InstrumentId: instrumentUID,
+			Interval:     s.cfg.StreamCandleInterval,
+		})
+		subscriptions.OrderBooks = append(subscriptions.OrderBooks, models.OrderBookInstrument{
+			InstrumentId: instrumentUID,
+			Depth:        s.cfg.StreamOrderBookDepth,
+		})
+		subscriptions.LastPrices = append(subscriptions.LastPrices, models.LastPriceInstrument{
+			InstrumentId: instrumentUID,
+		})
+	}
+	if err := subscriptions.Validate(); err != nil {
+		log.Printf("invalid stream settings, stream starts without subscriptions: %v", err)
+		subscriptions = models.StreamSubscriptions{}
+	}
+
+	return services.NewMarketDataStream(service, s.cfg.StreamURL, subscriptions)
+}
+
+// initializeAlerts собирает настроенные каналы доставки; при ALERTS_ENABLED оповещения проверяются
+// после сохранения новых свечей
+func (s *Server) initializeAlerts(service *services.TinkoffService) *services.AlertService {
+	guard, err := notify.NewGuard(s.cfg.AlertWebhookAllow)
+	if err != nil {
+		log.Printf("invalid ALERT_WEBHOOK_ALLOW, webhooks are limited to public addresses: %v", err)
+		guard, _ = notify.NewGuard(nil)
+	}
+	sinks := map[string]notify.Sink{
+		models.SinkWebhook: notify.NewWebhook(guard),
+	}
+	if s.cfg.TelegramBotToken != "" {
+		sinks[models.SinkTelegram] = notify.NewTelegram(s.cfg.TelegramAPIURL, s.cfg.TelegramBotToken)
+	}
+	if s.cfg.SMTPAddr != "" {
+		sinks[models.SinkSMTP] = notify.NewSMTP(s.cfg.SMTPAddr, s.cfg.SMTPFrom, s.cfg.SMTPUsername, s.cfg.SMTPPassword)
+	}
+
+	alerts := services.NewAlertService(service, sinks)
+	if s.cfg.AlertsEnabled {
+		service.OnCandlesStored(alerts.CandlesStored)
+	}
+	return alerts
+}
+
 func (s *Server) registerRoutes(repo *repository.InstrumentRepository) {
 	service := services.NewTinkoffService(s.cfg, repo)
-	//etlHandler := etl.NewETLHandler(service)
+	instrumentHandler := etl.NewInstrumentHandler(service)
 	signalHandler := analyzer.NewSignalHandler(service)
+	riskHandler := analyzer.NewRiskHandler(service)
+	correlationHandler := analyzer.NewCorrelationHandler(service)
+	backtestHandler := analyzer.NewBacktestHandler(service)
+	rulesHandler := analyzer.NewRulesHandler(service)
+	screenerHandler := analyzer.NewScreenerHandler(service)
+	fundamentalsHandler := etl.NewFundamentalsHandler(service)
+	backfillHandler := etl.NewBackfillHandler(services.NewBackfillService(s.ctx, service))
+	s.scheduler = s.initializeScheduler(service, repo)
+	jobHandler := etl.NewJobHandler(s.scheduler)
+	s.stream = s.initializeStream(service)
+	streamHandler := etl.NewStreamHandler(s.stream)
+	s.alerts = s.initializeAlerts(service)
+	alertsHandler := analyzer.NewAlertsHandler(s.alerts)
 
-	//s.e.GET("/api/v1/ti/getClosePrices", etlHandler.GetClosePricesHandler)
-	//s.e.GET("/api/v1/ti/getBonds", etlHandler.GetAllBonds)
-	//s.e.GET("/api/v1/ti/getCandles", etlHandler.GetCandles)
 	s.e.GET("/api/v1/sig/getSignals", signalHandler.GetSignals)
+	s.e.GET("/api/v1/sig/indicators", signalHandler.GetIndicators)
+	s.e.GET("/api/v1/risk", riskHandler.GetRisk)
+	s.e.GET("/api/v1/correlations", correlationHandler.GetCorrelations)
+	s.e.POST("/api/v1/backtest", backtestHandler.RunBacktest)
+	s.e.GET("/api/v1/screener", screenerHandler.Screen)
+
+	s.e.GET("/api/v1/rules", rulesHandler.ListRules)
+	s.e.GET("/api/v1/rules/fields", rulesHandler.GetFields)
+	s.e.PUT("/api/v1/rules/:strategy/:name", rulesHandler.PutRule)
+	s.e.DELETE("/api/v1/rules/:strategy/:name", rulesHandler.DeleteRule)
+	s.e.GET("/api/v1/rules/:strategy/evaluate", rulesHandler.EvaluateRules)
+	s.e.GET("/api/v1/rules/:strategy/evaluations", rulesHandler.GetEvaluations)
 
-	//dbHandler := etl.NewDBHandler(instrumentRepository)
-	//s.e.GET("/api/v1/db/getInstrumentIDs", dbHandler.GetInstrumentUIDAndFigi)
-	//s.e.GET("/api/v1/db/getCandles", dbHandler.GetCandles)
+	s.e.GET("/api/v1/alerts", alertsHandler.ListAlerts)
+	s.e.POST("/api/v1/alerts", alertsHandler.CreateAlert)
+	s.e.GET("/api/v1/alerts/:id", alertsHandler.GetAlert)
+	s.e.PUT("/api/v1/alerts/:id", alertsHandler.UpdateAlert)
+	s.e.DELETE("/api/v1/alerts/:id", alertsHandler.DeleteAlert)
+	s.e.GET("/api/v1/alerts/:id/events", alertsHandler.GetEvents)
+	s.e.POST("/api/v1/alerts/:id/test", alertsHandler.TestAlert)
+
+	s.e.GET("/api/v1/fundamentals", fundamentalsHandler.GetFundamentals)
+	s.e.POST("/api/v1/fundamentals/sync", fundamentalsHandler.SyncFundamentals)
+
+	s.e.GET("/api/v1/instruments", instrumentHandler.ListInstruments)
+	s.e.POST("/api/v1/instruments/sync", instrumentHandler.SyncInstruments)
+	s.e.GET("/api/v1/instruments/:uid", instrumentHandler.GetInstrument)
+	s.e.GET("/api/v1/instruments/:uid/candles", instrumentHandler.GetCandles)
+	s.e.GET("/api/v1/instruments/:uid/close", instrumentHandler.GetClosePrice)
+	s.e.GET("/api/v1/instruments/:uid/last-price", streamHandler.GetLastPrice)
+	s.e.GET("/api/v1/instruments/:uid/orderbook", streamHandler.GetOrderBook)
+
+	s.e.POST("/api/v1/backfill", backfillHandler.StartBackfill)
+	s.e.GET("/api/v1/backfill", backfillHandler.GetBackfill)
+
+	s.e.GET("/api/v1/jobs", jobHandler.ListJobs)
+	s.e.POST("/api/v1/jobs/:name/run", jobHandler.RunJob)
+	s.e.POST("/api/v1/jobs/:name/pause", jobHandler.PauseJob)
+	s.e.POST("/api/v1/jobs/:name/resume", jobHandler.ResumeJob)
+
+	s.e.GET("/api/v1/stream", streamHandler.GetStream)
+	s.e.PUT("/api/v1/stream/subscriptions", streamHandler.SetSubscriptions)
+
+	s.e.GET("/api/v1/openapi.json", openapi.Handler(apiDocument()))
 
 	log.Printf("Server is running on port %s...", s.cfg.ServerPort)
 }
@@ -87,6 +250,15 @@ func (s *Server) Run() error {
 	s.initializeMiddleware()
 	initializeRepository := s.initializeRepository(s.db)
 	s.registerRoutes(initializeRepository)
+	if s.cfg.SchedulerEnabled {
+		s.scheduler.Start(s.ctx)
+	}
+	if s.cfg.StreamEnabled {
+		go s.stream.Run(s.ctx)
+	}
+	if s.cfg.AlertsEnabled {
+		go s.alerts.Run(s.ctx)
+	}
 
 	address := fmt.Sprintf(":%s", s.cfg.ServerPort)
 	return s.e.Start(address)
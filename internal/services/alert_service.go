@@ -0,0 +1,414 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/math/price_analysis"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/pkg/notify"
+	"sync"
+	"time"
+)
+
+const alertSendTimeout = 30 * time.Second
+
+// Состояния hurst_cross и sma_cross: значение выше или ниже порога (короткая SMA выше или ниже длинной)
+const (
+	alertAbove = "above"
+	alertBelow = "below"
+)
+
+// alertKey инструмент и интервал, по которым сохранены новые свечи
+type alertKey struct {
+	instrumentUID string
+	interval      string
+}
+
+// AlertService проверяет оповещения, когда по инструменту сохраняются новые свечи, и отправляет сработавшие
+// в каналы доставки. Проверки идут в одной горутине Run: сохранение свечей только ставит инструмент в очередь,
+// повторные постановки до проверки схлопываются.
+type AlertService struct {
+	tinkoff *TinkoffService
+	sinks   map[string]notify.Sink
+
+	mu      sync.Mutex
+	pending map[alertKey]struct{}
+	wake    chan struct{}
+}
+
+// NewAlertService sinks — настроенные каналы доставки по имени: webhook, telegram, smtp
+func NewAlertService(tinkoff *TinkoffService, sinks map[string]notify.Sink) *AlertService {
+	return &AlertService{
+		tinkoff: tinkoff,
+		sinks:   sinks,
+		pending: make(map[alertKey]struct{}),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// CandlesStored ставит инструмент в очередь проверки; регистрируется как CandlesHook
+func (a *AlertService) CandlesStored(instrumentUID, interval string) {
+	a.mu.Lock()
+	a.pending[alertKey{instrumentUID: instrumentUID, interval: interval}] = struct{}{}
+	a.mu.Unlock()
+
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run проверяет инструменты из очереди до отмены ctx
+func (a *AlertService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.wake:
+		}
+
+		a.mu.Lock()
+		pending := a.pending
+		a.pending = make(map[alertKey]struct{})
+		a.mu.Unlock()
+
+		for key := range pending {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := a.Evaluate(ctx, key.instrumentUID, key.interval); err != nil {
+				log.Printf("Alerts %s %s: %v", key.instrumentUID, key.interval, err)
+			}
+		}
+	}
+}
+
+// alertSnapshot значения на последней сохраненной свече, по которым проверяются оповещения
+type alertSnapshot struct {
+	ticker     string
+	candleTime time.Time
+	price      float64
+	regime     price_analysis.RegimeWindow
+	hurst      float64
+	shortSMA   float64
+	longSMA    float64
+}
+
+// Evaluate проверяет включенные оповещения инструмента по сохраненным свечам интервала за analysisWindow.
+// Оповещение, уже проверенное на последней свече, пропускается; первая проверка только запоминает состояние.
+func (a *AlertService) Evaluate(ctx context.Context, instrumentUID, interval string) error {
+	repo := a.tinkoff.is.instrumentRepository
+
+	all, err := repo.ListAlerts(ctx, instrumentUID, interval)
+	if err != nil {
+		return err
+	}
+	var alerts []models.Alert
+	for _, alert := range all {
+		if alert.Enabled {
+			alerts = append(alerts, alert)
+		}
+	}
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	snapshot, err := a.snapshot(ctx, instrumentUID, interval)
+	if err != nil {
+		return err
+	}
+
+	for _, alert := range alerts {
+		if !snapshot.candleTime.After(alert.LastCandleTime) {
+			continue
+		}
+		if err := a.check(ctx, alert, snapshot); err != nil {
+			log.Printf("Alert %d: %v", alert.Id, err)
+		}
+	}
+	return nil
+}
+
+// snapshot считает сигнал, скользящий анализ и режим только по свечам из базы: догрузка из Tinkoff
+// снова вызвала бы проверку. Незакрытая свеча из потока отбрасывается: ее цены еще изменятся, а проверка
+// на ней заняла бы LastCandleTime и закрытая версия той же свечи уже не проверилась бы.
+func (a *AlertService) snapshot(ctx context.Context, instrumentUID, interval string) (alertSnapshot, error) {
+	repo := a.tinkoff.is.instrumentRepository
+	pa := a.tinkoff.pa
+
+	window, err := analysisWindow(interval)
+	if err != nil {
+		return alertSnapshot{}, err
+	}
+	to := time.Now().UTC()
+	candles, err := repo.GetCandlesInRange(ctx, instrumentUID, interval, to.Add(-window), to)
+	if err != nil {
+		return alertSnapshot{}, err
+	}
+	for len(candles) > 0 && !candles[len(candles)-1].Closed(to) {
+		candles = candles[:len(candles)-1]
+	}
+
+	prices, times := closeSeries(candles)
+	if len(prices) < slidingWindow {
+		return alertSnapshot{}, fmt.Errorf("need at least %d candles for alerts, got %d", slidingWindow, len(prices))
+	}
+
+	sig, err := pa.TotalSignal(prices)
+	if err != nil {
+		return alertSnapshot{}, err
+	}
+	fdi, _, hurstSeries, err := pa.SlidingWindowAnalysis(ctx, prices, slidingWindow)
+	if err != nil {
+		return alertSnapshot{}, err
+	}
+	regimes, err := pa.ClassifyRegimes(hurstSeries, fdi, times[slidingWindow-1:], price_analysis.DefaultRegimeThresholds())
+	if err != nil {
+		return alertSnapshot{}, err
+	}
+
+	ticker, err := repo.GetTicker(ctx, instrumentUID)
+	if err != nil || ticker == "" {
+		ticker = instrumentUID
+	}
+
+	return alertSnapshot{
+		ticker:     ticker,
+		candleTime: times[len(times)-1],
+		price:      prices[len(prices)-1],
+		regime:     regimes.Current,
+		hurst:      hurstSeries[len(hurstSeries)-1],
+		shortSMA:   sig.ShortSMA[len(sig.ShortSMA)-1],
+		longSMA:    sig.LongSMA[len(sig.LongSMA)-1],
+	}, nil
+}
+
+// check сравнивает состояние оповещения с прошлой проверкой и при срабатывании отправляет сообщение.
+// Срабатывание во время паузы после предыдущего записывается, но не отправляется. Если доставка не удалась,
+// LastState остается прежним: на следующей свече смена состояния сработает снова и сообщение уйдет повторно.
+func (a *AlertService) check(ctx context.Context, alert models.Alert, snapshot alertSnapshot) error {
+	repo := a.tinkoff.is.instrumentRepository
+	now := time.Now().UTC()
+
+	from, to := alert.LastState, alertState(alert, snapshot)
+	alert.LastCandleTime = snapshot.candleTime
+	alert.LastEvaluatedAt = now
+
+	advance := true
+	if alertFires(alert, from, to) {
+		msg := alertMessage(alert, snapshot, from, to)
+		event := models.AlertEvent{
+			AlertId:    alert.Id,
+			CandleTime: snapshot.candleTime,
+			From:       from,
+			To:         to,
+			Message:    msg.Subject + "\n" + msg.Text,
+			Suppressed: alert.Cooldown(now),
+		}
+		if !event.Suppressed {
+			if err := a.send(ctx, alert, msg); err != nil {
+				event.Error = err.Error()
+				advance = false
+				log.Printf("Alert %d: delivery via %s failed, retry on next candle: %v", alert.Id, alert.Sink, err)
+			} else {
+				event.Delivered = true
+				alert.LastFiredAt = now
+			}
+		}
+		if err := repo.CreateAlertEvent(ctx, &event); err != nil {
+			log.Printf("Alert %d: failed to store event: %v", alert.Id, err)
+		}
+	}
+	if advance {
+		alert.LastState = to
+	}
+
+	return repo.SaveAlertState(ctx, alert)
+}
+
+func alertState(alert models.Alert, snapshot alertSnapshot) string {
+	switch alert.Kind {
+	case models.AlertRegimeChange:
+		return snapshot.regime.Regime
+	case models.AlertHurstCross:
+		if snapshot.hurst >= alert.Threshold {
+			return alertAbove
+		}
+	case models.AlertSMACross:
+		if snapshot.shortSMA > snapshot.longSMA {
+			return alertAbove
+		}
+	}
+	return alertBelow
+}
+
+// alertFires срабатывание — смена состояния, подходящая под режим или направление оповещения
+func alertFires(alert models.Alert, from, to string) bool {
+	if from == "" || from == to {
+		return false
+	}
+	if alert.Kind == models.AlertRegimeChange {
+		return alert.Regime == "" || alert.Regime == to
+	}
+	switch alert.Direction {
+	case models.DirectionUp:
+		return to == alertAbove
+	case models.DirectionDown:
+		return to == alertBelow
+	}
+	return true
+}
+
+func alertMessage(alert models.Alert, snapshot alertSnapshot, from, to string) notify.Message {
+	var subject, detail string
+	switch alert.Kind {
+	case models.AlertRegimeChange:
+		subject = fmt.Sprintf("%s: regime %s -> %s", snapshot.ticker, from, to)
+		detail = fmt.Sprintf("Hurst %.3f, spectrum width %.3f, confidence %.2f",
+			snapshot.regime.Hurst, snapshot.regime.Width, snapshot.regime.Confidence)
+	case models.AlertHurstCross:
+		subject = fmt.Sprintf("%s: Hurst crossed %s %.3f", snapshot.ticker, to, alert.Threshold)
+		detail = fmt.Sprintf("Hurst %.3f", snapshot.hurst)
+	case models.AlertSMACross:
+		subject = fmt.Sprintf("%s: short SMA crossed %s long SMA", snapshot.ticker, to)
+		detail = fmt.Sprintf("short SMA %.4f, long SMA %.4f", snapshot.shortSMA, snapshot.longSMA)
+	}
+	if alert.Name != "" {
+		subject = alert.Name + " — " + subject
+	}
+
+	return notify.Message{
+		Subject: subject,
+		Text: fmt.Sprintf("%s\nPrice %v, candle %s, %s",
+			detail, snapshot.price, snapshot.candleTime.Format(time.RFC3339), alert.Interval),
+		Time: snapshot.candleTime,
+		Fields: map[string]any{
+			"alertId":      alert.Id,
+			"instrumentId": alert.InstrumentId,
+			"ticker":       snapshot.ticker,
+			"interval":     alert.Interval,
+			"kind":         alert.Kind,
+			"from":         from,
+			"to":           to,
+			"price":        snapshot.price,
+			"hurst":        snapshot.hurst,
+			"regime":       snapshot.regime.Regime,
+			"shortSma":     snapshot.shortSMA,
+			"longSma":      snapshot.longSMA,
+		},
+	}
+}
+
+func (a *AlertService) send(ctx context.Context, alert models.Alert, msg notify.Message) error {
+	sink, ok := a.sinks[alert.Sink]
+	if !ok {
+		return fmt.Errorf("sink %q is not configured", alert.Sink)
+	}
+	ctx, cancel := context.WithTimeout(ctx, alertSendTimeout)
+	defer cancel()
+	return sink.Send(ctx, alert.Target, msg)
+}
+
+func (a *AlertService) ListAlerts(ctx context.Context, instrumentUID string) ([]models.Alert, error) {
+	return a.tinkoff.is.instrumentRepository.ListAlerts(ctx, instrumentUID, "")
+}
+
+func (a *AlertService) GetAlert(ctx context.Context, id uint) (models.Alert, error) {
+	return a.tinkoff.is.instrumentRepository.GetAlert(ctx, id)
+}
+
+func (a *AlertService) CreateAlert(ctx context.Context, req models.AlertRequest) (models.Alert, error) {
+	if err := a.checkSink(req.Sink, req.Target); err != nil {
+		return models.Alert{}, err
+	}
+	alert := req.Alert()
+	if err := a.tinkoff.is.instrumentRepository.SaveAlert(ctx, &alert); err != nil {
+		return models.Alert{}, err
+	}
+	return alert, nil
+}
+
+// UpdateAlert заменяет определение оповещения. Состояние сбрасывается: следующая свеча задаст новую
+// точку отсчета; пауза после последнего срабатывания сохраняется.
+func (a *AlertService) UpdateAlert(ctx context.Context, id uint, req models.AlertRequest) (models.Alert, error) {
+	if err := a.checkSink(req.Sink, req.Target); err != nil {
+		return models.Alert{}, err
+	}
+	repo := a.tinkoff.is.instrumentRepository
+	old, err := repo.GetAlert(ctx, id)
+	if err != nil {
+		return models.Alert{}, err
+	}
+
+	alert := req.Alert()
+	alert.Id = old.Id
+	alert.CreatedAt = old.CreatedAt
+	alert.LastFiredAt = old.LastFiredAt
+	if err := repo.SaveAlert(ctx, &alert); err != nil {
+		return models.Alert{}, err
+	}
+	return alert, nil
+}
+
+func (a *AlertService) DeleteAlert(ctx context.Context, id uint) error {
+	return a.tinkoff.is.instrumentRepository.DeleteAlert(ctx, id)
+}
+
+func (a *AlertService) ListAlertEvents(ctx context.Context, id uint, req models.ListAlertEventsRequest) ([]models.AlertEvent, error) {
+	repo := a.tinkoff.is.instrumentRepository
+	if _, err := repo.GetAlert(ctx, id); err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = models.DefaultPageLimit
+	}
+	return repo.ListAlertEvents(ctx, id, limit)
+}
+
+// TestAlert отправляет пробное сообщение получателю оповещения; ошибка доставки — ошибка внешнего сервиса
+func (a *AlertService) TestAlert(ctx context.Context, id uint) error {
+	repo := a.tinkoff.is.instrumentRepository
+	alert, err := repo.GetAlert(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := a.checkSink(alert.Sink, alert.Target); err != nil {
+		return err
+	}
+	ticker, err := repo.GetTicker(ctx, alert.InstrumentId)
+	if err != nil || ticker == "" {
+		ticker = alert.InstrumentId
+	}
+
+	err = a.send(ctx, alert, notify.Message{
+		Subject: fmt.Sprintf("%s: test notification", ticker),
+		Text:    fmt.Sprintf("Alert %d (%s, %s) is delivered via %s", alert.Id, alert.Kind, alert.Interval, alert.Sink),
+		Time:    time.Now().UTC(),
+		Fields: map[string]any{
+			"alertId":      alert.Id,
+			"instrumentId": alert.InstrumentId,
+			"test":         true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", apperr.ErrInternal, alert.Sink, err)
+	}
+	return nil
+}
+
+// checkSink канал настроен на сервере и принимает получателя: адрес webhook не ведет во внутреннюю сеть
+func (a *AlertService) checkSink(sink, target string) error {
+	s, ok := a.sinks[sink]
+	if !ok {
+		return apperr.InvalidArgument("sink %q is not configured on the server", sink)
+	}
+	if checker, ok := s.(notify.TargetChecker); ok {
+		if err := checker.CheckTarget(target); err != nil {
+			return apperr.InvalidArgument("target: %v", err)
+		}
+	}
+	return nil
+}
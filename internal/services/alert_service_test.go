@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/math/price_analysis"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/pkg/notify"
+)
+
+// recordingSink запоминает отправленные сообщения; err — ошибка доставки
+type recordingSink struct {
+	sent []notify.Message
+	err  error
+}
+
+func (s *recordingSink) Send(_ context.Context, _ string, msg notify.Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func smaAlert(repo *memRepository, state string) models.Alert {
+	alert := models.Alert{
+		Id:           1,
+		InstrumentId: "uid-1",
+		Interval:     "CANDLE_INTERVAL_HOUR",
+		Kind:         models.AlertSMACross,
+		Direction:    models.DirectionBoth,
+		Sink:         models.SinkWebhook,
+		Target:       "https://example.com/hook",
+		Enabled:      true,
+		LastState:    state,
+	}
+	repo.alerts[alert.Id] = alert
+	return alert
+}
+
+func TestAlertCheckRedeliversAfterFailure(t *testing.T) {
+	repo := newMemRepository()
+	sink := &recordingSink{err: errors.New("connection refused")}
+	alerts := NewAlertService(newTestService(repo), map[string]notify.Sink{models.SinkWebhook: sink})
+	ctx := context.Background()
+
+	alert := smaAlert(repo, alertBelow)
+	first := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	cross := alertSnapshot{ticker: "T", candleTime: first, shortSMA: 2, longSMA: 1}
+
+	if err := alerts.check(ctx, alert, cross); err != nil {
+		t.Fatal(err)
+	}
+	stored := repo.alerts[alert.Id]
+	if stored.LastState != alertBelow {
+		t.Fatalf("LastState = %q after failed delivery, want %q", stored.LastState, alertBelow)
+	}
+	if !stored.LastCandleTime.Equal(first) || !stored.LastFiredAt.IsZero() {
+		t.Fatalf("LastCandleTime = %v, LastFiredAt = %v", stored.LastCandleTime, stored.LastFiredAt)
+	}
+	if len(repo.events) != 1 || repo.events[0].Delivered || repo.events[0].Error == "" {
+		t.Fatalf("events = %+v, want one failed event", repo.events)
+	}
+
+	// Следующая свеча: состояние то же, доставка восстановилась — сообщение уходит повторно
+	sink.err = nil
+	cross.candleTime = first.Add(time.Hour)
+	if err := alerts.check(ctx, stored, cross); err != nil {
+		t.Fatal(err)
+	}
+	stored = repo.alerts[alert.Id]
+	if stored.LastState != alertAbove || stored.LastFiredAt.IsZero() {
+		t.Fatalf("alert after redelivery = %+v", stored)
+	}
+	if len(sink.sent) != 1 || len(repo.events) != 2 || !repo.events[1].Delivered {
+		t.Fatalf("sent %d, events %+v", len(sink.sent), repo.events)
+	}
+}
+
+func TestAlertCheckSuppressedAdvancesState(t *testing.T) {
+	repo := newMemRepository()
+	sink := &recordingSink{}
+	alerts := NewAlertService(newTestService(repo), map[string]notify.Sink{models.SinkWebhook: sink})
+
+	alert := smaAlert(repo, alertBelow)
+	alert.CooldownSeconds = 3600
+	alert.LastFiredAt = time.Now().UTC().Add(-time.Minute)
+	snapshot := alertSnapshot{ticker: "T", candleTime: time.Now().UTC(), shortSMA: 2, longSMA: 1}
+
+	if err := alerts.check(context.Background(), alert, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.alerts[alert.Id].LastState; got != alertAbove {
+		t.Fatalf("LastState = %q, want %q", got, alertAbove)
+	}
+	if len(sink.sent) != 0 || len(repo.events) != 1 || !repo.events[0].Suppressed {
+		t.Fatalf("sent %d, events %+v", len(sink.sent), repo.events)
+	}
+}
+
+// hourlyCandles n часовых свечей, последняя открыта в last
+func hourlyCandles(instrumentUID string, last time.Time, n int) []models.Candle {
+	rng := rand.New(rand.NewSource(1))
+	candles := make([]models.Candle, n)
+	price := 100.0
+	for i := range candles {
+		price *= math.Exp(rng.NormFloat64() * 0.01)
+		candles[i] = models.Candle{
+			InstrumentId: instrumentUID,
+			Interval:     "CANDLE_INTERVAL_HOUR",
+			Time:         last.Add(time.Duration(i-n+1) * time.Hour),
+			Open:         price, High: price, Low: price, Close: price, Volume: 1,
+		}
+	}
+	return candles
+}
+
+func TestAlertSnapshotSkipsFormingCandle(t *testing.T) {
+	repo := newMemRepository()
+	alerts := NewAlertService(newTestService(repo), nil)
+	ctx := context.Background()
+
+	forming := time.Now().UTC().Truncate(time.Hour)
+	if _, err := repo.CreateCandles(ctx, hourlyCandles("uid-1", forming, 2*slidingWindow)); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := alerts.snapshot(ctx, "uid-1", "CANDLE_INTERVAL_HOUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := forming.Add(-time.Hour); !snapshot.candleTime.Equal(want) {
+		t.Fatalf("snapshot candle = %v, want last closed %v", snapshot.candleTime, want)
+	}
+}
+
+func TestAlertFires(t *testing.T) {
+	tests := []struct {
+		name     string
+		alert    models.Alert
+		from, to string
+		want     bool
+	}{
+		{"first check only records state", models.Alert{Kind: models.AlertSMACross, Direction: models.DirectionBoth}, "", alertAbove, false},
+		{"same state", models.Alert{Kind: models.AlertSMACross, Direction: models.DirectionBoth}, alertAbove, alertAbove, false},
+		{"both up", models.Alert{Kind: models.AlertSMACross, Direction: models.DirectionBoth}, alertBelow, alertAbove, true},
+		{"both down", models.Alert{Kind: models.AlertHurstCross, Direction: models.DirectionBoth}, alertAbove, alertBelow, true},
+		{"up matches", models.Alert{Kind: models.AlertHurstCross, Direction: models.DirectionUp}, alertBelow, alertAbove, true},
+		{"up ignores down", models.Alert{Kind: models.AlertHurstCross, Direction: models.DirectionUp}, alertAbove, alertBelow, false},
+		{"down matches", models.Alert{Kind: models.AlertSMACross, Direction: models.DirectionDown}, alertAbove, alertBelow, true},
+		{"down ignores up", models.Alert{Kind: models.AlertSMACross, Direction: models.DirectionDown}, alertBelow, alertAbove, false},
+		{"any regime change", models.Alert{Kind: models.AlertRegimeChange},
+			price_analysis.RegimeTrending, price_analysis.RegimeRandomWalk, true},
+		{"regime filter matches", models.Alert{Kind: models.AlertRegimeChange, Regime: price_analysis.RegimeTurbulent},
+			price_analysis.RegimeTrending, price_analysis.RegimeTurbulent, true},
+		{"regime filter skips", models.Alert{Kind: models.AlertRegimeChange, Regime: price_analysis.RegimeTurbulent},
+			price_analysis.RegimeTrending, price_analysis.RegimeRandomWalk, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := alertFires(tt.alert, tt.from, tt.to); got != tt.want {
+				t.Fatalf("alertFires(%q -> %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertCooldown(t *testing.T) {
+	fired := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		alert models.Alert
+		now   time.Time
+		want  bool
+	}{
+		{"never fired", models.Alert{CooldownSeconds: 3600}, fired, false},
+		{"inside cooldown", models.Alert{CooldownSeconds: 3600, LastFiredAt: fired}, fired.Add(59 * time.Minute), true},
+		{"cooldown over", models.Alert{CooldownSeconds: 3600, LastFiredAt: fired}, fired.Add(time.Hour), false},
+		{"no cooldown", models.Alert{LastFiredAt: fired}, fired, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.alert.Cooldown(tt.now); got != tt.want {
+				t.Fatalf("Cooldown = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertEvaluateDedupesSameCandle(t *testing.T) {
+	repo := newMemRepository()
+	sink := &recordingSink{}
+	alerts := NewAlertService(newTestService(repo), map[string]notify.Sink{models.SinkWebhook: sink})
+	ctx := context.Background()
+
+	last := time.Now().UTC().Truncate(time.Hour).Add(-time.Hour)
+	if _, err := repo.CreateCandles(ctx, hourlyCandles("uid-1", last, 2*slidingWindow)); err != nil {
+		t.Fatal(err)
+	}
+	// Противоположное текущему состояние: первая проверка сработает
+	snapshot, err := alerts.snapshot(ctx, "uid-1", "CANDLE_INTERVAL_HOUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := alertBelow
+	if alertState(models.Alert{Kind: models.AlertSMACross}, snapshot) == alertBelow {
+		state = alertAbove
+	}
+	smaAlert(repo, state)
+
+	for range 2 {
+		if err := alerts.Evaluate(ctx, "uid-1", "CANDLE_INTERVAL_HOUR"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sink.sent) != 1 || len(repo.events) != 1 {
+		t.Fatalf("sent %d, events %d; repeated check on the same candle must not fire", len(sink.sent), len(repo.events))
+	}
+}
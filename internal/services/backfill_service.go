@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/models"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrBackfillRunning = errors.New("backfill is already running")
+
+// BackfillService загружает историю свечей инструмента кусками, допустимыми для интервала,
+// и после каждого куска сохраняет checkpoint, чтобы прерванная загрузка продолжилась с того же места
+type BackfillService struct {
+	tinkoff *TinkoffService
+	// base контекст фоновых загрузок: они переживают HTTP запрос, но останавливаются вместе с сервером
+	base context.Context
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+func NewBackfillService(base context.Context, tinkoff *TinkoffService) *BackfillService {
+	return &BackfillService{
+		tinkoff: tinkoff,
+		base:    base,
+		running: make(map[string]bool),
+	}
+}
+
+// Start запускает загрузку в фоне и возвращает начальное состояние
+func (b *BackfillService) Start(ctx context.Context, req models.BackfillRequest) (models.BackfillCheckpoint, error) {
+	checkpoint, err := b.prepare(ctx, req)
+	if err != nil {
+		return models.BackfillCheckpoint{}, err
+	}
+
+	go func() {
+		defer b.release(checkpoint)
+		if _, err := b.run(b.base, checkpoint); err != nil {
+			log.Printf("backfill %s %s failed: %v", checkpoint.InstrumentId, checkpoint.Interval, err)
+		}
+	}()
+
+	return checkpoint, nil
+}
+
+// Run выполняет загрузку синхронно
+func (b *BackfillService) Run(ctx context.Context, req models.BackfillRequest) (models.BackfillCheckpoint, error) {
+	checkpoint, err := b.prepare(ctx, req)
+	if err != nil {
+		return models.BackfillCheckpoint{}, err
+	}
+	defer b.release(checkpoint)
+
+	return b.run(ctx, checkpoint)
+}
+
+func (b *BackfillService) Status(ctx context.Context, instrumentUID, interval string) (models.BackfillCheckpoint, error) {
+	return b.tinkoff.is.instrumentRepository.GetCheckpoint(ctx, instrumentUID, interval)
+}
+
+// prepare восстанавливает checkpoint или создает новый от даты первой свечи инструмента
+func (b *BackfillService) prepare(ctx context.Context, req models.BackfillRequest) (models.BackfillCheckpoint, error) {
+	if _, ok := models.CandleIntervalLimits[req.Interval]; !ok {
+		return models.BackfillCheckpoint{}, apperr.InvalidArgument("unknown candle interval: %q", req.Interval)
+	}
+
+	to := time.Now().UTC()
+	if req.To != "" {
+		var err error
+		to, err = time.Parse(time.RFC3339, req.To)
+		if err != nil {
+			return models.BackfillCheckpoint{}, apperr.InvalidArgument("invalid to: %v", err)
+		}
+	}
+
+	key := req.InstrumentId + "/" + req.Interval
+	b.mu.Lock()
+	if b.running[key] {
+		b.mu.Unlock()
+		return models.BackfillCheckpoint{}, ErrBackfillRunning
+	}
+	b.running[key] = true
+	b.mu.Unlock()
+
+	repo := b.tinkoff.is.instrumentRepository
+	checkpoint, err := repo.GetCheckpoint(ctx, req.InstrumentId, req.Interval)
+	switch {
+	case err == nil:
+		log.Printf("Resume backfill %s %s from %s", req.InstrumentId, req.Interval, checkpoint.Cursor)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		from, err := b.firstCandleDate(ctx, req.InstrumentId, req.Interval)
+		if err != nil {
+			b.release(models.BackfillCheckpoint{InstrumentId: req.InstrumentId, Interval: req.Interval})
+			return models.BackfillCheckpoint{}, err
+		}
+		checkpoint = models.BackfillCheckpoint{
+			InstrumentId: req.InstrumentId,
+			Interval:     req.Interval,
+			From:         from,
+			Cursor:       from,
+		}
+	default:
+		b.release(models.BackfillCheckpoint{InstrumentId: req.InstrumentId, Interval: req.Interval})
+		return models.BackfillCheckpoint{}, err
+	}
+
+	checkpoint.To = to
+	checkpoint.Status = models.BackfillRunning
+	checkpoint.Error = ""
+	if err := repo.SaveCheckpoint(ctx, checkpoint); err != nil {
+		b.release(checkpoint)
+		return models.BackfillCheckpoint{}, err
+	}
+
+	return checkpoint, nil
+}
+
+func (b *BackfillService) release(checkpoint models.BackfillCheckpoint) {
+	b.mu.Lock()
+	delete(b.running, checkpoint.InstrumentId+"/"+checkpoint.Interval)
+	b.mu.Unlock()
+}
+
+// firstCandleDate дата первой минутной свечи для внутридневных интервалов и первой дневной для остальных
+func (b *BackfillService) firstCandleDate(ctx context.Context, instrumentUID, interval string) (time.Time, error) {
+	instrument, err := b.tinkoff.is.instrumentRepository.GetInstrument(ctx, instrumentUID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	from := instrument.First1minCandleDate
+	switch interval {
+	case "CANDLE_INTERVAL_DAY", "CANDLE_INTERVAL_WEEK", "CANDLE_INTERVAL_MONTH":
+		from = instrument.First1dayCandleDate
+	}
+
+	if from.IsZero() {
+		return time.Time{}, fmt.Errorf("instrument %s has no first candle date for %s", instrumentUID, interval)
+	}
+	return from.UTC(), nil
+}
+
+// run загружает непокрытые диапазоны [Cursor, To), сохраняя checkpoint после каждого куска
+func (b *BackfillService) run(ctx context.Context, checkpoint models.BackfillCheckpoint) (models.BackfillCheckpoint, error) {
+	repo := b.tinkoff.is.instrumentRepository
+	req := models.GetCandlesRequest{
+		InstrumentId: checkpoint.InstrumentId,
+		Interval:     checkpoint.Interval,
+	}
+
+	fail := func(err error) (models.BackfillCheckpoint, error) {
+		checkpoint.Status = models.BackfillFailed
+		checkpoint.Error = err.Error()
+		if saveErr := repo.SaveCheckpoint(ctx, checkpoint); saveErr != nil {
+			log.Printf("failed to save checkpoint: %v", saveErr)
+		}
+		return checkpoint, err
+	}
+
+	coverage, err := repo.GetCoverage(ctx, checkpoint.InstrumentId, checkpoint.Interval)
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, gap := range missingRanges(checkpoint.Cursor, checkpoint.To, coverage) {
+		chunks, err := splitRange(gap, checkpoint.Interval)
+		if err != nil {
+			return fail(err)
+		}
+
+		for _, chunk := range chunks {
+			coveredTo, stored, err := b.tinkoff.storeChunk(ctx, req, chunk)
+			if err != nil {
+				return fail(err)
+			}
+
+			checkpoint.Cursor = coveredTo
+			checkpoint.Chunks++
+			checkpoint.Candles += stored
+			if err := repo.SaveCheckpoint(ctx, checkpoint); err != nil {
+				return fail(err)
+			}
+
+			if coveredTo.Before(chunk.to) {
+				break
+			}
+		}
+	}
+
+	if checkpoint.Cursor.Before(checkpoint.To) && !checkpoint.To.After(time.Now()) {
+		checkpoint.Cursor = checkpoint.To
+	}
+	checkpoint.Status = models.BackfillDone
+	if err := repo.SaveCheckpoint(ctx, checkpoint); err != nil {
+		return checkpoint, err
+	}
+
+	log.Printf("Backfill %s %s done: %d chunks, %d candles", checkpoint.InstrumentId, checkpoint.Interval, checkpoint.Chunks, checkpoint.Candles)
+	return checkpoint, nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/models"
+)
+
+const backfillInterval = "CANDLE_INTERVAL_1_MIN"
+
+var backfillFrom = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestBackfillResumesFromCursor(t *testing.T) {
+	service, repo, fake := newMarketDataService(t)
+	cursor := backfillFrom.Add(24 * time.Hour)
+	to := backfillFrom.Add(72 * time.Hour)
+	_ = repo.SaveCheckpoint(context.Background(), models.BackfillCheckpoint{
+		InstrumentId: "uid",
+		Interval:     backfillInterval,
+		From:         backfillFrom,
+		To:           cursor,
+		Cursor:       cursor,
+		Chunks:       1,
+		Candles:      1,
+		Status:       models.BackfillFailed,
+		Error:        "previous run",
+	})
+
+	checkpoint, err := NewBackfillService(context.Background(), service).Run(context.Background(), models.BackfillRequest{
+		InstrumentId: "uid",
+		Interval:     backfillInterval,
+		To:           to.Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Два куска по дню от Cursor, загруженный ранее день не запрашивается
+	if len(fake.requests) != 2 {
+		t.Fatalf("requests = %+v, want two one-day chunks", fake.requests)
+	}
+	if fake.requests[0].From != cursor.Format(time.RFC3339Nano) {
+		t.Errorf("first request from %s, want cursor %s", fake.requests[0].From, cursor)
+	}
+	if !checkpoint.From.Equal(backfillFrom) || !checkpoint.Cursor.Equal(to) || !checkpoint.To.Equal(to) {
+		t.Errorf("checkpoint = %+v, want from kept and cursor at to", checkpoint)
+	}
+	if checkpoint.Status != models.BackfillDone || checkpoint.Error != "" || checkpoint.Chunks != 3 || checkpoint.Candles != 3 {
+		t.Errorf("checkpoint = %+v, want done with counters continued", checkpoint)
+	}
+	if saved, _ := repo.GetCheckpoint(context.Background(), "uid", backfillInterval); saved != checkpoint {
+		t.Errorf("saved checkpoint = %+v, want %+v", saved, checkpoint)
+	}
+}
+
+func TestBackfillFailedChunkKeepsCursor(t *testing.T) {
+	service, repo, fake := newMarketDataService(t)
+	repo.instruments["uid"] = models.Instrument{Uid: "uid", First1minCandleDate: backfillFrom}
+	secondDay := backfillFrom.Add(24 * time.Hour)
+	fake.fail = func(req models.GetCandlesRequest) bool {
+		return req.From == secondDay.Format(time.RFC3339Nano)
+	}
+
+	_, err := NewBackfillService(context.Background(), service).Run(context.Background(), models.BackfillRequest{
+		InstrumentId: "uid",
+		Interval:     backfillInterval,
+		To:           backfillFrom.Add(72 * time.Hour).Format(time.RFC3339),
+	})
+	if err == nil {
+		t.Fatal("Run succeeded with a failing chunk")
+	}
+
+	checkpoint, _ := repo.GetCheckpoint(context.Background(), "uid", backfillInterval)
+	if checkpoint.Status != models.BackfillFailed || checkpoint.Error == "" {
+		t.Errorf("checkpoint = %+v, want failed with the error", checkpoint)
+	}
+	if !checkpoint.Cursor.Equal(secondDay) || checkpoint.Chunks != 1 || checkpoint.Candles != 1 {
+		t.Errorf("checkpoint = %+v, want cursor after the first stored chunk", checkpoint)
+	}
+	if len(fake.requests) != 2 {
+		t.Errorf("requests = %d, want to stop at the failed chunk", len(fake.requests))
+	}
+}
+
+func TestBackfillRejectsConcurrentStart(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte(`{"candles":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	service, repo, _ := newMarketDataService(t)
+	service.Config.APIBaseURL = server.URL
+	repo.instruments["uid"] = models.Instrument{Uid: "uid", First1minCandleDate: backfillFrom}
+	backfill := NewBackfillService(context.Background(), service)
+	req := models.BackfillRequest{
+		InstrumentId: "uid",
+		Interval:     backfillInterval,
+		To:           backfillFrom.Add(time.Hour).Format(time.RFC3339),
+	}
+
+	checkpoint, err := backfill.Start(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if checkpoint.Status != models.BackfillRunning || !checkpoint.Cursor.Equal(backfillFrom) {
+		t.Errorf("checkpoint = %+v, want running from the first candle date", checkpoint)
+	}
+
+	if _, err := backfill.Start(context.Background(), req); !errors.Is(err, ErrBackfillRunning) {
+		t.Errorf("second Start error = %v, want ErrBackfillRunning", err)
+	}
+	close(release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		saved, _ := repo.GetCheckpoint(context.Background(), "uid", backfillInterval)
+		if saved.Status == models.BackfillDone {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("checkpoint = %+v, backfill did not finish", saved)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// После завершения загрузку можно запустить снова
+	for {
+		if _, err := backfill.Run(context.Background(), req); !errors.Is(err, ErrBackfillRunning) {
+			if err != nil {
+				t.Fatalf("Run after finish: %v", err)
+			}
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
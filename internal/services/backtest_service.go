@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/backtest"
+	"mamonolitmvp/internal/models"
+)
+
+const (
+	defaultInitialCash = 1_000_000
+	defaultLookback    = slidingWindow
+)
+
+// RunBacktest прогоняет SignalStrategy по свечам инструмента с лотом и шагом цены из каталога
+func (s *TinkoffService) RunBacktest(ctx context.Context, req models.BacktestRequest) (models.BacktestResponse, error) {
+	cfg, err := s.backtestConfig(req)
+	if err != nil {
+		return models.BacktestResponse{}, err
+	}
+
+	instrument, err := s.GetInstrument(ctx, req.InstrumentId)
+	if err != nil {
+		return models.BacktestResponse{}, err
+	}
+	cfg.Lot = max(instrument.Lot, 1)
+	cfg.MinPriceIncrement = instrument.MinPriceIncrement.Float64()
+	if cfg.SlippageTicks > 0 && cfg.MinPriceIncrement == 0 {
+		return models.BacktestResponse{}, apperr.InvalidArgument("instrument %s has no min price increment, slippageTicks must be 0", req.InstrumentId)
+	}
+
+	candles, err := s.LoadCandles(ctx, req.GetCandlesRequest)
+	if err != nil {
+		return models.BacktestResponse{}, err
+	}
+	if len(candles) <= cfg.Lookback {
+		return models.BacktestResponse{}, apperr.InvalidArgument("need more than %d candles for backtest, got %d", cfg.Lookback, len(candles))
+	}
+
+	bars := make([]backtest.Bar, len(candles))
+	for i, c := range candles {
+		bars[i] = backtest.Bar{Time: c.Time, Open: c.Open, High: c.High, Low: c.Low, Close: c.Close}
+	}
+
+	strategy := backtest.NewSignalStrategy()
+	if req.TrendThreshold != 0 {
+		strategy.TrendThreshold = req.TrendThreshold
+	}
+	if req.RSIOverbought != 0 {
+		strategy.RSIOverbought = req.RSIOverbought
+	}
+	if req.MinHurst != 0 {
+		strategy.MinHurst = req.MinHurst
+	}
+
+	result, err := backtest.NewEngine(s.pa, s.rm).Run(ctx, bars, strategy, cfg)
+	if err != nil {
+		return models.BacktestResponse{}, err
+	}
+
+	return models.BacktestResponse{
+		Ticker:            instrument.Ticker,
+		InstrumentId:      req.InstrumentId,
+		Interval:          req.Interval,
+		Lot:               cfg.Lot,
+		MinPriceIncrement: cfg.MinPriceIncrement,
+		Summary:           result.Summary,
+		Risk:              result.Risk,
+		Equity:            result.Equity,
+		Trades:            result.Trades,
+	}, nil
+}
+
+// backtestConfig параметры прогона с умолчаниями; лот и шаг цены заполняются из инструмента
+func (s *TinkoffService) backtestConfig(req models.BacktestRequest) (backtest.Config, error) {
+	cfg := backtest.Config{
+		InitialCash:    req.InitialCash,
+		Commission:     req.Commission,
+		SlippageTicks:  req.SlippageTicks,
+		Lookback:       req.Lookback,
+		Step:           req.Step,
+		Confidence:     req.Confidence,
+		RiskFreeRate:   req.RiskFreeRate,
+		PeriodsPerYear: req.PeriodsPerYear,
+	}
+	if cfg.InitialCash == 0 {
+		cfg.InitialCash = defaultInitialCash
+	}
+	if cfg.Lookback == 0 {
+		cfg.Lookback = defaultLookback
+	}
+	if cfg.Lookback < s.pa.LongSmaPeriod {
+		return backtest.Config{}, apperr.InvalidArgument("lookback must be at least %d candles, got %d", s.pa.LongSmaPeriod, cfg.Lookback)
+	}
+	if cfg.Step == 0 {
+		cfg.Step = 1
+	}
+	if cfg.Confidence == 0 {
+		cfg.Confidence = defaultConfidence
+	}
+	if cfg.PeriodsPerYear == 0 {
+		var ok bool
+		cfg.PeriodsPerYear, ok = periodsPerYear[req.Interval]
+		if !ok {
+			return backtest.Config{}, apperr.InvalidArgument("unknown candle interval: %q", req.Interval)
+		}
+	}
+	return cfg, nil
+}
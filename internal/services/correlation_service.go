@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/math/correlation_analysis"
+	"mamonolitmvp/internal/models"
+	"time"
+)
+
+// GetCorrelations считает корреляции по свечам, уже сохраненным в базе, без запросов в Tinkoff
+func (s *TinkoffService) GetCorrelations(ctx context.Context, req models.GetCorrelationsRequest) (correlation_analysis.CrossAsset, error) {
+	if len(req.InstrumentIds) < 2 {
+		return correlation_analysis.CrossAsset{}, apperr.InvalidArgument("at least two instruments required: %d", len(req.InstrumentIds))
+	}
+
+	if req.Interval == "" {
+		req.Interval = "CANDLE_INTERVAL_DAY"
+	}
+
+	from, err := time.Parse(time.RFC3339, req.From)
+	if err != nil {
+		return correlation_analysis.CrossAsset{}, apperr.InvalidArgument("invalid from: %v", err)
+	}
+	to, err := time.Parse(time.RFC3339, req.To)
+	if err != nil {
+		return correlation_analysis.CrossAsset{}, apperr.InvalidArgument("invalid to: %v", err)
+	}
+
+	series := make([]correlation_analysis.Series, 0, len(req.InstrumentIds))
+	for _, id := range req.InstrumentIds {
+		candles, err := s.is.instrumentRepository.GetCandlesInRange(ctx, id, req.Interval, from, to)
+		if err != nil {
+			return correlation_analysis.CrossAsset{}, fmt.Errorf("instrument %s: %w", id, err)
+		}
+
+		prices, times := closeSeries(candles)
+
+		series = append(series, correlation_analysis.Series{
+			ID:     id,
+			Times:  times,
+			Prices: prices,
+		})
+	}
+
+	return s.ca.TotalCorrelation(series, req.Method, req.Missing, req.Window, req.Step)
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mamonolitmvp/internal/math/coefficients_calculation"
+	"mamonolitmvp/internal/models"
+	"time"
+)
+
+// SyncFundamentals загружает фундаментальные показатели активов из Tinkoff и сохраняет их в базу
+func (s *TinkoffService) SyncFundamentals(ctx context.Context, assetUIDs []string) ([]models.AssetFundamental, error) {
+	reqBody := models.GetAssetFundamentalsRequest{Assets: assetUIDs}
+	url := fmt.Sprintf("%s/tinkoff.public.invest.api.contract.v1.InstrumentsService/GetAssetFundamentals", s.Config.APIBaseURL)
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + s.Config.APIToken,
+		"Content-Type":  "application/json",
+	}
+
+	respBody, err := s.Client.Post(ctx, url, headers, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.GetAssetFundamentalsResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for i := range response.Fundamentals {
+		response.Fundamentals[i].Time = now
+	}
+
+	err = s.is.instrumentRepository.CreateFundamentals(ctx, response.Fundamentals)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Fundamentals, nil
+}
+
+// GetFundamentals последние сохраненные показатели и коэффициенты актива инструмента
+func (s *TinkoffService) GetFundamentals(ctx context.Context, instrumentUID string) (models.AssetFundamental, coefficients_calculation.FinancialRatio, error) {
+	assetUID, err := s.is.instrumentRepository.GetAssetUID(ctx, instrumentUID)
+	if err != nil {
+		return models.AssetFundamental{}, coefficients_calculation.FinancialRatio{}, err
+	}
+
+	fundamental, err := s.is.instrumentRepository.GetFundamentals(ctx, assetUID)
+	if err != nil {
+		return models.AssetFundamental{}, coefficients_calculation.FinancialRatio{}, err
+	}
+
+	ratio := s.fr.TotalRatios(coefficients_calculation.Fundamentals{
+		MarketCapitalization: fundamental.MarketCapitalization,
+		SharesOutstanding:    fundamental.SharesOutstanding,
+		EpsTtm:               fundamental.EpsTtm,
+		NetIncomeTtm:         fundamental.NetIncomeTtm,
+		EbitdaTtm:            fundamental.EbitdaTtm,
+		EnterpriseValue:      fundamental.TotalEnterpriseValueMrq,
+		TotalDebt:            fundamental.TotalDebtMrq,
+		PeRatio:              fundamental.PeRatioTtm,
+		PriceToBook:          fundamental.PriceToBookTtm,
+		EvToEbitda:           fundamental.EvToEbitdaMrq,
+		Roe:                  fundamental.Roe,
+		DividendYield:        fundamental.DividendYieldDailyTtm,
+		DebtToEquity:         fundamental.TotalDebtToEquityMrq,
+	})
+
+	return fundamental, ratio, nil
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/pkg/http_client"
+
+	"gorm.io/gorm"
+)
+
+const (
+	sberAssetUID       = "40d89385-a03a-4659-bf4e-d3ecba011782"
+	emptyAssetUID      = "7ad9e9b0-3a0b-4e3f-9c3e-000000000002"
+	noDividendAssetUID = "b3f1c2d4-5e6f-4a7b-8c9d-000000000003"
+)
+
+// newFundamentalsService сервис с сервером GetAssetFundamentals, который отдает запрошенные активы
+// из testdata/get_asset_fundamentals.json
+func newFundamentalsService(t *testing.T) (*TinkoffService, *memRepository, *[]models.GetAssetFundamentalsRequest) {
+	t.Helper()
+	fixture, err := os.ReadFile("testdata/get_asset_fundamentals.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var assets map[string][]json.RawMessage
+	if err := json.Unmarshal(fixture, &assets); err != nil {
+		t.Fatal(err)
+	}
+
+	var requests []models.GetAssetFundamentalsRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tinkoff.public.invest.api.contract.v1.InstrumentsService/GetAssetFundamentals" {
+			http.NotFound(w, r)
+			return
+		}
+		var req models.GetAssetFundamentalsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, req)
+
+		// Поля ответа передаются как есть, чтобы отсутствующие показатели остались отсутствующими
+		var found []json.RawMessage
+		for _, raw := range assets["fundamentals"] {
+			var asset struct {
+				AssetUid string `json:"assetUid"`
+			}
+			_ = json.Unmarshal(raw, &asset)
+			if slices.Contains(req.Assets, asset.AssetUid) {
+				found = append(found, raw)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string][]json.RawMessage{"fundamentals": found})
+	}))
+	t.Cleanup(server.Close)
+
+	repo := newMemRepository()
+	repo.instruments["sber"] = models.Instrument{Uid: "sber", AssetUid: sberAssetUID}
+	repo.instruments["empty"] = models.Instrument{Uid: "empty", AssetUid: emptyAssetUID}
+	repo.instruments["no-dividend"] = models.Instrument{Uid: "no-dividend", AssetUid: noDividendAssetUID}
+	repo.instruments["no-asset"] = models.Instrument{Uid: "no-asset"}
+
+	service := newTestService(repo)
+	service.Client = http_client.NewHTTPClient()
+	service.Client.Retry.MaxRetries = 0
+	service.Config.APIBaseURL = server.URL
+	return service, repo, &requests
+}
+
+func TestSyncFundamentalsStoresFixture(t *testing.T) {
+	service, repo, requests := newFundamentalsService(t)
+
+	fundamentals, err := service.SyncFundamentals(context.Background(), []string{sberAssetUID, emptyAssetUID})
+	if err != nil {
+		t.Fatalf("SyncFundamentals: %v", err)
+	}
+
+	if len(*requests) != 1 || len((*requests)[0].Assets) != 2 {
+		t.Errorf("requests = %+v, want one request with both assets", *requests)
+	}
+	if len(fundamentals) != 2 || len(repo.fundamentals) != 2 {
+		t.Fatalf("fundamentals = %d, stored = %d, want 2", len(fundamentals), len(repo.fundamentals))
+	}
+	sber := repo.fundamentals[0]
+	if sber.AssetUid != sberAssetUID || *sber.MarketCapitalization != 6e12 || *sber.DividendYieldDailyTtm != 11.5 {
+		t.Errorf("stored = %+v, want fixture values", sber)
+	}
+	// Показатели, которых нет в ответе, не превращаются в нули
+	if sber.EbitdaTtm != nil || sber.PeRatioTtm != nil {
+		t.Errorf("stored EBITDA %v and P/E %v, want nil for absent fields", sber.EbitdaTtm, sber.PeRatioTtm)
+	}
+	if sber.Time.IsZero() {
+		t.Error("stored fundamentals have no load time")
+	}
+}
+
+func TestGetFundamentalsRatiosFromFixture(t *testing.T) {
+	service, _, _ := newFundamentalsService(t)
+	if _, err := service.SyncFundamentals(context.Background(), []string{sberAssetUID, emptyAssetUID}); err != nil {
+		t.Fatalf("SyncFundamentals: %v", err)
+	}
+
+	fundamental, ratio, err := service.GetFundamentals(context.Background(), "sber")
+	if err != nil {
+		t.Fatalf("GetFundamentals: %v", err)
+	}
+	if fundamental.AssetUid != sberAssetUID {
+		t.Errorf("asset = %q, want %q", fundamental.AssetUid, sberAssetUID)
+	}
+
+	// P/E и ROE досчитываются: капитализация 6e12, прибыль 1.5e12, P/B 1.2 -> капитал 5e12
+	tests := []struct {
+		name string
+		got  *float64
+		want float64
+	}{
+		{"PeRatio", ratio.PeRatio, 4},
+		{"PriceToBook", ratio.PriceToBook, 1.2},
+		{"Roe", ratio.Roe, 30},
+		{"DividendYield", ratio.DividendYield, 11.5},
+		{"DebtToEquity", ratio.DebtToEquity, 0.24},
+		{"EarningsYield", ratio.EarningsYield, 25},
+	}
+	for _, tt := range tests {
+		if tt.got == nil {
+			t.Errorf("%s = nil, want %v", tt.name, tt.want)
+			continue
+		}
+		if math.Abs(*tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", tt.name, *tt.got, tt.want)
+		}
+	}
+	// EBITDA не передана: EV/EBITDA не определен, а не равен нулю
+	if ratio.EvToEbitda != nil {
+		t.Errorf("EvToEbitda = %v, want nil without EBITDA", *ratio.EvToEbitda)
+	}
+
+	body, err := json.Marshal(ratio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var encoded map[string]float64
+	if err := json.Unmarshal(body, &encoded); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := encoded["EvToEbitda"]; ok || len(encoded) != len(tests) {
+		t.Errorf("encoded ratios = %s, want only defined ratios", body)
+	}
+}
+
+func TestGetFundamentalsOmitsUndefinedRatios(t *testing.T) {
+	service, _, _ := newFundamentalsService(t)
+	if _, err := service.SyncFundamentals(context.Background(), []string{emptyAssetUID}); err != nil {
+		t.Fatalf("SyncFundamentals: %v", err)
+	}
+
+	_, ratio, err := service.GetFundamentals(context.Background(), "empty")
+	if err != nil {
+		t.Fatalf("GetFundamentals: %v", err)
+	}
+	body, _ := json.Marshal(ratio)
+	if string(body) != "{}" {
+		t.Errorf("ratios = %s, want none without profit, shares and book value", body)
+	}
+}
+
+func TestGetFundamentalsKeepsZeroRatios(t *testing.T) {
+	service, _, _ := newFundamentalsService(t)
+	if _, err := service.SyncFundamentals(context.Background(), []string{noDividendAssetUID}); err != nil {
+		t.Fatalf("SyncFundamentals: %v", err)
+	}
+
+	_, ratio, err := service.GetFundamentals(context.Background(), "no-dividend")
+	if err != nil {
+		t.Fatalf("GetFundamentals: %v", err)
+	}
+
+	// Компания без дивидендов и без долга: нули из источника — значения, а не пропуски
+	body, _ := json.Marshal(ratio)
+	want := `{"PeRatio":8,"PriceToBook":2,"Roe":25,"DividendYield":0,"DebtToEquity":0,"EarningsYield":12.5}`
+	if string(body) != want {
+		t.Errorf("ratios = %s, want %s", body, want)
+	}
+}
+
+func TestGetFundamentalsNotFound(t *testing.T) {
+	service, _, _ := newFundamentalsService(t)
+
+	for _, instrumentUID := range []string{"no-asset", "sber", "unknown"} {
+		_, _, err := service.GetFundamentals(context.Background(), instrumentUID)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetFundamentals(%q) error = %v, want not found", instrumentUID, err)
+		}
+	}
+}
@@ -1,13 +1,38 @@
 package services
 
 import (
+	"context"
 	"mamonolitmvp/internal/models"
+	"time"
 )
 
 type InstrumentRepository interface {
-	CreateInstruments(instruments []models.PlacementPrice) error
-	GetTicker(instrumentUID string) (string, error)
-	CreateCandles(candles []models.HistoricCandle) error
+	CreateInstruments(ctx context.Context, instruments []models.Instrument) (models.UpsertStats, error)
+	GetTicker(ctx context.Context, instrumentUID string) (string, error)
+	CreateCandles(ctx context.Context, candles []models.Candle) (models.UpsertStats, error)
+	GetCandlesInRange(ctx context.Context, instrumentUID, interval string, from, to time.Time) ([]models.Candle, error)
+	ListCandles(ctx context.Context, instrumentUID, interval string, from, to time.Time, page models.Pagination) ([]models.Candle, int64, error)
+	GetCoverage(ctx context.Context, instrumentUID, interval string) ([]models.CandleCoverage, error)
+	AddCoverage(ctx context.Context, coverage models.CandleCoverage) error
+	GetInstrument(ctx context.Context, instrumentUID string) (models.Instrument, error)
+	ListInstruments(ctx context.Context, req models.ListInstrumentsRequest) ([]models.Instrument, int64, error)
+	GetCheckpoint(ctx context.Context, instrumentUID, interval string) (models.BackfillCheckpoint, error)
+	SaveCheckpoint(ctx context.Context, checkpoint models.BackfillCheckpoint) error
+	CreateFundamentals(ctx context.Context, fundamentals []models.AssetFundamental) error
+	GetAssetUID(ctx context.Context, instrumentUID string) (string, error)
+	GetFundamentals(ctx context.Context, assetUID string) (models.AssetFundamental, error)
+	ListRules(ctx context.Context, strategy string) ([]models.StrategyRule, error)
+	SaveRule(ctx context.Context, rule models.StrategyRule) error
+	DeleteRule(ctx context.Context, strategy, name string) error
+	SaveRuleEvaluation(ctx context.Context, evaluation models.RuleEvaluation) error
+	ListRuleEvaluations(ctx context.Context, strategy string) ([]models.RuleEvaluation, error)
+	ListAlerts(ctx context.Context, instrumentUID, interval string) ([]models.Alert, error)
+	GetAlert(ctx context.Context, id uint) (models.Alert, error)
+	SaveAlert(ctx context.Context, alert *models.Alert) error
+	SaveAlertState(ctx context.Context, alert models.Alert) error
+	DeleteAlert(ctx context.Context, id uint) error
+	CreateAlertEvent(ctx context.Context, event *models.AlertEvent) error
+	ListAlertEvents(ctx context.Context, alertID uint, limit int) ([]models.AlertEvent, error)
 }
 
 type InstrumentService struct {
@@ -20,10 +45,14 @@ func NewInstrumentService(instrumentRepository InstrumentRepository) *Instrument
 	}
 }
 
-func (s *InstrumentService) CreateInstruments(instruments []models.PlacementPrice) error {
-	return s.instrumentRepository.CreateInstruments(instruments)
+func (s *InstrumentService) CreateInstruments(ctx context.Context, instruments []models.Instrument) (models.UpsertStats, error) {
+	return s.instrumentRepository.CreateInstruments(ctx, instruments)
 }
 
-func (s *InstrumentService) CreateCandles(candles []models.HistoricCandle) error {
-	return s.instrumentRepository.CreateCandles(candles)
+func (s *InstrumentService) CreateCandles(ctx context.Context, candles []models.HistoricCandle) (models.UpsertStats, error) {
+	stored, err := models.ToCandles(candles)
+	if err != nil {
+		return models.UpsertStats{}, err
+	}
+	return s.instrumentRepository.CreateCandles(ctx, stored)
 }
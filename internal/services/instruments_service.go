@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ListInstruments отдает инструменты из базы. Пустой каталог отдается пустой страницей: его загружают
+// POST /api/v1/instruments/sync и задача планировщика.
+func (s *TinkoffService) ListInstruments(ctx context.Context, req models.ListInstrumentsRequest) (models.InstrumentsPage, error) {
+	req.Pagination = req.Pagination.WithDefaults()
+
+	instruments, total, err := s.is.instrumentRepository.ListInstruments(ctx, req)
+	if err != nil {
+		return models.InstrumentsPage{}, err
+	}
+
+	return models.InstrumentsPage{
+		Instruments: instruments,
+		Page:        req.Info(total),
+	}, nil
+}
+
+// GetInstrument отдает инструмент из базы, а если его там нет — из Tinkoff с сохранением
+func (s *TinkoffService) GetInstrument(ctx context.Context, instrumentUID string) (models.Instrument, error) {
+	instrument, err := s.is.instrumentRepository.GetInstrument(ctx, instrumentUID)
+	if err == nil {
+		return instrument, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Instrument{}, err
+	}
+
+	instrument, err = s.fetchInstrument(ctx, instrumentUID)
+	if err != nil {
+		return models.Instrument{}, err
+	}
+
+	if _, err := s.is.CreateInstruments(ctx, []models.Instrument{instrument}); err != nil {
+		return models.Instrument{}, err
+	}
+	return instrument, nil
+}
+
+func (s *TinkoffService) fetchInstrument(ctx context.Context, instrumentUID string) (models.Instrument, error) {
+	reqBody := models.GetInstrumentByRequest{
+		IdType: "INSTRUMENT_ID_TYPE_UID",
+		Id:     instrumentUID,
+	}
+	url := fmt.Sprintf("%s/tinkoff.public.invest.api.contract.v1.InstrumentsService/GetInstrumentBy", s.Config.APIBaseURL)
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + s.Config.APIToken,
+		"Content-Type":  "application/json",
+	}
+
+	respBody, err := s.Client.Post(ctx, url, headers, reqBody)
+	if err != nil {
+		return models.Instrument{}, err
+	}
+
+	var response models.GetInstrumentByResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return models.Instrument{}, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	// GetInstrumentBy отдает только общие поля, детали типа появятся после синхронизации каталога
+	return response.Instrument.ToBaseInstrument(models.NormalizeInstrumentType(response.Instrument.InstrumentType)), nil
+}
+
+// SyncInstruments загружает из Tinkoff инструменты указанных типов (по умолчанию всех) в каталог.
+// Повторная синхронизация обновляет только изменившиеся инструменты.
+func (s *TinkoffService) SyncInstruments(ctx context.Context, types []string) (map[string]models.UpsertStats, error) {
+	if len(types) == 0 {
+		types = models.InstrumentTypes
+	}
+
+	synced := make(map[string]models.UpsertStats, len(types))
+	for _, instrumentType := range types {
+		instruments, err := s.fetchInstruments(ctx, instrumentType)
+		if err != nil {
+			return synced, fmt.Errorf("sync %s: %w", instrumentType, err)
+		}
+
+		stats, err := s.is.CreateInstruments(ctx, instruments)
+		if err != nil {
+			return synced, fmt.Errorf("sync %s: %w", instrumentType, err)
+		}
+		synced[instrumentType] = stats
+	}
+	return synced, nil
+}
+
+func (s *TinkoffService) fetchInstruments(ctx context.Context, instrumentType string) ([]models.Instrument, error) {
+	method, ok := models.InstrumentMethods[instrumentType]
+	if !ok {
+		return nil, apperr.InvalidArgument("unknown instrument type: %q", instrumentType)
+	}
+
+	reqBody := models.InstrumentsRequest{InstrumentStatus: "INSTRUMENT_STATUS_BASE"}
+	url := fmt.Sprintf("%s/tinkoff.public.invest.api.contract.v1.InstrumentsService/%s", s.Config.APIBaseURL, method)
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + s.Config.APIToken,
+		"Content-Type":  "application/json",
+	}
+
+	respBody, err := s.Client.Post(ctx, url, headers, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.InstrumentsResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	instruments := make([]models.Instrument, 0, len(response.Instruments))
+	for _, instrument := range response.Instruments {
+		instruments = append(instruments, instrument.ToInstrument(instrumentType))
+	}
+	return instruments, nil
+}
+
+// GetInstrumentCandles страница свечей инструмента; недостающие диапазоны докачиваются из Tinkoff,
+// limit/offset применяются в запросе к базе
+func (s *TinkoffService) GetInstrumentCandles(ctx context.Context, req models.GetInstrumentCandlesRequest) (models.CandlesPage, error) {
+	req.Pagination = req.Pagination.WithDefaults()
+
+	from, to, err := s.syncCandles(ctx, req.GetCandlesRequest)
+	if err != nil {
+		return models.CandlesPage{}, err
+	}
+
+	candles, total, err := s.is.instrumentRepository.ListCandles(ctx, req.InstrumentId, req.Interval, from, to, req.Pagination)
+	if err != nil {
+		return models.CandlesPage{}, err
+	}
+
+	return models.CandlesPage{
+		InstrumentId: req.InstrumentId,
+		Interval:     req.Interval,
+		Candles:      candles,
+		Page:         req.Info(total),
+	}, nil
+}
+
+// GetClosePrice цена закрытия инструмента из Tinkoff
+func (s *TinkoffService) GetClosePrice(ctx context.Context, instrumentUID string) (models.ClosePrice, error) {
+	closePrices, err := s.GetClosePrices(ctx, []string{instrumentUID})
+	if err != nil {
+		return models.ClosePrice{}, err
+	}
+
+	for _, price := range closePrices {
+		if price.InstrumentUid == instrumentUID {
+			return price, nil
+		}
+	}
+	return models.ClosePrice{}, fmt.Errorf("%w: no close price for %s", apperr.ErrNotFound, instrumentUID)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/models"
+)
+
+const pageInterval = "CANDLE_INTERVAL_HOUR"
+
+// coveredRepository репозиторий с загруженным периодом [from, to) и свечами на каждый час из hours
+func coveredRepository(t *testing.T, from, to time.Time, hours ...int) *memRepository {
+	t.Helper()
+	repo := newMemRepository()
+	if err := repo.AddCoverage(context.Background(), models.CandleCoverage{
+		InstrumentId: "uid", Interval: pageInterval, From: from, To: to,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var candles []models.Candle
+	for _, h := range hours {
+		candles = append(candles, models.Candle{
+			InstrumentId: "uid", Interval: pageInterval,
+			Time: from.Add(time.Duration(h) * time.Hour), Close: float64(h),
+		})
+	}
+	if _, err := repo.CreateCandles(context.Background(), candles); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func candlesRequest(from, to time.Time, limit, offset int) models.GetInstrumentCandlesRequest {
+	return models.GetInstrumentCandlesRequest{
+		GetCandlesRequest: models.GetCandlesRequest{
+			InstrumentId: "uid",
+			Interval:     pageInterval,
+			From:         from.Format(time.RFC3339),
+			To:           to.Format(time.RFC3339),
+		},
+		Pagination: models.Pagination{Limit: limit, Offset: offset},
+	}
+}
+
+func TestGetInstrumentCandlesPaginates(t *testing.T) {
+	from := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	// Клиента Tinkoff нет: покрытый период не должен загружаться
+	service := newTestService(coveredRepository(t, from, to, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9))
+
+	tests := []struct {
+		name   string
+		limit  int
+		offset int
+		want   []float64
+	}{
+		{name: "first page", limit: 3, want: []float64{0, 1, 2}},
+		{name: "middle page", limit: 3, offset: 3, want: []float64{3, 4, 5}},
+		{name: "last partial page", limit: 3, offset: 9, want: []float64{9}},
+		{name: "beyond the end", limit: 3, offset: 20, want: []float64{}},
+		{name: "default limit", want: []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.GetInstrumentCandles(context.Background(), candlesRequest(from, to, tt.limit, tt.offset))
+			if err != nil {
+				t.Fatalf("GetInstrumentCandles: %v", err)
+			}
+			got := make([]float64, 0, len(page.Candles))
+			for _, c := range page.Candles {
+				got = append(got, c.Close)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("closes = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("closes = %v, want %v", got, tt.want)
+				}
+			}
+			if page.Page.Total != 10 || page.Page.Offset != tt.offset {
+				t.Errorf("page = %+v, want total 10 and offset %d", page.Page, tt.offset)
+			}
+		})
+	}
+}
+
+func TestGetInstrumentCandlesEmptyRange(t *testing.T) {
+	from := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	service := newTestService(coveredRepository(t, from, to))
+
+	page, err := service.GetInstrumentCandles(context.Background(), candlesRequest(from, to, 0, 0))
+	if err != nil {
+		t.Fatalf("GetInstrumentCandles on an empty range: %v", err)
+	}
+	if page.Page.Total != 0 || len(page.Candles) != 0 {
+		t.Errorf("page = %+v, want empty", page)
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"candles":[]`) {
+		t.Errorf("JSON %s, want an empty candles array", data)
+	}
+}
+
+func TestListInstrumentsEmptyCatalogue(t *testing.T) {
+	// Клиента Tinkoff нет: пустой каталог не должен синхронизироваться внутри GET
+	service := newTestService(newMemRepository())
+
+	page, err := service.ListInstruments(context.Background(), models.ListInstrumentsRequest{})
+	if err != nil {
+		t.Fatalf("ListInstruments: %v", err)
+	}
+	if len(page.Instruments) != 0 || page.Page.Total != 0 || page.Page.Limit != models.DefaultPageLimit {
+		t.Errorf("page = %+v, want an empty page with the default limit", page)
+	}
+}
+
+func TestListInstrumentsPage(t *testing.T) {
+	repo := newMemRepository()
+	for _, uid := range []string{"c", "a", "b"} {
+		repo.instruments[uid] = models.Instrument{Uid: uid}
+	}
+
+	page, err := newTestService(repo).ListInstruments(context.Background(), models.ListInstrumentsRequest{
+		Pagination: models.Pagination{Limit: 2, Offset: 1},
+	})
+	if err != nil {
+		t.Fatalf("ListInstruments: %v", err)
+	}
+	if len(page.Instruments) != 2 || page.Instruments[0].Uid != "b" || page.Instruments[1].Uid != "c" || page.Page.Total != 3 {
+		t.Errorf("page = %+v, want b and c of 3", page)
+	}
+}
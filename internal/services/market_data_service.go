@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/models"
+	"time"
+)
+
+// timeRange полуинтервал [from, to)
+type timeRange struct {
+	from time.Time
+	to   time.Time
+}
+
+// LoadCandles отдает свечи [from, to) из базы. Диапазоны, которые еще не загружались,
+// докачиваются из Tinkoff и сохраняются перед чтением.
+func (s *TinkoffService) LoadCandles(ctx context.Context, req models.GetCandlesRequest) ([]models.Candle, error) {
+	from, to, err := s.syncCandles(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.is.instrumentRepository.GetCandlesInRange(ctx, req.InstrumentId, req.Interval, from, to)
+}
+
+// syncCandles докачивает из Tinkoff части [from, to), которых нет в покрытии, и возвращает разобранный период.
+// Покрытие только решает, что загружать; свечи читаются из базы отдельно.
+func (s *TinkoffService) syncCandles(ctx context.Context, req models.GetCandlesRequest) (time.Time, time.Time, error) {
+	from, err := time.Parse(time.RFC3339, req.From)
+	if err != nil {
+		return time.Time{}, time.Time{}, apperr.InvalidArgument("invalid from: %v", err)
+	}
+	to, err := time.Parse(time.RFC3339, req.To)
+	if err != nil {
+		return time.Time{}, time.Time{}, apperr.InvalidArgument("invalid to: %v", err)
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, apperr.InvalidArgument("from must be before to: %s >= %s", req.From, req.To)
+	}
+
+	coverage, err := s.is.instrumentRepository.GetCoverage(ctx, req.InstrumentId, req.Interval)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	for _, gap := range missingRanges(from, to, coverage) {
+		if err := s.fillGap(ctx, req, gap); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	return from, to, nil
+}
+
+// fillGap загружает пропущенный диапазон кусками, допустимыми для интервала
+func (s *TinkoffService) fillGap(ctx context.Context, req models.GetCandlesRequest, gap timeRange) error {
+	chunks, err := splitRange(gap, req.Interval)
+	if err != nil {
+		return err
+	}
+
+	for _, chunk := range chunks {
+		coveredTo, _, err := s.storeChunk(ctx, req, chunk)
+		if err != nil {
+			return err
+		}
+		// Дальше только незавершенные или будущие свечи
+		if coveredTo.Before(chunk.to) {
+			break
+		}
+	}
+	return nil
+}
+
+// storeChunk загружает из Tinkoff свечи за диапазон, сохраняет завершенные и отмечает диапазон как покрытый.
+// Незавершенные свечи не сохраняются, покрытие обрезается по первой из них и по текущему времени.
+func (s *TinkoffService) storeChunk(ctx context.Context, req models.GetCandlesRequest, chunk timeRange) (time.Time, int, error) {
+	chunkReq := req
+	chunkReq.From = chunk.from.UTC().Format(time.RFC3339Nano)
+	chunkReq.To = chunk.to.UTC().Format(time.RFC3339Nano)
+
+	log.Printf("Fetch candles %s - %s for %s", chunkReq.From, chunkReq.To, req.InstrumentId)
+
+	candles, err := s.fetchCandles(ctx, chunkReq)
+	if err != nil {
+		return chunk.from, 0, err
+	}
+
+	coveredTo := chunk.to
+	if now := time.Now(); coveredTo.After(now) {
+		coveredTo = now
+	}
+
+	complete := make([]models.HistoricCandle, 0, len(candles))
+	for _, c := range candles {
+		if c.Time.Before(chunk.from) || !c.Time.Before(chunk.to) {
+			continue
+		}
+		if !c.IsComplete {
+			if c.Time.Before(coveredTo) {
+				coveredTo = c.Time
+			}
+			continue
+		}
+		complete = append(complete, c)
+	}
+
+	stored := complete[:0]
+	for _, c := range complete {
+		if c.Time.Before(coveredTo) {
+			stored = append(stored, c)
+		}
+	}
+
+	if len(stored) > 0 {
+		stats, err := s.is.CreateCandles(ctx, stored)
+		if err != nil {
+			return chunk.from, 0, err
+		}
+		s.candlesStored(req.InstrumentId, req.Interval, stats)
+	}
+
+	if !coveredTo.After(chunk.from) {
+		return chunk.from, len(stored), nil
+	}
+
+	err = s.is.instrumentRepository.AddCoverage(ctx, models.CandleCoverage{
+		InstrumentId: req.InstrumentId,
+		Interval:     req.Interval,
+		From:         chunk.from,
+		To:           coveredTo,
+	})
+	if err != nil {
+		return chunk.from, 0, err
+	}
+
+	return coveredTo, len(stored), nil
+}
+
+// splitRange делит диапазон на куски не длиннее ограничения GetCandles для интервала
+func splitRange(r timeRange, interval string) ([]timeRange, error) {
+	limit, ok := models.CandleIntervalLimits[interval]
+	if !ok {
+		return nil, apperr.InvalidArgument("unknown candle interval: %q", interval)
+	}
+
+	var chunks []timeRange
+	for cursor := r.from; cursor.Before(r.to); {
+		end := limit.Add(cursor)
+		if end.After(r.to) {
+			end = r.to
+		}
+		chunks = append(chunks, timeRange{from: cursor, to: end})
+		cursor = end
+	}
+	return chunks, nil
+}
+
+// fetchCandles запрос GetCandles в Tinkoff без сохранения результата. Период должен укладываться
+// в ограничение интервала: длинные диапазоны делит splitRange.
+func (s *TinkoffService) fetchCandles(ctx context.Context, reqBody models.GetCandlesRequest) ([]models.HistoricCandle, error) {
+	if err := reqBody.ValidateWithinLimit(); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/tinkoff.public.invest.api.contract.v1.MarketDataService/GetCandles", s.Config.APIBaseURL)
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + s.Config.APIToken,
+		"Content-Type":  "application/json",
+	}
+
+	respBody, err := s.Client.Post(ctx, url, headers, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	response, _, err := s.fixeRespBody(respBody, reqBody.InstrumentId, reqBody.Interval)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Candles, nil
+}
+
+// missingRanges части [from, to), не покрытые отсортированными по From диапазонами coverage
+func missingRanges(from, to time.Time, coverage []models.CandleCoverage) []timeRange {
+	var gaps []timeRange
+	cursor := from
+	for _, c := range coverage {
+		if !c.To.After(cursor) {
+			continue
+		}
+		if !c.From.Before(to) {
+			break
+		}
+		if c.From.After(cursor) {
+			gaps = append(gaps, timeRange{from: cursor, to: c.From})
+		}
+		cursor = c.To
+		if !cursor.Before(to) {
+			return gaps
+		}
+	}
+
+	if cursor.Before(to) {
+		gaps = append(gaps, timeRange{from: cursor, to: to})
+	}
+	return gaps
+}
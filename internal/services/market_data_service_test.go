@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/pkg/http_client"
+)
+
+// fakeGetCandles сервер GetCandles: запоминает запросы и отдает по завершенной свече на начало каждого запроса.
+// На запросы, для которых fail возвращает true, отвечает ошибкой InvalidArgument.
+type fakeGetCandles struct {
+	mu       sync.Mutex
+	requests []models.GetCandlesRequest
+	fail     func(req models.GetCandlesRequest) bool
+}
+
+func (f *fakeGetCandles) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req models.GetCandlesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+	if f.fail != nil && f.fail(req) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":3,"message":"invalid request","description":"30014"}`))
+		return
+	}
+
+	from, _ := time.Parse(time.RFC3339, req.From)
+	_, _ = fmt.Fprintf(w, `{"candles":[{"time":%q,"isComplete":true,"volume":"1","open":{"units":"1"},"high":{"units":"2"},"low":{"units":"1"},"close":{"units":"2"}}]}`,
+		from.Format(time.RFC3339))
+}
+
+func newMarketDataService(t *testing.T) (*TinkoffService, *memRepository, *fakeGetCandles) {
+	t.Helper()
+	fake := &fakeGetCandles{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	repo := newMemRepository()
+	service := newTestService(repo)
+	service.Client = http_client.NewHTTPClient()
+	service.Client.Retry.MaxRetries = 0
+	service.Config.APIBaseURL = server.URL
+	return service, repo, fake
+}
+
+func TestLoadCandlesSplitsRangeByIntervalLimit(t *testing.T) {
+	service, repo, fake := newMarketDataService(t)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(60 * time.Hour)
+	candles, err := service.LoadCandles(context.Background(), models.GetCandlesRequest{
+		InstrumentId: "uid",
+		Interval:     "CANDLE_INTERVAL_1_MIN",
+		From:         from.Format(time.RFC3339),
+		To:           to.Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatalf("LoadCandles: %v", err)
+	}
+
+	if len(fake.requests) != 3 {
+		t.Fatalf("requests = %d, want 3 chunks of at most one day", len(fake.requests))
+	}
+	for i, req := range fake.requests {
+		if err := req.ValidateWithinLimit(); err != nil {
+			t.Errorf("request %d %s - %s: %v", i, req.From, req.To, err)
+		}
+	}
+	if len(candles) != 3 {
+		t.Errorf("candles = %d, want one per chunk", len(candles))
+	}
+
+	coverage, _ := repo.GetCoverage(context.Background(), "uid", "CANDLE_INTERVAL_1_MIN")
+	if len(coverage) != 1 || !coverage[0].From.Equal(from) || !coverage[0].To.Equal(to) {
+		t.Errorf("coverage = %+v, want one range %s - %s", coverage, from, to)
+	}
+
+	// Повторная загрузка читает из базы
+	if _, err := service.LoadCandles(context.Background(), models.GetCandlesRequest{
+		InstrumentId: "uid",
+		Interval:     "CANDLE_INTERVAL_1_MIN",
+		From:         from.Format(time.RFC3339),
+		To:           to.Format(time.RFC3339),
+	}); err != nil {
+		t.Fatalf("second LoadCandles: %v", err)
+	}
+	if len(fake.requests) != 3 {
+		t.Errorf("requests after reload = %d, want no new requests", len(fake.requests))
+	}
+}
+
+func TestFetchCandlesRejectsRangeBeyondLimit(t *testing.T) {
+	service, _, fake := newMarketDataService(t)
+
+	_, err := service.fetchCandles(context.Background(), models.GetCandlesRequest{
+		InstrumentId: "uid",
+		Interval:     "CANDLE_INTERVAL_1_MIN",
+		From:         "2025-01-01T00:00:00Z",
+		To:           "2025-01-03T00:00:00Z",
+	})
+	if !errors.Is(err, apperr.ErrInvalidArgument) {
+		t.Fatalf("err = %v, want invalid argument", err)
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("sent %d requests for a range beyond the limit", len(fake.requests))
+	}
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"mamonolitmvp/config"
+	"mamonolitmvp/internal/math/coefficients_calculation"
+	"mamonolitmvp/internal/math/price_analysis"
+	"mamonolitmvp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// memRepository хранилище в памяти для тестов сервисов; методы, которые тест не задал, паникуют
+// через встроенный nil-интерфейс
+type memRepository struct {
+	InstrumentRepository
+
+	mu           sync.Mutex
+	candles      map[string][]models.Candle
+	instruments  map[string]models.Instrument
+	alerts       map[uint]models.Alert
+	events       []models.AlertEvent
+	coverage     map[string][]models.CandleCoverage
+	fundamentals []models.AssetFundamental
+	checkpoints  map[string]models.BackfillCheckpoint
+}
+
+func newMemRepository() *memRepository {
+	return &memRepository{
+		candles:     make(map[string][]models.Candle),
+		instruments: make(map[string]models.Instrument),
+		alerts:      make(map[uint]models.Alert),
+		coverage:    make(map[string][]models.CandleCoverage),
+		checkpoints: make(map[string]models.BackfillCheckpoint),
+	}
+}
+
+// newTestService TinkoffService поверх repo без клиента Tinkoff
+func newTestService(repo InstrumentRepository) *TinkoffService {
+	pa := price_analysis.NewPriceAnalysis()
+	pa.RSIPeriod = 14
+	return &TinkoffService{
+		Config: &config.Config{RSIInterval: 14, ScreenerWorkers: 2},
+		is:     NewInstrumentService(repo),
+		pa:     pa,
+		fr:     coefficients_calculation.NewFinancialRatios(),
+	}
+}
+
+func candleKey(instrumentUID, interval string) string {
+	return instrumentUID + "|" + interval
+}
+
+func (r *memRepository) CreateCandles(_ context.Context, candles []models.Candle) (models.UpsertStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var stats models.UpsertStats
+	for _, c := range candles {
+		key := candleKey(c.InstrumentId, c.Interval)
+		stored := r.candles[key]
+		i := slices.IndexFunc(stored, func(s models.Candle) bool { return s.Time.Equal(c.Time) })
+		switch {
+		case i < 0:
+			stored = append(stored, c)
+			stats.Inserted++
+		case stored[i] != c:
+			stored[i] = c
+			stats.Updated++
+		default:
+			stats.Unchanged++
+		}
+		slices.SortFunc(stored, func(a, b models.Candle) int { return a.Time.Compare(b.Time) })
+		r.candles[key] = stored
+	}
+	return stats, nil
+}
+
+func (r *memRepository) GetCandlesInRange(_ context.Context, instrumentUID, interval string, from, to time.Time) ([]models.Candle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []models.Candle
+	for _, c := range r.candles[candleKey(instrumentUID, interval)] {
+		if !c.Time.Before(from) && c.Time.Before(to) {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (r *memRepository) ListCandles(ctx context.Context, instrumentUID, interval string, from, to time.Time, page models.Pagination) ([]models.Candle, int64, error) {
+	candles, _ := r.GetCandlesInRange(ctx, instrumentUID, interval, from, to)
+	start := min(page.Offset, len(candles))
+	end := min(start+page.Limit, len(candles))
+	return append([]models.Candle{}, candles[start:end]...), int64(len(candles)), nil
+}
+
+func (r *memRepository) GetTicker(_ context.Context, instrumentUID string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.instruments[instrumentUID].Ticker, nil
+}
+
+func (r *memRepository) GetInstrument(_ context.Context, instrumentUID string) (models.Instrument, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	instrument, ok := r.instruments[instrumentUID]
+	if !ok {
+		return models.Instrument{}, gorm.ErrRecordNotFound
+	}
+	return instrument, nil
+}
+
+// ListInstruments каталог, отсортированный по uid; фильтры запроса не учитываются
+func (r *memRepository) ListInstruments(_ context.Context, req models.ListInstrumentsRequest) ([]models.Instrument, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	instruments := make([]models.Instrument, 0, len(r.instruments))
+	for _, instrument := range r.instruments {
+		instruments = append(instruments, instrument)
+	}
+	slices.SortFunc(instruments, func(a, b models.Instrument) int { return strings.Compare(a.Uid, b.Uid) })
+	total := int64(len(instruments))
+	instruments = instruments[min(req.Offset, len(instruments)):]
+	return instruments[:min(req.Limit, len(instruments))], total, nil
+}
+
+func (r *memRepository) ListAlerts(_ context.Context, instrumentUID, interval string) ([]models.Alert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []models.Alert
+	for _, alert := range r.alerts {
+		if alert.InstrumentId == instrumentUID && (interval == "" || alert.Interval == interval) {
+			result = append(result, alert)
+		}
+	}
+	return result, nil
+}
+
+func (r *memRepository) GetAlert(_ context.Context, id uint) (models.Alert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	alert, ok := r.alerts[id]
+	if !ok {
+		return models.Alert{}, gorm.ErrRecordNotFound
+	}
+	return alert, nil
+}
+
+func (r *memRepository) SaveAlertState(_ context.Context, alert models.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts[alert.Id] = alert
+	return nil
+}
+
+func (r *memRepository) CreateAlertEvent(_ context.Context, event *models.AlertEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event.Id = uint(len(r.events) + 1)
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *memRepository) GetCoverage(_ context.Context, instrumentUID, interval string) ([]models.CandleCoverage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.coverage[candleKey(instrumentUID, interval)]), nil
+}
+
+// AddCoverage склеивает диапазон с пересекающимися и смежными, как репозиторий
+func (r *memRepository) AddCoverage(_ context.Context, added models.CandleCoverage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := candleKey(added.InstrumentId, added.Interval)
+	var kept []models.CandleCoverage
+	for _, c := range r.coverage[key] {
+		if c.From.After(added.To) || c.To.Before(added.From) {
+			kept = append(kept, c)
+			continue
+		}
+		if c.From.Before(added.From) {
+			added.From = c.From
+		}
+		if c.To.After(added.To) {
+			added.To = c.To
+		}
+	}
+	kept = append(kept, added)
+	slices.SortFunc(kept, func(a, b models.CandleCoverage) int { return a.From.Compare(b.From) })
+	r.coverage[key] = kept
+	return nil
+}
+
+func (r *memRepository) CreateFundamentals(_ context.Context, fundamentals []models.AssetFundamental) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fundamentals = append(r.fundamentals, fundamentals...)
+	return nil
+}
+
+func (r *memRepository) GetAssetUID(_ context.Context, instrumentUID string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	assetUID := r.instruments[instrumentUID].AssetUid
+	if assetUID == "" {
+		return "", gorm.ErrRecordNotFound
+	}
+	return assetUID, nil
+}
+
+// GetFundamentals последние по Time показатели актива, как репозиторий
+func (r *memRepository) GetFundamentals(_ context.Context, assetUID string) (models.AssetFundamental, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *models.AssetFundamental
+	for i, f := range r.fundamentals {
+		if f.AssetUid == assetUID && (latest == nil || f.Time.After(latest.Time)) {
+			latest = &r.fundamentals[i]
+		}
+	}
+	if latest == nil {
+		return models.AssetFundamental{}, gorm.ErrRecordNotFound
+	}
+	return *latest, nil
+}
+
+func (r *memRepository) GetCheckpoint(_ context.Context, instrumentUID, interval string) (models.BackfillCheckpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	checkpoint, ok := r.checkpoints[candleKey(instrumentUID, interval)]
+	if !ok {
+		return models.BackfillCheckpoint{}, gorm.ErrRecordNotFound
+	}
+	return checkpoint, nil
+}
+
+func (r *memRepository) SaveCheckpoint(_ context.Context, checkpoint models.BackfillCheckpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkpoints[candleKey(checkpoint.InstrumentId, checkpoint.Interval)] = checkpoint
+	return nil
+}
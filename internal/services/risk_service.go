@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/math/coefficients_calculation"
+	"mamonolitmvp/internal/models"
+)
+
+const (
+	defaultConfidence = 0.95
+	tradingDays       = 252
+	tradingHours      = 9
+)
+
+// periodsPerYear число свечей интервала в торговом году
+var periodsPerYear = map[string]float64{
+	"CANDLE_INTERVAL_1_MIN":  tradingDays * tradingHours * 60,
+	"CANDLE_INTERVAL_2_MIN":  tradingDays * tradingHours * 30,
+	"CANDLE_INTERVAL_3_MIN":  tradingDays * tradingHours * 20,
+	"CANDLE_INTERVAL_5_MIN":  tradingDays * tradingHours * 12,
+	"CANDLE_INTERVAL_10_MIN": tradingDays * tradingHours * 6,
+	"CANDLE_INTERVAL_15_MIN": tradingDays * tradingHours * 4,
+	"CANDLE_INTERVAL_30_MIN": tradingDays * tradingHours * 2,
+	"CANDLE_INTERVAL_HOUR":   tradingDays * tradingHours,
+	"CANDLE_INTERVAL_2_HOUR": tradingDays * tradingHours / 2,
+	"CANDLE_INTERVAL_4_HOUR": tradingDays * tradingHours / 4,
+	"CANDLE_INTERVAL_DAY":    tradingDays,
+	"CANDLE_INTERVAL_WEEK":   52,
+	"CANDLE_INTERVAL_MONTH":  12,
+}
+
+func (s *TinkoffService) GetRisk(ctx context.Context, req models.GetRiskRequest) (string, coefficients_calculation.Risk, error) {
+	reqBody := req.GetCandlesRequest
+
+	candles, err := s.LoadCandles(ctx, reqBody)
+	if err != nil {
+		return "", coefficients_calculation.Risk{}, err
+	}
+
+	prices, times := closeSeries(candles)
+
+	confidence := req.Confidence
+	if confidence == 0 {
+		confidence = defaultConfidence
+	}
+
+	periods := req.PeriodsPerYear
+	if periods == 0 {
+		var ok bool
+		periods, ok = periodsPerYear[reqBody.Interval]
+		if !ok {
+			return "", coefficients_calculation.Risk{}, apperr.InvalidArgument("unknown candle interval: %q", reqBody.Interval)
+		}
+	}
+
+	risk, err := s.rm.TotalRisk(prices, times, confidence, req.RiskFreeRate, periods)
+	if err != nil {
+		return "", coefficients_calculation.Risk{}, err
+	}
+
+	ticker, err := s.is.instrumentRepository.GetTicker(ctx, reqBody.InstrumentId)
+	if err != nil {
+		return "", coefficients_calculation.Risk{}, err
+	}
+
+	return ticker, risk, nil
+}
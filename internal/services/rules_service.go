@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/math/price_analysis"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/internal/rules"
+	"time"
+)
+
+func (s *TinkoffService) ListRules(ctx context.Context, strategy string) ([]models.StrategyRule, error) {
+	return s.is.instrumentRepository.ListRules(ctx, strategy)
+}
+
+// PutRule создает или заменяет правило стратегии
+func (s *TinkoffService) PutRule(ctx context.Context, strategy, name string, req models.PutRuleRequest) (models.StrategyRule, error) {
+	rule := models.StrategyRule{
+		Strategy:    strategy,
+		Name:        name,
+		Expression:  req.Expression,
+		Priority:    req.Priority,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		Description: req.Description,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if err := s.is.instrumentRepository.SaveRule(ctx, rule); err != nil {
+		return models.StrategyRule{}, err
+	}
+	return rule, nil
+}
+
+func (s *TinkoffService) DeleteRule(ctx context.Context, strategy, name string) error {
+	return s.is.instrumentRepository.DeleteRule(ctx, strategy, name)
+}
+
+func (s *TinkoffService) ListRuleEvaluations(ctx context.Context, strategy string) ([]models.RuleEvaluation, error) {
+	return s.is.instrumentRepository.ListRuleEvaluations(ctx, strategy)
+}
+
+// EvaluateRules проверяет включенные правила стратегии на свечах инструмента и сохраняет решение
+// как последнее по инструменту и интервалу
+func (s *TinkoffService) EvaluateRules(ctx context.Context, strategy string, req models.GetCandlesRequest) (models.RuleEvaluation, error) {
+	all, err := s.is.instrumentRepository.ListRules(ctx, strategy)
+	if err != nil {
+		return models.RuleEvaluation{}, err
+	}
+	var enabled []models.StrategyRule
+	for _, rule := range all {
+		if rule.Enabled {
+			enabled = append(enabled, rule)
+		}
+	}
+	if len(enabled) == 0 {
+		return models.RuleEvaluation{}, fmt.Errorf("%w: strategy %q has no enabled rules", apperr.ErrNotFound, strategy)
+	}
+
+	env, err := s.ruleEnv(ctx, req)
+	if err != nil {
+		return models.RuleEvaluation{}, err
+	}
+
+	ticker, err := s.is.instrumentRepository.GetTicker(ctx, req.InstrumentId)
+	if err != nil {
+		return models.RuleEvaluation{}, err
+	}
+
+	// Период уже проверен при загрузке свечей
+	from, _ := time.Parse(time.RFC3339, req.From)
+	to, _ := time.Parse(time.RFC3339, req.To)
+
+	evaluation := models.RuleEvaluation{
+		Strategy:     strategy,
+		InstrumentId: req.InstrumentId,
+		Interval:     req.Interval,
+		Ticker:       ticker,
+		Action:       rules.Hold,
+		From:         from,
+		To:           to,
+		EvaluatedAt:  time.Now().UTC(),
+	}
+	for _, rule := range enabled {
+		result := evaluateRule(rule, env)
+		if result.Fired && evaluation.Rule == "" {
+			evaluation.Action = result.Action
+			evaluation.Rule = rule.Name
+		}
+		evaluation.Rules = append(evaluation.Rules, result)
+	}
+
+	// Решение уже посчитано: ошибка записи не должна его потерять
+	if err := s.is.instrumentRepository.SaveRuleEvaluation(ctx, evaluation); err != nil {
+		log.Printf("Rules %s: failed to store evaluation for %s: %v", strategy, req.InstrumentId, err)
+	}
+	return evaluation, nil
+}
+
+// evaluateRule правило, которое не разбирается или не вычисляется, считается не сработавшим
+func evaluateRule(rule models.StrategyRule, env *rules.Env) models.RuleResult {
+	result := models.RuleResult{
+		Name:       rule.Name,
+		Expression: rule.Expression,
+		Priority:   rule.Priority,
+	}
+
+	parsed, err := rules.Parse(rule.Expression)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Action = parsed.Action
+
+	result.Fired, result.Clauses, err = parsed.Eval(env)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// ruleEnv сигнал и скользящий анализ по свечам запроса
+func (s *TinkoffService) ruleEnv(ctx context.Context, req models.GetCandlesRequest) (*rules.Env, error) {
+	candles, err := s.LoadCandles(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, _ := closeSeries(candles)
+	if len(prices) < slidingWindow {
+		return nil, apperr.InvalidArgument("need at least %d candles for analysis, got %d", slidingWindow, len(prices))
+	}
+
+	sig, err := s.pa.TotalSignal(prices)
+	if err != nil {
+		return nil, err
+	}
+
+	fdi, normFdi, hurstSeries, err := s.pa.SlidingWindowAnalysis(ctx, prices, slidingWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules.NewEnv(prices, sig, price_analysis.SlidingWindow{FdiSeries: fdi, HurstSeries: hurstSeries}, normFdi), nil
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/pkg/scheduler"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+var (
+	ErrJobRunning  = errors.New("job is already running")
+	ErrJobNotFound = fmt.Errorf("job %w", apperr.ErrNotFound)
+)
+
+type JobStore interface {
+	GetJobStates(ctx context.Context) ([]models.JobState, error)
+	SaveJobState(ctx context.Context, state models.JobState) error
+}
+
+// Job задача планировщика
+type Job struct {
+	Name     string
+	Schedule scheduler.Schedule
+	// Jitter случайная задержка запуска до Jitter после времени по расписанию
+	Jitter time.Duration
+	Run    func(ctx context.Context) error
+}
+
+type scheduledJob struct {
+	Job
+	state models.JobState
+}
+
+// Scheduler запускает задачи по расписанию внутри процесса. Задача не запускается повторно,
+// пока не завершился предыдущий запуск; состояние задач сохраняется в базе.
+type Scheduler struct {
+	store JobStore
+
+	mu    sync.Mutex
+	jobs  map[string]*scheduledJob
+	order []string
+	// ctx контекст запусков, задается в Start
+	ctx  context.Context
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewScheduler(store JobStore) *Scheduler {
+	return &Scheduler{
+		store: store,
+		jobs:  make(map[string]*scheduledJob),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// Add регистрирует задачу; вызывается до Start
+func (s *Scheduler) Add(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[job.Name] = &scheduledJob{
+		Job:   job,
+		state: models.JobState{Name: job.Name, Schedule: fmt.Sprint(job.Schedule)},
+	}
+	s.order = append(s.order, job.Name)
+}
+
+// Start восстанавливает состояние задач и запускает планировщик до отмены ctx.
+// Запуск, пропущенный пока сервер был остановлен, выполняется сразу.
+func (s *Scheduler) Start(ctx context.Context) {
+	states, err := s.store.GetJobStates(ctx)
+	if err != nil {
+		log.Printf("failed to restore job states: %v", err)
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	s.ctx = ctx
+	for _, state := range states {
+		job, ok := s.jobs[state.Name]
+		if !ok {
+			continue
+		}
+		state.Schedule = job.state.Schedule
+		if state.LastStatus == models.JobRunning {
+			state.LastStatus = models.JobFailed
+			state.LastError = "interrupted by shutdown"
+		}
+		job.state = state
+	}
+	for _, name := range s.order {
+		job := s.jobs[name]
+		// Сохраненное время могло остаться от прежнего расписания
+		if next := s.next(job.Job, now); job.state.NextRunAt.IsZero() || next.Before(job.state.NextRunAt) {
+			job.state.NextRunAt = next
+		}
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.loop(ctx)
+}
+
+// Wait ожидает остановки планировщика после отмены контекста Start и завершения запущенных задач
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	defer s.wg.Done()
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		var wakeAt time.Time
+		var due []*scheduledJob
+		var states []models.JobState
+		for _, name := range s.order {
+			job := s.jobs[name]
+			if job.state.Paused || job.state.Running || job.state.NextRunAt.IsZero() {
+				continue
+			}
+			if !job.state.NextRunAt.After(now) {
+				due = append(due, job)
+				states = append(states, s.begin(job, true))
+				continue
+			}
+			if wakeAt.IsZero() || job.state.NextRunAt.Before(wakeAt) {
+				wakeAt = job.state.NextRunAt
+			}
+		}
+		s.mu.Unlock()
+
+		for i, job := range due {
+			s.save(states[i])
+			s.run(job, states[i].LastStartedAt)
+		}
+
+		// Без запланированных запусков ждем только пробуждения после паузы, ручного запуска или завершения
+		timer := time.NewTimer(time.Until(wakeAt))
+		if wakeAt.IsZero() {
+			timer.Stop()
+		}
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// next время следующего запуска по расписанию с джиттером
+func (s *Scheduler) next(job Job, after time.Time) time.Time {
+	next := job.Schedule.Next(after)
+	if job.Jitter > 0 && !next.IsZero() {
+		next = next.Add(rand.N(job.Jitter))
+	}
+	return next
+}
+
+// begin отмечает задачу запущенной и возвращает состояние для сохранения; вызывается под s.mu.
+// Пока задача отмечена, повторно она не запускается. При reschedule следующий запуск
+// переносится на время по расписанию после текущего.
+func (s *Scheduler) begin(job *scheduledJob, reschedule bool) models.JobState {
+	now := time.Now()
+	job.state.Running = true
+	job.state.LastStatus = models.JobRunning
+	job.state.LastStartedAt = now
+	if reschedule {
+		job.state.NextRunAt = s.next(job.Job, now)
+	}
+	return job.state
+}
+
+// run выполняет отмеченную задачу в фоне и сохраняет результат
+func (s *Scheduler) run(job *scheduledJob, now time.Time) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		log.Printf("Job %s started", job.Name)
+		err := job.Run(s.ctx)
+		finished := time.Now()
+
+		s.mu.Lock()
+		job.state.Running = false
+		job.state.Runs++
+		job.state.LastFinishedAt = finished
+		job.state.LastDurationMs = finished.Sub(now).Milliseconds()
+		job.state.LastStatus = models.JobSucceeded
+		job.state.LastError = ""
+		if err != nil {
+			job.state.LastStatus = models.JobFailed
+			job.state.LastError = err.Error()
+			job.state.Failures++
+		}
+		// Запуск по расписанию, пришедшийся на время работы, пропускается, а не выполняется следом
+		if !job.state.NextRunAt.IsZero() && !job.state.NextRunAt.After(finished) {
+			log.Printf("Job %s skipped run at %s: previous run was still in progress", job.Name, job.state.NextRunAt.Format(time.RFC3339))
+			job.state.NextRunAt = s.next(job.Job, finished)
+		}
+		state := job.state
+		s.mu.Unlock()
+
+		if err != nil {
+			log.Printf("Job %s failed: %v", job.Name, err)
+		} else {
+			log.Printf("Job %s done in %s", job.Name, finished.Sub(now))
+		}
+		s.save(state)
+		s.notify()
+	}()
+}
+
+func (s *Scheduler) save(state models.JobState) {
+	// Состояние сохраняется и после отмены контекста запусков, чтобы зафиксировать прерванный запуск
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(s.ctx), 5*time.Second)
+	defer cancel()
+	if err := s.store.SaveJobState(ctx, state); err != nil {
+		log.Printf("failed to save job %s state: %v", state.Name, err)
+	}
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// List состояние задач в порядке регистрации
+func (s *Scheduler) List(ctx context.Context) []models.JobState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make([]models.JobState, 0, len(s.order))
+	for _, name := range s.order {
+		states = append(states, s.jobs[name].state)
+	}
+	return states
+}
+
+// Trigger запускает задачу вне расписания, в том числе приостановленную.
+// Внеочередной запуск не сдвигает расписание.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (models.JobState, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	if !ok {
+		s.mu.Unlock()
+		return models.JobState{}, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	if s.ctx == nil {
+		s.mu.Unlock()
+		return models.JobState{}, fmt.Errorf("%w: scheduler is not started", apperr.ErrUnavailable)
+	}
+	if job.state.Running {
+		state := job.state
+		s.mu.Unlock()
+		return state, ErrJobRunning
+	}
+	state := s.begin(job, false)
+	s.mu.Unlock()
+
+	s.save(state)
+	s.run(job, state.LastStartedAt)
+	return state, nil
+}
+
+// Pause приостанавливает запуски по расписанию; текущий запуск не прерывается
+func (s *Scheduler) Pause(ctx context.Context, name string) (models.JobState, error) {
+	return s.setPaused(name, true)
+}
+
+// Resume возобновляет запуски по расписанию со следующего времени после текущего
+func (s *Scheduler) Resume(ctx context.Context, name string) (models.JobState, error) {
+	return s.setPaused(name, false)
+}
+
+func (s *Scheduler) setPaused(name string, paused bool) (models.JobState, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	if !ok {
+		s.mu.Unlock()
+		return models.JobState{}, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	if job.state.Paused && !paused {
+		job.state.NextRunAt = s.next(job.Job, time.Now())
+	}
+	job.state.Paused = paused
+	state := job.state
+	started := s.ctx != nil
+	s.mu.Unlock()
+
+	if started {
+		s.save(state)
+	}
+	s.notify()
+	return state, nil
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/models"
+)
+
+// everySchedule запуск через фиксированный интервал после t
+type everySchedule time.Duration
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+type memJobStore struct {
+	mu     sync.Mutex
+	states map[string]models.JobState
+}
+
+func newMemJobStore(states ...models.JobState) *memJobStore {
+	s := &memJobStore{states: make(map[string]models.JobState)}
+	for _, state := range states {
+		s.states[state.Name] = state
+	}
+	return s
+}
+
+func (s *memJobStore) GetJobStates(_ context.Context) ([]models.JobState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states := make([]models.JobState, 0, len(s.states))
+	for _, state := range s.states {
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (s *memJobStore) SaveJobState(_ context.Context, state models.JobState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state.Name] = state
+	return nil
+}
+
+func (s *memJobStore) get(name string) models.JobState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[name]
+}
+
+// startScheduler запускает планировщик до конца теста и дожидается завершения задач
+func startScheduler(t *testing.T, store JobStore, jobs ...Job) *Scheduler {
+	t.Helper()
+	s := NewScheduler(store)
+	for _, job := range jobs {
+		s.Add(job)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		s.Wait()
+	})
+	return s
+}
+
+// waitJob ожидает, пока состояние задачи не удовлетворит условию
+func waitJob(t *testing.T, s *Scheduler, name string, ok func(models.JobState) bool) models.JobState {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		for _, state := range s.List(context.Background()) {
+			if state.Name == name && ok(state) {
+				return state
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s: condition not reached, states %+v", name, s.List(context.Background()))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSchedulerDoesNotOverlapRuns(t *testing.T) {
+	var active, maxActive, runs atomic.Int32
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	job := Job{
+		Name:     "sync",
+		Schedule: everySchedule(5 * time.Millisecond),
+		Run: func(ctx context.Context) error {
+			n := active.Add(1)
+			defer active.Add(-1)
+			if n > maxActive.Load() {
+				maxActive.Store(n)
+			}
+			if runs.Add(1) == 1 {
+				started <- struct{}{}
+				<-release
+			}
+			return nil
+		},
+	}
+
+	store := newMemJobStore()
+	s := startScheduler(t, store, job)
+	<-started
+
+	// Несколько запусков по расписанию приходятся на время первого, ручной запуск отклоняется
+	time.Sleep(30 * time.Millisecond)
+	state, err := s.Trigger(context.Background(), "sync")
+	if !errors.Is(err, ErrJobRunning) {
+		t.Fatalf("Trigger error = %v, want ErrJobRunning", err)
+	}
+	if !state.Running || state.LastStatus != models.JobRunning {
+		t.Errorf("state = %+v, want running", state)
+	}
+	if runs.Load() != 1 {
+		t.Errorf("runs while the first is in progress = %d, want 1", runs.Load())
+	}
+	if saved := store.get("sync"); saved.LastStatus != models.JobRunning {
+		t.Errorf("saved state = %+v, want running", saved)
+	}
+
+	close(release)
+	state = waitJob(t, s, "sync", func(state models.JobState) bool { return state.Runs >= 3 })
+	if maxActive.Load() != 1 {
+		t.Errorf("max concurrent runs = %d, want 1", maxActive.Load())
+	}
+	if state.LastStatus != models.JobSucceeded && state.LastStatus != models.JobRunning {
+		t.Errorf("state = %+v", state)
+	}
+}
+
+func TestSchedulerPauseResume(t *testing.T) {
+	var runs atomic.Int32
+	job := Job{
+		Name:     "fundamentals",
+		Schedule: everySchedule(time.Millisecond),
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	}
+	// Пауза и прерванный остановкой сервера запуск восстанавливаются из базы
+	store := newMemJobStore(models.JobState{Name: "fundamentals", Paused: true, LastStatus: models.JobRunning, Runs: 4})
+	s := startScheduler(t, store, job)
+
+	time.Sleep(30 * time.Millisecond)
+	if runs.Load() != 0 {
+		t.Fatalf("paused job ran %d times", runs.Load())
+	}
+	restored := s.List(context.Background())[0]
+	if !restored.Paused || restored.LastStatus != models.JobFailed || restored.LastError == "" || restored.Runs != 4 {
+		t.Errorf("restored state = %+v, want paused and interrupted run marked failed", restored)
+	}
+
+	before := time.Now()
+	state, err := s.Resume(context.Background(), "fundamentals")
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if state.Paused || state.NextRunAt.Before(before) {
+		t.Errorf("resumed state = %+v, want next run after resume", state)
+	}
+	waitJob(t, s, "fundamentals", func(state models.JobState) bool { return state.Runs > 4 })
+
+	state, err = s.Pause(context.Background(), "fundamentals")
+	if err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	if !state.Paused || !store.get("fundamentals").Paused {
+		t.Errorf("paused state = %+v, saved %+v", state, store.get("fundamentals"))
+	}
+	paused := waitJob(t, s, "fundamentals", func(state models.JobState) bool { return !state.Running })
+	time.Sleep(30 * time.Millisecond)
+	if after := s.List(context.Background())[0]; after.Runs != paused.Runs {
+		t.Errorf("runs after pause = %d, want %d", after.Runs, paused.Runs)
+	}
+
+	if _, err := s.Pause(context.Background(), "unknown"); !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("Pause unknown job error = %v, want not found", err)
+	}
+}
+
+func TestSchedulerManualRun(t *testing.T) {
+	failed := errors.New("upstream unavailable")
+	var fail atomic.Bool
+	job := Job{
+		Name:     "instruments",
+		Schedule: everySchedule(time.Hour),
+		Run: func(ctx context.Context) error {
+			if fail.Load() {
+				return failed
+			}
+			return nil
+		},
+	}
+
+	s := NewScheduler(newMemJobStore())
+	s.Add(job)
+	if _, err := s.Trigger(context.Background(), "instruments"); !errors.Is(err, apperr.ErrUnavailable) {
+		t.Errorf("Trigger before Start error = %v, want unavailable", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		s.Wait()
+	})
+
+	if _, err := s.Trigger(context.Background(), "unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Trigger unknown job error = %v, want ErrJobNotFound", err)
+	}
+
+	// Ручной запуск работает и для приостановленной задачи и не сдвигает расписание
+	if _, err := s.Pause(context.Background(), "instruments"); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	scheduled := s.List(context.Background())[0].NextRunAt
+	if _, err := s.Trigger(context.Background(), "instruments"); err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	state := waitJob(t, s, "instruments", func(state models.JobState) bool { return state.Runs == 1 && !state.Running })
+	if state.LastStatus != models.JobSucceeded || !state.NextRunAt.Equal(scheduled) || !state.Paused {
+		t.Errorf("state = %+v, want succeeded with schedule %s kept", state, scheduled)
+	}
+
+	fail.Store(true)
+	if _, err := s.Trigger(context.Background(), "instruments"); err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	state = waitJob(t, s, "instruments", func(state models.JobState) bool { return state.Runs == 2 && !state.Running })
+	if state.LastStatus != models.JobFailed || state.LastError != failed.Error() || state.Failures != 1 {
+		t.Errorf("state = %+v, want failed run recorded", state)
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/backtest"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/internal/rules"
+	"math"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// screenResult сигнал или ошибка по одному инструменту
+type screenResult struct {
+	row models.ScreenerRow
+	err error
+}
+
+// Screen считает сигнал по каждому инструменту запроса пулом из Config.ScreenerWorkers воркеров и строит рейтинг
+// по полю SortBy. Ошибка по инструменту попадает в Errors и не мешает остальным; запрос целиком падает
+// только при ошибке выбора инструментов или отмене.
+func (s *TinkoffService) Screen(ctx context.Context, req models.ScreenerRequest) (models.ScreenerResponse, error) {
+	targets, err := s.screenerTargets(ctx, req)
+	if err != nil {
+		return models.ScreenerResponse{}, err
+	}
+
+	sortBy := models.DefaultScreenerSort
+	if field, ok := rules.LookupScalar(req.SortBy); ok {
+		sortBy = field.Name
+	}
+	order := req.Order
+	if order == "" {
+		order = "desc"
+	}
+
+	results := make([]screenResult, len(targets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(s.Config.ScreenerWorkers, 1), len(targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = s.screenInstrument(ctx, targets[i], req)
+			}
+		}()
+	}
+
+feed:
+	for i := range targets {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return models.ScreenerResponse{}, err
+	}
+
+	response := models.ScreenerResponse{
+		SortBy:      sortBy,
+		Order:       order,
+		Instruments: len(targets),
+		Rows:        []models.ScreenerRow{},
+		Errors:      []models.ScreenerError{},
+	}
+	for _, result := range results {
+		if result.err != nil {
+			response.Errors = append(response.Errors, models.ScreenerError{
+				InstrumentId: result.row.InstrumentId,
+				Ticker:       result.row.Ticker,
+				Error:        result.err.Error(),
+			})
+			continue
+		}
+		response.Rows = append(response.Rows, result.row)
+	}
+
+	rankRows(response.Rows, sortBy, order == "asc")
+	if req.Limit > 0 && len(response.Rows) > req.Limit {
+		response.Rows = response.Rows[:req.Limit]
+	}
+	return response, nil
+}
+
+// screenerTargets инструменты запроса: явный список, фильтр каталога или watchlist
+func (s *TinkoffService) screenerTargets(ctx context.Context, req models.ScreenerRequest) ([]models.Instrument, error) {
+	if len(req.InstrumentIds) > 0 {
+		targets := make([]models.Instrument, len(req.InstrumentIds))
+		for i, id := range req.InstrumentIds {
+			targets[i] = models.Instrument{Uid: id}
+		}
+		return targets, nil
+	}
+
+	catalogue := req.Catalogue()
+	if !catalogue.IsEmpty() {
+		instruments, total, err := s.is.instrumentRepository.ListInstruments(ctx, catalogue)
+		if err != nil {
+			return nil, err
+		}
+		if total > int64(models.MaxScreenerInstruments) {
+			return nil, apperr.InvalidArgument("catalogue filter matches %d instruments, at most %d can be screened", total, models.MaxScreenerInstruments)
+		}
+		if len(instruments) == 0 {
+			return nil, fmt.Errorf("%w: no instruments match the catalogue filter", apperr.ErrNotFound)
+		}
+		return instruments, nil
+	}
+
+	if len(s.Config.Watchlist) == 0 {
+		return nil, apperr.InvalidArgument("no instruments to screen: set instrumentIds, a catalogue filter or WATCHLIST")
+	}
+	targets := make([]models.Instrument, len(s.Config.Watchlist))
+	for i, id := range s.Config.Watchlist {
+		targets[i] = models.Instrument{Uid: id}
+	}
+	return targets, nil
+}
+
+// screenInstrument сигнал по свечам инструмента; недостающие свечи догружаются, как в GetTotalSignal
+func (s *TinkoffService) screenInstrument(ctx context.Context, instrument models.Instrument, req models.ScreenerRequest) screenResult {
+	// Инструменты не из каталога: тикер и имя, если инструмент в нем есть
+	if instrument.Ticker == "" {
+		if found, err := s.is.instrumentRepository.GetInstrument(ctx, instrument.Uid); err == nil {
+			instrument = found
+		}
+	}
+
+	row := models.ScreenerRow{
+		InstrumentId: instrument.Uid,
+		Ticker:       instrument.Ticker,
+		Name:         instrument.Name,
+	}
+
+	candles, err := s.LoadCandles(ctx, models.GetCandlesRequest{
+		InstrumentId: instrument.Uid,
+		Interval:     req.Interval,
+		From:         req.From,
+		To:           req.To,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = fmt.Errorf("%w: no candles in range", apperr.ErrNotFound)
+	}
+	if err != nil {
+		return screenResult{row: row, err: err}
+	}
+
+	prices, _ := closeSeries(candles)
+	row.Candles = len(prices)
+	if len(prices) < slidingWindow {
+		return screenResult{row: row, err: apperr.InvalidArgument("need at least %d candles for analysis, got %d", slidingWindow, len(prices))}
+	}
+
+	sig, err := s.pa.TotalSignal(prices)
+	if err != nil {
+		return screenResult{row: row, err: err}
+	}
+
+	decision := backtest.NewSignalStrategy().Decide(sig)
+	row.Fields = rules.Scalars(prices, sig)
+	row.RSITrend = sig.RSITrend
+	row.Action = decision.Action
+	row.Reason = decision.Reason
+	return screenResult{row: row}
+}
+
+// rankRows сортирует по полю и проставляет ранг с 1; нечисловые значения (NaN, ±Inf) идут в конец
+func rankRows(rows []models.ScreenerRow, field string, asc bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].Fields[field], rows[j].Fields[field]
+		aOk, bOk := isFinite(a), isFinite(b)
+		switch {
+		case aOk != bOk:
+			return aOk
+		case !aOk || a == b:
+			return rows[i].Ticker < rows[j].Ticker
+		case asc:
+			return a < b
+		}
+		return a > b
+	})
+	for i := range rows {
+		rows[i].Rank = i + 1
+	}
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/pkg/http_client"
+)
+
+var screenFrom = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const screenHours = 120
+
+// newScreenerService сервис со свечами в памяти: у инструментов с трендом свечи покрывают весь период запроса,
+// у "short" свечей меньше окна анализа, а "missing" нет в базе, и Tinkoff отвечает на него NotFound
+func newScreenerService(t *testing.T) (*TinkoffService, models.ScreenerRequest) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":5,"message":"instrument not found","description":"50002"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	repo := newMemRepository()
+	to := screenFrom.Add(screenHours * time.Hour)
+	seed := func(uid string, slope float64, hours int) {
+		repo.instruments[uid] = models.Instrument{Uid: uid, Ticker: strings.ToUpper(uid), Name: uid + " corp"}
+		candles := make([]models.Candle, hours)
+		for i := range candles {
+			price := 100 + slope*float64(i) + math.Sin(float64(i))
+			candles[i] = models.Candle{
+				InstrumentId: uid,
+				Interval:     "CANDLE_INTERVAL_HOUR",
+				Time:         screenFrom.Add(time.Duration(i) * time.Hour),
+				Open:         price,
+				High:         price + 0.5,
+				Low:          price - 0.5,
+				Close:        price,
+				Volume:       1,
+			}
+		}
+		_, _ = repo.CreateCandles(context.Background(), candles)
+		_ = repo.AddCoverage(context.Background(), models.CandleCoverage{
+			InstrumentId: uid, Interval: "CANDLE_INTERVAL_HOUR", From: screenFrom, To: to,
+		})
+	}
+	seed("up", 0.3, screenHours)
+	seed("flat", 0, screenHours)
+	seed("down", -0.2, screenHours)
+	seed("short", 0.1, slidingWindow/2)
+
+	service := newTestService(repo)
+	service.Client = http_client.NewHTTPClient()
+	service.Client.Retry.MaxRetries = 0
+	service.Config.APIBaseURL = server.URL
+
+	return service, models.ScreenerRequest{
+		InstrumentIds: []string{"down", "missing", "up", "short", "flat"},
+		Interval:      "CANDLE_INTERVAL_HOUR",
+		From:          screenFrom.Format(time.RFC3339),
+		To:            to.Format(time.RFC3339),
+	}
+}
+
+func screenTickers(rows []models.ScreenerRow) []string {
+	tickers := make([]string, len(rows))
+	for i, row := range rows {
+		tickers[i] = row.Ticker
+	}
+	return tickers
+}
+
+func TestScreenReportsPartialFailure(t *testing.T) {
+	service, req := newScreenerService(t)
+
+	response, err := service.Screen(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Screen: %v", err)
+	}
+
+	if response.Instruments != 5 || response.SortBy != models.DefaultScreenerSort || response.Order != "desc" {
+		t.Errorf("response = %d instruments by %s %s", response.Instruments, response.SortBy, response.Order)
+	}
+	if got := screenTickers(response.Rows); !slices.Equal(got, []string{"UP", "FLAT", "DOWN"}) {
+		t.Errorf("rows = %v, want UP, FLAT, DOWN by trend factor", got)
+	}
+	for i, row := range response.Rows {
+		if row.Rank != i+1 || row.Candles != screenHours || row.Action == "" || row.Name == "" {
+			t.Errorf("row %d = %+v", i, row)
+		}
+		if _, ok := row.Fields["TrendFactor"]; !ok {
+			t.Errorf("row %s has no TrendFactor", row.Ticker)
+		}
+	}
+
+	// Ошибки по инструментам в порядке запроса, остальные инструменты посчитаны
+	if len(response.Errors) != 2 {
+		t.Fatalf("errors = %+v, want missing and short", response.Errors)
+	}
+	missing, short := response.Errors[0], response.Errors[1]
+	if missing.InstrumentId != "missing" || !strings.Contains(missing.Error, "not found") {
+		t.Errorf("missing error = %+v", missing)
+	}
+	if short.InstrumentId != "short" || short.Ticker != "SHORT" || !strings.Contains(short.Error, "need at least 100 candles") {
+		t.Errorf("short error = %+v", short)
+	}
+}
+
+func TestScreenOrderAndLimit(t *testing.T) {
+	service, req := newScreenerService(t)
+	req.Order = "asc"
+	req.Limit = 2
+
+	response, err := service.Screen(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Screen: %v", err)
+	}
+	if got := screenTickers(response.Rows); !slices.Equal(got, []string{"DOWN", "FLAT"}) {
+		t.Errorf("rows = %v, want the two lowest trend factors", got)
+	}
+	if response.Order != "asc" || response.Instruments != 5 || len(response.Errors) != 2 {
+		t.Errorf("response = %+v, want errors kept beyond the limit", response)
+	}
+}
+
+func TestScreenSortByFieldIgnoresCase(t *testing.T) {
+	service, req := newScreenerService(t)
+	req.SortBy = "price"
+
+	response, err := service.Screen(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Screen: %v", err)
+	}
+	if response.SortBy != "Price" {
+		t.Errorf("sortBy = %q, want the canonical field name", response.SortBy)
+	}
+	// Последняя цена 100 + slope * 119 + sin(119)
+	if got := screenTickers(response.Rows); !slices.Equal(got, []string{"UP", "FLAT", "DOWN"}) {
+		t.Errorf("rows = %v, want UP, FLAT, DOWN by price", got)
+	}
+}
+
+func TestScreenWithoutTargets(t *testing.T) {
+	service, req := newScreenerService(t)
+	req.InstrumentIds = nil
+
+	_, err := service.Screen(context.Background(), req)
+	if !errors.Is(err, apperr.ErrInvalidArgument) {
+		t.Errorf("err = %v, want invalid argument without instruments and watchlist", err)
+	}
+}
+
+func TestScreenCanceled(t *testing.T) {
+	service, req := newScreenerService(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := service.Screen(ctx, req); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context canceled", err)
+	}
+}
+
+func TestRankRowsNonFiniteLast(t *testing.T) {
+	row := func(ticker string, value float64) models.ScreenerRow {
+		return models.ScreenerRow{Ticker: ticker, Fields: map[string]float64{"Hurst": value}}
+	}
+	rows := func() []models.ScreenerRow {
+		return []models.ScreenerRow{
+			row("NAN", math.NaN()),
+			row("B", 0.5),
+			row("INF", math.Inf(1)),
+			row("A", 0.5),
+			row("LOW", 0.2),
+			row("MINF", math.Inf(-1)),
+			row("HIGH", 0.8),
+		}
+	}
+
+	tests := []struct {
+		asc  bool
+		want []string
+	}{
+		{false, []string{"HIGH", "A", "B", "LOW", "INF", "MINF", "NAN"}},
+		{true, []string{"LOW", "A", "B", "HIGH", "INF", "MINF", "NAN"}},
+	}
+	for _, tt := range tests {
+		got := rows()
+		rankRows(got, "Hurst", tt.asc)
+		if tickers := screenTickers(got); !slices.Equal(tickers, tt.want) {
+			t.Errorf("asc=%v: %v, want %v: equal values and non-finite values by ticker, non-finite last", tt.asc, tickers, tt.want)
+		}
+		for i, r := range got {
+			if r.Rank != i+1 {
+				t.Errorf("asc=%v: %s rank %d, want %d", tt.asc, r.Ticker, r.Rank, i+1)
+			}
+		}
+	}
+}
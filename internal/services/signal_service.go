@@ -1,61 +1,96 @@
 package services
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"mamonolitmvp/internal/apperr"
 	"mamonolitmvp/internal/math/price_analysis"
 	"mamonolitmvp/internal/models"
-	"strconv"
+	"time"
 )
 
-func (s *TinkoffService) GetTotalSignal(instrumentInfo map[string]any) (string, price_analysis.Signal, []price_analysis.Fdi, []price_analysis.NormalizeFdi, []float64, error) {
-	reqBody := models.GetCandlesRequest{
-		Figi:         instrumentInfo["figi"].(string),
-		From:         instrumentInfo["from"].(string),
-		To:           instrumentInfo["to"].(string),
-		Interval:     instrumentInfo["interval"].(string),
-		InstrumentId: instrumentInfo["instrumentId"].(string),
-	}
-
-	url := fmt.Sprintf("%s/tinkoff.public.invest.api.contract.v1.MarketDataService/GetCandles", s.Config.APIBaseURL)
+// slidingWindow размер окна скользящего анализа FDI и Херста
+const slidingWindow = 100
 
-	headers := map[string]string{
-		"Authorization": "Bearer " + s.Config.APIToken,
-		"Content-Type":  "application/json",
+func (s *TinkoffService) GetTotalSignal(ctx context.Context, req models.GetSignalsRequest) (string, price_analysis.Signal, []price_analysis.Fdi, []price_analysis.NormalizeFdi, []float64, price_analysis.RegimeAnalysis, error) {
+	candles, err := s.LoadCandles(ctx, req.GetCandlesRequest)
+	if err != nil {
+		return "", price_analysis.Signal{}, nil, nil, nil, price_analysis.RegimeAnalysis{}, err
 	}
 
-	respBody, _ := s.Client.Post(url, headers, reqBody)
-
-	response, _, _ := s.fixeRespBody(respBody, reqBody.InstrumentId)
-
-	prices := make([]float64, 0, len(response.Candles))
+	prices, times := closeSeries(candles)
+	if len(prices) < slidingWindow {
+		return "", price_analysis.Signal{}, nil, nil, nil, price_analysis.RegimeAnalysis{}, apperr.InvalidArgument("need at least %d candles for analysis, got %d", slidingWindow, len(prices))
+	}
 
-	for _, v := range response.Candles {
-		locUnit := v.Close.Units
-		locNano := v.Close.Nano
+	sig, err := s.pa.TotalSignal(prices)
+	if err != nil {
+		return "", price_analysis.Signal{}, nil, nil, nil, price_analysis.RegimeAnalysis{}, err
+	}
 
-		locPrice := fmt.Sprintf("%s.%d", locUnit, locNano)
-		priceValue, err := strconv.ParseFloat(locPrice, 64)
+	if len(req.Indicators) > 0 {
+		sig.Indicators, err = s.pa.CalculateIndicators(ohlcvSeries(candles), req.Indicators)
 		if err != nil {
-			return "", price_analysis.Signal{}, nil, nil, nil, err
+			return "", price_analysis.Signal{}, nil, nil, nil, price_analysis.RegimeAnalysis{}, err
 		}
-
-		prices = append(prices, priceValue)
 	}
 
-	sig, err := s.pa.TotalSignal(prices)
+	fdi, normFdi, hurstSeries, err := s.pa.SlidingWindowAnalysis(ctx, prices, slidingWindow)
 	if err != nil {
-		return "", price_analysis.Signal{}, nil, nil, nil, err
+		return "", price_analysis.Signal{}, nil, nil, nil, price_analysis.RegimeAnalysis{}, err
 	}
 
-	fdi, normFdi, hurstSeries, err := s.pa.SlidingWindowAnalysis(prices, 100)
+	// Окно i заканчивается свечой i+slidingWindow-1
+	regimes, err := s.pa.ClassifyRegimes(hurstSeries, fdi, times[slidingWindow-1:], req.Regime.WithDefaults())
+	if errors.Is(err, price_analysis.ErrInsufficientData) {
+		return "", price_analysis.Signal{}, nil, nil, nil, price_analysis.RegimeAnalysis{}, apperr.InvalidArgument("%v", err)
+	}
 	if err != nil {
-		return "", price_analysis.Signal{}, nil, nil, nil, err
+		return "", price_analysis.Signal{}, nil, nil, nil, price_analysis.RegimeAnalysis{}, err
 	}
 
-	ticker, err := s.is.instrumentRepository.GetTicker(instrumentInfo["instrumentId"].(string))
+	ticker, err := s.is.instrumentRepository.GetTicker(ctx, req.InstrumentId)
 	if err != nil {
-		return "", price_analysis.Signal{}, nil, nil, nil, err
+		return "", price_analysis.Signal{}, nil, nil, nil, price_analysis.RegimeAnalysis{}, err
 	}
 
-	return ticker, sig, fdi, normFdi, hurstSeries, err
+	return ticker, sig, fdi, normFdi, hurstSeries, regimes, err
+}
+
+// closeSeries цены закрытия свечей и время их открытия
+func closeSeries(candles []models.Candle) ([]float64, []time.Time) {
+	prices := make([]float64, 0, len(candles))
+	times := make([]time.Time, 0, len(candles))
+
+	for _, v := range candles {
+		prices = append(prices, v.Close)
+		times = append(times, v.Time)
+	}
+
+	return prices, times
+}
+
+// ohlcvSeries ряды open/high/low/close/volume свечей
+func ohlcvSeries(candles []models.Candle) price_analysis.OHLCV {
+	data := price_analysis.OHLCV{
+		Open:   make([]float64, len(candles)),
+		High:   make([]float64, len(candles)),
+		Low:    make([]float64, len(candles)),
+		Close:  make([]float64, len(candles)),
+		Volume: make([]float64, len(candles)),
+	}
+
+	for i, v := range candles {
+		data.Open[i] = v.Open
+		data.High[i] = v.High
+		data.Low[i] = v.Low
+		data.Close[i] = v.Close
+		data.Volume[i] = float64(v.Volume)
+	}
+
+	return data
+}
+
+func (s *TinkoffService) ListIndicators() []price_analysis.IndicatorSpec {
+	return s.pa.Indicators.List()
 }
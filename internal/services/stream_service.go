@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mamonolitmvp/internal/apperr"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/pkg/http_client"
+	"sync"
+	"time"
+)
+
+var (
+	errResubscribe = errors.New("subscriptions changed")
+	errStaleStream = errors.New("no messages from market data stream")
+)
+
+// candleFlushPeriod как часто незакрытые свечи проверяются на закрытие по времени
+const candleFlushPeriod = time.Second
+
+// candleSeries свечи одного инструмента и интервала
+type candleSeries struct {
+	instrumentUID string
+	interval      string
+}
+
+// MarketDataStream держит открытым поток MarketDataServerSideStream: закрытые свечи сохраняются в базу,
+// последние цены и стаканы хранятся в памяти. После обрыва поток открывается заново с теми же подписками.
+// Обновления незакрытой свечи копятся в памяти, в базу пишется последнее, когда пришла следующая свеча
+// серии или истек интервал: иначе первая сделка свечи вставила бы ее в базу и запустила проверки по ней.
+type MarketDataStream struct {
+	tinkoff *TinkoffService
+	url     string
+
+	// PingDelay период пингов сервера; поток без сообщений дольше трех периодов переоткрывается
+	PingDelay time.Duration
+	Retry     http_client.RetryPolicy
+
+	mu         sync.Mutex
+	status     models.StreamStatus
+	lastPrices map[string]models.LastPrice
+	orderBooks map[string]models.OrderBook
+	// forming последнее обновление незакрытой свечи каждой серии
+	forming map[candleSeries]models.Candle
+	// restart закрывает текущее соединение, чтобы открыть поток с новыми подписками
+	restart context.CancelCauseFunc
+	changed chan struct{}
+}
+
+func NewMarketDataStream(tinkoff *TinkoffService, baseURL string, subscriptions models.StreamSubscriptions) *MarketDataStream {
+	return &MarketDataStream{
+		tinkoff:    tinkoff,
+		url:        fmt.Sprintf("%s/tinkoff.public.invest.api.contract.v1.MarketDataStreamService/MarketDataServerSideStream", baseURL),
+		PingDelay:  30 * time.Second,
+		Retry:      http_client.DefaultRetryPolicy(),
+		status:     models.StreamStatus{Subscriptions: subscriptions},
+		lastPrices: make(map[string]models.LastPrice),
+		orderBooks: make(map[string]models.OrderBook),
+		forming:    make(map[candleSeries]models.Candle),
+		changed:    make(chan struct{}, 1),
+	}
+}
+
+// Run переподключается к потоку до отмены ctx; задержка растет, пока соединения обрываются без сообщений
+func (m *MarketDataStream) Run(ctx context.Context) {
+	go m.flushLoop(ctx)
+
+	attempt := 0
+	for ctx.Err() == nil {
+		// Подписки читаются и restart ставится под одной блокировкой: Subscribe между ними не нашел бы
+		// restart, и поток открылся бы со старым набором
+		connCtx, cancel := context.WithCancelCause(ctx)
+		m.mu.Lock()
+		subscriptions := m.status.Subscriptions
+		if !subscriptions.IsEmpty() {
+			m.restart = cancel
+		}
+		m.mu.Unlock()
+
+		if subscriptions.IsEmpty() {
+			cancel(nil)
+			select {
+			case <-ctx.Done():
+			case <-m.changed:
+			}
+			continue
+		}
+
+		received, err := m.connect(connCtx, cancel, subscriptions)
+		cause := context.Cause(connCtx)
+		cancel(nil)
+
+		m.mu.Lock()
+		m.restart = nil
+		m.status.Connected = false
+		m.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(cause, errResubscribe) {
+			log.Println("Market data stream: resubscribe")
+			attempt = 0
+			continue
+		}
+		if errors.Is(cause, errStaleStream) {
+			err = cause
+		}
+		if received {
+			attempt = 0
+		}
+
+		delay := m.Retry.Backoff(attempt)
+		attempt++
+
+		m.mu.Lock()
+		m.status.Reconnects++
+		m.status.LastError = err.Error()
+		m.mu.Unlock()
+
+		log.Printf("Market data stream disconnected: %v, reconnect in %s", err, delay)
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+	}
+}
+
+// connect открывает поток и обрабатывает сообщения до его закрытия
+func (m *MarketDataStream) connect(ctx context.Context, cancel context.CancelCauseFunc, subscriptions models.StreamSubscriptions) (bool, error) {
+	headers := map[string]string{
+		"Authorization": "Bearer " + m.tinkoff.Config.APIToken,
+		"Content-Type":  "application/json",
+	}
+
+	staleAfter := 3 * m.PingDelay
+	watchdog := time.AfterFunc(staleAfter, func() {
+		cancel(errStaleStream)
+	})
+	defer watchdog.Stop()
+
+	received := false
+	err := m.tinkoff.Client.Stream(ctx, m.url, headers, subscriptions.Request(m.PingDelay), func(raw json.RawMessage) error {
+		watchdog.Reset(staleAfter)
+
+		var msg models.MarketDataResponse
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return fmt.Errorf("failed to unmarshal stream message: %w", err)
+		}
+
+		if !received {
+			received = true
+			m.mu.Lock()
+			m.status.Connected = true
+			m.status.ConnectedAt = time.Now()
+			m.status.LastError = ""
+			m.mu.Unlock()
+			log.Println("Market data stream connected")
+		}
+
+		m.handle(ctx, msg)
+		return nil
+	})
+	return received, err
+}
+
+func (m *MarketDataStream) handle(ctx context.Context, msg models.MarketDataResponse) {
+	m.mu.Lock()
+	m.status.LastMessageAt = time.Now()
+	m.mu.Unlock()
+
+	for _, resp := range []*models.SubscriptionResponse{msg.SubscribeCandlesResponse, msg.SubscribeOrderBookResponse, msg.SubscribeLastPriceResponse} {
+		if resp == nil {
+			continue
+		}
+		for _, status := range resp.Statuses() {
+			if status.SubscriptionStatus != models.SubscriptionStatusSuccess {
+				log.Printf("Market data stream: subscription %s %s failed: %s", status.InstrumentUid, status.Interval, status.SubscriptionStatus)
+			}
+		}
+	}
+
+	switch {
+	case msg.Candle != nil:
+		candle, err := msg.Candle.ToCandle()
+		if err != nil {
+			log.Printf("Market data stream: skip candle: %v", err)
+			return
+		}
+		m.bufferCandle(ctx, candle, time.Now())
+	case msg.LastPrice != nil:
+		m.mu.Lock()
+		m.lastPrices[msg.LastPrice.InstrumentUid] = *msg.LastPrice
+		m.status.LastPrices++
+		m.mu.Unlock()
+	case msg.Orderbook != nil:
+		m.mu.Lock()
+		m.orderBooks[msg.Orderbook.InstrumentUid] = *msg.Orderbook
+		m.status.OrderBooks++
+		m.mu.Unlock()
+	}
+}
+
+// bufferCandle запоминает обновление свечи и пишет в базу свечи, которые больше не изменятся:
+// предыдущую свечу серии, когда пришла более поздняя, и саму свечу, если ее интервал уже истек
+func (m *MarketDataStream) bufferCandle(ctx context.Context, candle models.Candle, now time.Time) {
+	key := candleSeries{instrumentUID: candle.InstrumentId, interval: candle.Interval}
+	var ready []models.Candle
+
+	m.mu.Lock()
+	previous, ok := m.forming[key]
+	switch {
+	case ok && candle.Time.Before(previous.Time):
+		// Запоздавшее обновление свечи, которая уже записана
+		ready = append(ready, candle)
+	case ok && candle.Time.After(previous.Time):
+		ready = append(ready, previous)
+		m.forming[key] = candle
+	default:
+		m.forming[key] = candle
+	}
+	if current := m.forming[key]; current.Closed(now) {
+		ready = append(ready, current)
+		delete(m.forming, key)
+	}
+	m.mu.Unlock()
+
+	m.storeCandles(ctx, ready)
+}
+
+// flushLoop пишет незакрытые свечи, интервал которых истек, пока следующая свеча серии не пришла
+func (m *MarketDataStream) flushLoop(ctx context.Context) {
+	ticker := time.NewTicker(candleFlushPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.flushClosed(ctx, now)
+		}
+	}
+}
+
+func (m *MarketDataStream) flushClosed(ctx context.Context, now time.Time) {
+	var ready []models.Candle
+	m.mu.Lock()
+	for key, candle := range m.forming {
+		if candle.Closed(now) {
+			ready = append(ready, candle)
+			delete(m.forming, key)
+		}
+	}
+	m.mu.Unlock()
+
+	m.storeCandles(ctx, ready)
+}
+
+// storeCandles сохраняет закрытые свечи. Ошибка записи не рвет поток: пропущенный диапазон догрузит
+// GetCandles, покрытие для него не отмечено.
+func (m *MarketDataStream) storeCandles(ctx context.Context, candles []models.Candle) {
+	for _, candle := range candles {
+		stats, err := m.tinkoff.is.instrumentRepository.CreateCandles(ctx, []models.Candle{candle})
+		if err != nil {
+			log.Printf("Market data stream: failed to store candle %s %s: %v", candle.InstrumentId, candle.Time, err)
+			continue
+		}
+		m.tinkoff.candlesStored(candle.InstrumentId, candle.Interval, stats)
+		m.mu.Lock()
+		m.status.Candles++
+		m.mu.Unlock()
+	}
+}
+
+// Subscribe заменяет набор подписок; открытый поток переоткрывается с новым набором
+func (m *MarketDataStream) Subscribe(ctx context.Context, subscriptions models.StreamSubscriptions) models.StreamStatus {
+	m.mu.Lock()
+	m.status.Subscriptions = subscriptions
+	if m.restart != nil {
+		m.restart(errResubscribe)
+	}
+	status := m.status
+	m.mu.Unlock()
+
+	select {
+	case m.changed <- struct{}{}:
+	default:
+	}
+	return status
+}
+
+func (m *MarketDataStream) Status(ctx context.Context) models.StreamStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// LastPrice последняя цена инструмента из потока
+func (m *MarketDataStream) LastPrice(ctx context.Context, instrumentUID string) (models.LastPrice, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	price, ok := m.lastPrices[instrumentUID]
+	if !ok {
+		return models.LastPrice{}, fmt.Errorf("%w: no last price for %s in market data stream", apperr.ErrNotFound, instrumentUID)
+	}
+	return price, nil
+}
+
+// OrderBook последний стакан инструмента из потока
+func (m *MarketDataStream) OrderBook(ctx context.Context, instrumentUID string) (models.OrderBook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	orderBook, ok := m.orderBooks[instrumentUID]
+	if !ok {
+		return models.OrderBook{}, fmt.Errorf("%w: no order book for %s in market data stream", apperr.ErrNotFound, instrumentUID)
+	}
+	return orderBook, nil
+}
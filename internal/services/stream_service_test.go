@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/fakestream"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/pkg/http_client"
+)
+
+func streamCandle(at time.Time, price float64) models.Candle {
+	return models.Candle{
+		InstrumentId: "uid-1",
+		Interval:     "CANDLE_INTERVAL_1_MIN",
+		Time:         at,
+		Open:         100, High: max(100, price), Low: min(100, price), Close: price, Volume: 1,
+	}
+}
+
+func storedCandles(t *testing.T, repo *memRepository) []models.Candle {
+	t.Helper()
+	candles, err := repo.GetCandlesInRange(context.Background(), "uid-1", "CANDLE_INTERVAL_1_MIN", time.Time{}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return candles
+}
+
+func TestStreamBuffersFormingCandle(t *testing.T) {
+	repo := newMemRepository()
+	service := newTestService(repo)
+	var hooks int
+	service.OnCandlesStored(func(string, string) { hooks++ })
+	stream := NewMarketDataStream(service, "", models.StreamSubscriptions{})
+	ctx := context.Background()
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	stream.bufferCandle(ctx, streamCandle(start, 101), start.Add(5*time.Second))
+	stream.bufferCandle(ctx, streamCandle(start, 102), start.Add(30*time.Second))
+	if got := storedCandles(t, repo); len(got) != 0 || hooks != 0 {
+		t.Fatalf("forming candle stored: %+v, hooks %d", got, hooks)
+	}
+
+	// Следующая свеча закрывает предыдущую: пишется последнее обновление
+	stream.bufferCandle(ctx, streamCandle(start.Add(time.Minute), 103), start.Add(61*time.Second))
+	got := storedCandles(t, repo)
+	if len(got) != 1 || got[0].Close != 102 || hooks != 1 {
+		t.Fatalf("stored %+v, hooks %d; want closed candle with close 102", got, hooks)
+	}
+
+	// Интервал следующей свечи истек без новых сообщений
+	stream.flushClosed(ctx, start.Add(119*time.Second))
+	if got := storedCandles(t, repo); len(got) != 1 {
+		t.Fatalf("candle stored before its interval passed: %+v", got)
+	}
+	stream.flushClosed(ctx, start.Add(2*time.Minute))
+	got = storedCandles(t, repo)
+	if len(got) != 2 || got[1].Close != 103 || hooks != 2 {
+		t.Fatalf("stored %+v, hooks %d; want second candle after interval", got, hooks)
+	}
+	if status := stream.Status(ctx); status.Candles != 2 {
+		t.Fatalf("status candles = %d, want 2", status.Candles)
+	}
+}
+
+func TestStreamStoresClosedCandleImmediately(t *testing.T) {
+	repo := newMemRepository()
+	stream := NewMarketDataStream(newTestService(repo), "", models.StreamSubscriptions{})
+	ctx := context.Background()
+
+	// waitingClose: свеча приходит после закрытия интервала
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	stream.bufferCandle(ctx, streamCandle(start, 101), start.Add(time.Minute+time.Second))
+	if got := storedCandles(t, repo); len(got) != 1 {
+		t.Fatalf("closed candle not stored: %+v", got)
+	}
+
+	// Запоздавшее обновление уже записанной свечи пишется сразу
+	stream.bufferCandle(ctx, streamCandle(start.Add(time.Minute), 102), start.Add(70*time.Second))
+	stream.bufferCandle(ctx, streamCandle(start, 99), start.Add(71*time.Second))
+	got := storedCandles(t, repo)
+	if len(got) != 1 || got[0].Close != 99 {
+		t.Fatalf("stored %+v, want late update of the first candle only", got)
+	}
+}
+
+// startFakeStream поток поверх fakestream.Server с короткими пингами и задержками переподключения
+func startFakeStream(t *testing.T, server *fakestream.Server, subscriptions models.StreamSubscriptions, pingDelay time.Duration) (*MarketDataStream, *memRepository) {
+	t.Helper()
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	repo := newMemRepository()
+	service := newTestService(repo)
+	service.Client = http_client.NewHTTPClient()
+	stream := NewMarketDataStream(service, httpServer.URL, subscriptions)
+	stream.PingDelay = pingDelay
+	stream.Retry = http_client.RetryPolicy{BaseDelay: 5 * time.Millisecond, MaxDelay: 20 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		stream.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return stream, repo
+}
+
+// eventually ждет условия до таймаута
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func lastPrices(uids ...string) models.StreamSubscriptions {
+	var subscriptions models.StreamSubscriptions
+	for _, uid := range uids {
+		subscriptions.LastPrices = append(subscriptions.LastPrices, models.LastPriceInstrument{InstrumentId: uid})
+	}
+	return subscriptions
+}
+
+func TestStreamReconnectsAfterDrop(t *testing.T) {
+	server := fakestream.NewServer()
+	server.Tick = 5 * time.Millisecond
+	server.DropAfter = 3
+	stream, _ := startFakeStream(t, server, lastPrices("uid-1"), time.Second)
+	ctx := context.Background()
+
+	eventually(t, "three connections", func() bool { return server.Connections() >= 3 })
+	eventually(t, "reconnects in status", func() bool { return stream.Status(ctx).Reconnects >= 2 })
+	if _, err := stream.LastPrice(ctx, "uid-1"); err != nil {
+		t.Fatalf("no last price after reconnects: %v", err)
+	}
+}
+
+func TestStreamResubscribes(t *testing.T) {
+	server := fakestream.NewServer()
+	server.Tick = 5 * time.Millisecond
+	stream, _ := startFakeStream(t, server, lastPrices("uid-1"), time.Second)
+	ctx := context.Background()
+
+	eventually(t, "uid-1 price", func() bool {
+		_, err := stream.LastPrice(ctx, "uid-1")
+		return err == nil
+	})
+	status := stream.Subscribe(ctx, lastPrices("uid-2"))
+	if len(status.Subscriptions.LastPrices) != 1 || status.Subscriptions.LastPrices[0].InstrumentId != "uid-2" {
+		t.Fatalf("subscriptions = %+v", status.Subscriptions)
+	}
+
+	eventually(t, "uid-2 price", func() bool {
+		_, err := stream.LastPrice(ctx, "uid-2")
+		return err == nil
+	})
+	if n := server.Connections(); n != 2 {
+		t.Fatalf("connections = %d, want 2", n)
+	}
+	if n := stream.Status(ctx).Reconnects; n != 0 {
+		t.Fatalf("resubscribe counted as %d reconnects", n)
+	}
+}
+
+func TestStreamRapidSubscribeKeepsLatest(t *testing.T) {
+	server := fakestream.NewServer()
+	server.Tick = time.Millisecond
+	stream, _ := startFakeStream(t, server, lastPrices("uid-0"), time.Second)
+	ctx := context.Background()
+
+	// Смены подписок во время переподключений: последний набор должен дойти до сервера
+	for i := 1; i <= 20; i++ {
+		stream.Subscribe(ctx, lastPrices(fmt.Sprintf("uid-%d", i)))
+		time.Sleep(time.Duration(i%3) * time.Millisecond)
+	}
+	eventually(t, "last subscription", func() bool {
+		_, err := stream.LastPrice(ctx, "uid-20")
+		return err == nil
+	})
+}
+
+func TestStreamWatchdogReconnectsStaleStream(t *testing.T) {
+	server := fakestream.NewServer()
+	server.Tick = 5 * time.Millisecond
+	server.StallAfter = 2
+	stream, _ := startFakeStream(t, server, lastPrices("uid-1"), 20*time.Millisecond)
+	ctx := context.Background()
+
+	eventually(t, "reconnect of stalled stream", func() bool { return server.Connections() >= 2 })
+	eventually(t, "stale error in status", func() bool {
+		return strings.Contains(stream.Status(ctx).LastError, errStaleStream.Error())
+	})
+}
+
+func TestStreamStoresCandlesFromFakeServer(t *testing.T) {
+	server := fakestream.NewServer()
+	server.Tick = 5 * time.Millisecond
+	stream, repo := startFakeStream(t, server, models.StreamSubscriptions{
+		Candles: []models.CandleInstrument{{InstrumentId: "uid-1", Interval: "CANDLE_INTERVAL_1_MIN"}},
+	}, time.Second)
+	ctx := context.Background()
+
+	eventually(t, "stream connected", func() bool { return stream.Status(ctx).Connected })
+	time.Sleep(50 * time.Millisecond)
+	// С waitingClose внутри минуты закрытых свечей нет, и незакрытые не пишутся
+	for _, candle := range storedCandles(t, repo) {
+		if !candle.Closed(time.Now()) {
+			t.Fatalf("forming candle stored: %+v", candle)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/pkg/scheduler"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CandleIntervalCron расписание закрытия свечи интервала (UTC)
+var CandleIntervalCron = map[string]string{
+	"CANDLE_INTERVAL_1_MIN":  "* * * * *",
+	"CANDLE_INTERVAL_2_MIN":  "*/2 * * * *",
+	"CANDLE_INTERVAL_3_MIN":  "*/3 * * * *",
+	"CANDLE_INTERVAL_5_MIN":  "*/5 * * * *",
+	"CANDLE_INTERVAL_10_MIN": "*/10 * * * *",
+	"CANDLE_INTERVAL_15_MIN": "*/15 * * * *",
+	"CANDLE_INTERVAL_30_MIN": "*/30 * * * *",
+	"CANDLE_INTERVAL_HOUR":   "0 * * * *",
+	"CANDLE_INTERVAL_2_HOUR": "0 */2 * * *",
+	"CANDLE_INTERVAL_4_HOUR": "0 */4 * * *",
+	"CANDLE_INTERVAL_DAY":    "0 0 * * *",
+	"CANDLE_INTERVAL_WEEK":   "0 0 * * 1",
+	"CANDLE_INTERVAL_MONTH":  "0 0 1 * *",
+}
+
+// CatalogueSyncJob обновляет каталог инструментов всех типов
+func CatalogueSyncJob(tinkoff *TinkoffService, schedule scheduler.Schedule, jitter time.Duration) Job {
+	return Job{
+		Name:     "catalogue-sync",
+		Schedule: schedule,
+		Jitter:   jitter,
+		Run: func(ctx context.Context) error {
+			synced, err := tinkoff.SyncInstruments(ctx, nil)
+			for instrumentType, stats := range synced {
+				log.Printf("Catalogue sync %s: %s", instrumentType, stats)
+			}
+			return err
+		},
+	}
+}
+
+// CandleSyncJob после закрытия свечи интервала догружает последние свечи инструментов watchlist.
+// Загружаются только диапазоны, которых еще нет в базе; ошибка по одному инструменту не мешает остальным.
+func CandleSyncJob(tinkoff *TinkoffService, interval string, watchlist []string, jitter time.Duration) (Job, error) {
+	spec, ok := CandleIntervalCron[interval]
+	if !ok {
+		return Job{}, fmt.Errorf("unknown candle interval: %q", interval)
+	}
+	schedule, err := scheduler.ParseCron(spec)
+	if err != nil {
+		return Job{}, err
+	}
+
+	name := "candle-sync-" + strings.ToLower(strings.TrimPrefix(interval, "CANDLE_INTERVAL_"))
+	return Job{
+		Name:     name,
+		Schedule: schedule,
+		Jitter:   jitter,
+		Run: func(ctx context.Context) error {
+			to := time.Now().UTC()
+			// Окно в один допустимый период запроса: одна загрузка, даже если сервер долго не работал
+			from := models.CandleIntervalLimits[interval].Sub(to)
+
+			var errs []error
+			for _, instrumentUID := range watchlist {
+				_, err := tinkoff.LoadCandles(ctx, models.GetCandlesRequest{
+					InstrumentId: instrumentUID,
+					Interval:     interval,
+					From:         from.Format(time.RFC3339),
+					To:           to.Format(time.RFC3339),
+				})
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				// Пустое окно — нет торгов, это не ошибка
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					errs = append(errs, fmt.Errorf("%s: %w", instrumentUID, err))
+				}
+			}
+			return errors.Join(errs...)
+		},
+	}, nil
+}
+
+// analysisWindow календарный период, в который с запасом на выходные помещается 2*slidingWindow свечей
+// интервала, но не меньше недели
+func analysisWindow(interval string) (time.Duration, error) {
+	periods, ok := periodsPerYear[interval]
+	if !ok {
+		return 0, fmt.Errorf("unknown candle interval: %q", interval)
+	}
+	years := 2 * slidingWindow / periods
+	return max(7*24*time.Hour, time.Duration(years*365*1.5*float64(24*time.Hour))), nil
+}
+
+// RuleEvaluationJob проверяет включенные правила всех стратегий на последних свечах инструментов watchlist
+// за analysisWindow
+func RuleEvaluationJob(tinkoff *TinkoffService, schedule scheduler.Schedule, interval string, watchlist []string, jitter time.Duration) (Job, error) {
+	window, err := analysisWindow(interval)
+	if err != nil {
+		return Job{}, err
+	}
+
+	return Job{
+		Name:     "rule-evaluation",
+		Schedule: schedule,
+		Jitter:   jitter,
+		Run: func(ctx context.Context) error {
+			all, err := tinkoff.ListRules(ctx, "")
+			if err != nil {
+				return err
+			}
+			var strategies []string
+			for _, rule := range all {
+				if rule.Enabled && !slices.Contains(strategies, rule.Strategy) {
+					strategies = append(strategies, rule.Strategy)
+				}
+			}
+
+			to := time.Now().UTC()
+			from := to.Add(-window)
+
+			var errs []error
+			for _, strategy := range strategies {
+				for _, instrumentUID := range watchlist {
+					evaluation, err := tinkoff.EvaluateRules(ctx, strategy, models.GetCandlesRequest{
+						InstrumentId: instrumentUID,
+						Interval:     interval,
+						From:         from.Format(time.RFC3339),
+						To:           to.Format(time.RFC3339),
+					})
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					if err != nil {
+						errs = append(errs, fmt.Errorf("%s %s: %w", strategy, instrumentUID, err))
+						continue
+					}
+					log.Printf("Rules %s %s: %s %s", strategy, evaluation.Ticker, evaluation.Action, evaluation.Rule)
+				}
+			}
+			return errors.Join(errs...)
+		},
+	}, nil
+}
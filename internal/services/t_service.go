@@ -1,34 +1,72 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"mamonolitmvp/config"
+	"mamonolitmvp/internal/math/coefficients_calculation"
+	"mamonolitmvp/internal/math/correlation_analysis"
 	"mamonolitmvp/internal/math/price_analysis"
 	"mamonolitmvp/internal/models"
 	"mamonolitmvp/internal/repository"
 	"mamonolitmvp/pkg/http_client"
-)
+	"time"
 
-const ChunkSize = 524288
+	"golang.org/x/time/rate"
+)
 
 type TinkoffService struct {
 	Client *http_client.HTTPClient
 	Config *config.Config
 	is     *InstrumentService
 	pa     *price_analysis.PriceAnalysis
+	rm     *coefficients_calculation.RiskMetrics
+	ca     *correlation_analysis.CorrelationAnalysis
+	fr     *coefficients_calculation.FinancialRatios
+
+	candlesHooks []CandlesHook
 }
 
+// CandlesHook вызывается после сохранения новых свечей инструмента; не должен блокировать загрузку
+type CandlesHook func(instrumentUID, interval string)
+
 func NewTinkoffService(cfg *config.Config, repo *repository.InstrumentRepository) *TinkoffService {
+	pa := price_analysis.NewPriceAnalysis()
+	pa.RSIPeriod = cfg.RSIInterval
+
+	client := http_client.NewHTTPClient()
+	client.Retry.MaxRetries = cfg.HTTPMaxRetries
+	client.Client.Timeout = time.Duration(cfg.HTTPTimeoutSeconds) * time.Second
+	client.DefaultLimit = rate.Limit(float64(cfg.TinkoffRateLimit) / 60)
+
 	return &TinkoffService{
-		Client: http_client.NewHTTPClient(),
+		Client: client,
 		Config: cfg,
 		is:     NewInstrumentService(repo),
-		pa:     price_analysis.NewPriceAnalysis(),
+		pa:     pa,
+		rm:     coefficients_calculation.NewRiskMetrics(),
+		ca:     correlation_analysis.NewCorrelationAnalysis(),
+		fr:     coefficients_calculation.NewFinancialRatios(),
+	}
+}
+
+// OnCandlesStored регистрирует hook новых свечей; регистрировать до запуска загрузок и потока
+func (s *TinkoffService) OnCandlesStored(hook CandlesHook) {
+	s.candlesHooks = append(s.candlesHooks, hook)
+}
+
+// candlesStored сообщает hook'ам о новых свечах; обновления уже сохраненных свечей не в счет
+func (s *TinkoffService) candlesStored(instrumentUID, interval string, stats models.UpsertStats) {
+	if stats.Inserted == 0 {
+		return
+	}
+	for _, hook := range s.candlesHooks {
+		hook(instrumentUID, interval)
 	}
 }
 
-func (s *TinkoffService) GetClosePrices(instruments []string) ([]models.ClosePrice, error) {
+func (s *TinkoffService) GetClosePrices(ctx context.Context, instruments []string) ([]models.ClosePrice, error) {
 	var InstrumentRequests []models.InstrumentRequest
 	for _, instrument := range instruments {
 		InstrumentRequests = append(InstrumentRequests, models.InstrumentRequest{InstrumentID: instrument})
@@ -42,7 +80,7 @@ func (s *TinkoffService) GetClosePrices(instruments []string) ([]models.ClosePri
 		"Content-Type":  "application/json",
 	}
 
-	respBody, err := s.Client.Post(url, headers, reqBody)
+	respBody, err := s.Client.Post(ctx, url, headers, reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -54,68 +92,7 @@ func (s *TinkoffService) GetClosePrices(instruments []string) ([]models.ClosePri
 	return response.ClosePrices, nil
 }
 
-func (s *TinkoffService) GetAllInstruments(instrumentStatus string) ([]models.PlacementPrice, error) {
-	reqBody := models.BondsRequest{InstrumentStatus: instrumentStatus}
-	url := fmt.Sprintf("%s/tinkoff.public.invest.api.contract.v1.InstrumentsService/Shares", s.Config.APIBaseURL)
-
-	headers := map[string]string{
-		"Authorization": "Bearer " + s.Config.APIToken,
-		"Content-Type":  "application/json",
-	}
-
-	respBody, err := s.Client.Post(url, headers, reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	var response models.GetBondsResponse
-	if err := json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	err = s.is.CreateInstruments(response.Instruments)
-	if err != nil {
-		return nil, err
-	}
-
-	return response.Instruments, nil
-}
-
-func (s *TinkoffService) GetCandles(instrumentInfo map[string]any) ([]models.HistoricCandle, error) {
-	reqBody := models.GetCandlesRequest{
-		Figi:         instrumentInfo["figi"].(string),
-		From:         instrumentInfo["from"].(string),
-		To:           instrumentInfo["to"].(string),
-		Interval:     instrumentInfo["interval"].(string),
-		InstrumentId: instrumentInfo["instrumentId"].(string),
-	}
-
-	url := fmt.Sprintf("%s/tinkoff.public.invest.api.contract.v1.MarketDataService/GetCandles", s.Config.APIBaseURL)
-
-	headers := map[string]string{
-		"Authorization": "Bearer " + s.Config.APIToken,
-		"Content-Type":  "application/json",
-	}
-
-	respBody, err := s.Client.Post(url, headers, reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	response, _, err := s.fixeRespBody(respBody, reqBody.InstrumentId)
-	if err != nil {
-		return nil, err
-	}
-
-	err = s.is.CreateCandles(response.Candles)
-	if err != nil {
-		return nil, err
-	}
-
-	return response.Candles, nil
-}
-
-func (s *TinkoffService) fixeRespBody(respBody []byte, instrumentID string) (models.GetCandlesResponse, []byte, error) {
+func (s *TinkoffService) fixeRespBody(respBody []byte, instrumentID, interval string) (models.GetCandlesResponse, []byte, error) {
 	var responce models.GetCandlesResponse
 	err := json.Unmarshal(respBody, &responce)
 	if err != nil {
@@ -124,6 +101,7 @@ func (s *TinkoffService) fixeRespBody(respBody []byte, instrumentID string) (mod
 
 	for i := range responce.Candles {
 		responce.Candles[i].InstrumentId = instrumentID
+		responce.Candles[i].Interval = interval
 	}
 
 	data, err := json.Marshal(responce)
@@ -0,0 +1,198 @@
+//go:build integration
+
+// Интеграционные тесты миграций на настоящем Postgres: go test -tags integration ./internal/storage/timescale.
+// База поднимается через testdb: embedded-postgres или TEST_POSTGRES_DSN.
+// Миграции свечей проверяются без TimescaleDB: candles остается обычной таблицей.
+package timescale
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/models"
+	"mamonolitmvp/internal/testdb"
+
+	"gorm.io/gorm"
+)
+
+var testDB *gorm.DB
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Run(m, "migrate-pg", func(db *gorm.DB) error {
+		testDB = db
+		return nil
+	}))
+}
+
+// legacy таблицы свечей в старом формате: ключ без интервала, цены units/nano в отдельных таблицах
+type (
+	legacyCandle struct {
+		InstrumentId string    `gorm:"primaryKey;size:255"`
+		Time         time.Time `gorm:"primaryKey"`
+		Volume       string
+	}
+	legacyPrice struct {
+		InstrumentId string    `gorm:"primaryKey;size:255"`
+		Time         time.Time `gorm:"primaryKey"`
+		Units        string
+		Nano         int
+	}
+)
+
+var legacyStart = time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+
+// createLegacyCandles пустые legacy таблицы, candles и покрытие
+func createLegacyCandles(t *testing.T) {
+	t.Helper()
+	for _, table := range append([]string{"candles", "candle_coverages"}, legacyCandleTables...) {
+		if err := testDB.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", table)).Error; err != nil {
+			t.Fatalf("drop %s: %v", table, err)
+		}
+	}
+	if err := testDB.Table(legacyCandleTables[0]).AutoMigrate(&legacyCandle{}); err != nil {
+		t.Fatalf("create %s: %v", legacyCandleTables[0], err)
+	}
+	for _, table := range legacyCandleTables[1:] {
+		if err := testDB.Table(table).AutoMigrate(&legacyPrice{}); err != nil {
+			t.Fatalf("create %s: %v", table, err)
+		}
+	}
+	if err := testDB.AutoMigrate(&models.Candle{}, &models.CandleCoverage{}); err != nil {
+		t.Fatalf("create candles: %v", err)
+	}
+}
+
+// insertLegacy свечи инструмента с ценой закрытия 100 + i в старом формате
+func insertLegacy(t *testing.T, instrumentUID string, times ...time.Time) {
+	t.Helper()
+	for i, ts := range times {
+		err := testDB.Table(legacyCandleTables[0]).Create(&legacyCandle{InstrumentId: instrumentUID, Time: ts, Volume: "10"}).Error
+		for _, table := range legacyCandleTables[1:] {
+			if err != nil {
+				break
+			}
+			err = testDB.Table(table).Create(&legacyPrice{InstrumentId: instrumentUID, Time: ts, Units: fmt.Sprint(100 + i), Nano: 500000000}).Error
+		}
+		if err != nil {
+			t.Fatalf("insert legacy candle: %v", err)
+		}
+	}
+}
+
+// steps моменты start, start+offsets[0], ...
+func steps(start time.Time, offsets ...time.Duration) []time.Time {
+	times := []time.Time{start}
+	for _, offset := range offsets {
+		times = append(times, start.Add(offset))
+	}
+	return times
+}
+
+func candleIntervals(t *testing.T, table string) map[string]map[string]int {
+	t.Helper()
+	var rows []struct {
+		InstrumentId string
+		Interval     string
+		Count        int
+	}
+	err := testDB.Raw(fmt.Sprintf(`SELECT instrument_id, interval, COUNT(*) AS count FROM %s GROUP BY instrument_id, interval`, table)).
+		Scan(&rows).Error
+	if err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	result := make(map[string]map[string]int)
+	for _, row := range rows {
+		if result[row.InstrumentId] == nil {
+			result[row.InstrumentId] = make(map[string]int)
+		}
+		result[row.InstrumentId][row.Interval] = row.Count
+	}
+	return result
+}
+
+func TestMigrateCandleIntervalInfersSpacing(t *testing.T) {
+	createLegacyCandles(t)
+
+	// Часовые свечи с ночным перерывом, дневные с выходными, месячные, одна свеча и свечи с шагом 7 минут
+	insertLegacy(t, "hourly", steps(legacyStart, time.Hour, 2*time.Hour, 17*time.Hour, 18*time.Hour)...)
+	insertLegacy(t, "daily", steps(legacyStart, 24*time.Hour, 4*24*time.Hour, 5*24*time.Hour)...)
+	insertLegacy(t, "monthly", legacyStart, legacyStart.AddDate(0, 1, 0), legacyStart.AddDate(0, 2, 0), legacyStart.AddDate(0, 3, 0))
+	insertLegacy(t, "single", legacyStart)
+	insertLegacy(t, "odd", steps(legacyStart, 7*time.Minute, 14*time.Minute)...)
+	err := testDB.Create(&models.CandleCoverage{
+		InstrumentId: "hourly", Interval: "CANDLE_INTERVAL_HOUR", From: legacyStart, To: legacyStart.Add(time.Hour),
+	}).Error
+	if err != nil {
+		t.Fatalf("insert coverage: %v", err)
+	}
+
+	if err := migrateCandleInterval(testDB); err != nil {
+		t.Fatalf("migrateCandleInterval: %v", err)
+	}
+
+	want := map[string]map[string]int{
+		"hourly":  {"CANDLE_INTERVAL_HOUR": 5},
+		"daily":   {"CANDLE_INTERVAL_DAY": 4},
+		"monthly": {"CANDLE_INTERVAL_MONTH": 4},
+	}
+	for _, table := range legacyCandleTables {
+		got := candleIntervals(t, table)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s intervals = %v, want %v without unspecified candles", table, got, want)
+		}
+	}
+
+	var coverage int64
+	testDB.Model(&models.CandleCoverage{}).Count(&coverage)
+	if coverage != 0 {
+		t.Errorf("coverage rows = %d, want reset", coverage)
+	}
+
+	if err := migrateLegacyCandles(testDB); err != nil {
+		t.Fatalf("migrateLegacyCandles: %v", err)
+	}
+	if got := candleIntervals(t, "candles"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("candles intervals = %v, want %v", got, want)
+	}
+	var candle models.Candle
+	if err := testDB.Where("instrument_id = ? AND time = ?", "daily", legacyStart.Add(24*time.Hour)).First(&candle).Error; err != nil {
+		t.Fatalf("moved candle: %v", err)
+	}
+	if candle.Interval != "CANDLE_INTERVAL_DAY" || candle.Close != 101.5 || candle.Volume != 10 {
+		t.Errorf("moved candle = %+v", candle)
+	}
+}
+
+func TestMigrateUnspecifiedCandles(t *testing.T) {
+	createLegacyCandles(t)
+
+	candles := []models.Candle{
+		{InstrumentId: "known", Interval: "CANDLE_INTERVAL_HOUR", Time: legacyStart, Close: 1},
+	}
+	for _, ts := range steps(legacyStart, 15*time.Minute, 45*time.Minute) {
+		candles = append(candles, models.Candle{InstrumentId: "quarter", Interval: unspecifiedInterval, Time: ts, Close: 1})
+	}
+	candles = append(candles, models.Candle{InstrumentId: "single", Interval: unspecifiedInterval, Time: legacyStart, Close: 1})
+	if err := testDB.Create(&candles).Error; err != nil {
+		t.Fatalf("insert candles: %v", err)
+	}
+
+	if err := migrateUnspecifiedCandles(testDB); err != nil {
+		t.Fatalf("migrateUnspecifiedCandles: %v", err)
+	}
+
+	want := map[string]map[string]int{
+		"known":   {"CANDLE_INTERVAL_HOUR": 1},
+		"quarter": {"CANDLE_INTERVAL_15_MIN": 3},
+	}
+	if got := candleIntervals(t, "candles"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("candles intervals = %v, want %v", got, want)
+	}
+
+	// Повторный запуск ничего не меняет
+	if err := migrateUnspecifiedCandles(testDB); err != nil {
+		t.Fatalf("second migrateUnspecifiedCandles: %v", err)
+	}
+}
@@ -34,25 +34,394 @@ func InitDB(host, user, password, dBname, port string) (*gorm.DB, error) {
 }
 
 func migrate(db *gorm.DB) {
-	err := db.AutoMigrate(&models.HistoricCandle{}, &models.High{}, &models.Low{}, &models.Close{}, &models.Open{})
+	err := migrateCandleInterval(db)
+	if err != nil {
+		log.Printf("error migrate candle interval: %v", err)
+	}
+
+	err = db.AutoMigrate(&models.Candle{})
 	if err != nil {
 		log.Println("error migrate Candle table")
 	}
 
-	err = db.AutoMigrate(&models.PlacementPrice{})
+	err = createCandleHypertable(db)
+	if err != nil {
+		log.Printf("error create candle hypertable: %v", err)
+	}
+
+	err = migrateLegacyCandles(db)
+	if err != nil {
+		log.Printf("error migrate legacy candle tables: %v", err)
+	}
+
+	err = migrateUnspecifiedCandles(db)
+	if err != nil {
+		log.Printf("error migrate unspecified candles: %v", err)
+	}
+
+	err = db.AutoMigrate(&models.Instrument{}, &models.ShareDetails{}, &models.BondDetails{},
+		&models.EtfDetails{}, &models.CurrencyDetails{}, &models.FutureDetails{})
+	if err != nil {
+		log.Println("error migrate instrument catalogue tables")
+	}
+
+	err = migratePlacementPrices(db)
+	if err != nil {
+		log.Printf("error migrate placementPrice table: %v", err)
+	}
+
+	err = db.AutoMigrate(&models.CandleCoverage{})
+	if err != nil {
+		log.Println("error migrate candleCoverage table")
+	}
+
+	err = db.AutoMigrate(&models.BackfillCheckpoint{})
+	if err != nil {
+		log.Println("error migrate backfillCheckpoint table")
+	}
+
+	err = db.AutoMigrate(&models.AssetFundamental{})
+	if err != nil {
+		log.Println("error migrate assetFundamental table")
+	}
+
+	err = createFundamentalHypertable(db)
+	if err != nil {
+		log.Printf("error create assetFundamental hypertable: %v", err)
+	}
+
+	err = db.AutoMigrate(&models.JobState{})
 	if err != nil {
-		log.Println("error migrate placementPrice table")
+		log.Println("error migrate jobState table")
 	}
 
-	err = db.AutoMigrate(&models.Nominal{})
+	err = migrateRuleEvaluationKey(db)
 	if err != nil {
-		log.Println("error migrate nominal table")
+		log.Printf("error migrate rule evaluation key: %v", err)
 	}
 
-	err = db.AutoMigrate(&models.MinPriceIncrement{})
+	err = db.AutoMigrate(&models.StrategyRule{}, &models.RuleEvaluation{})
 	if err != nil {
-		log.Println("error migrate minPriceIncrement table")
+		log.Println("error migrate strategy rule tables")
+	}
+
+	err = db.AutoMigrate(&models.Alert{}, &models.AlertEvent{})
+	if err != nil {
+		log.Println("error migrate alert tables")
 	}
 
 	log.Println("Success connect to Postgres")
 }
+
+const (
+	candleChunkInterval    = "7 days"
+	candleCompressionAfter = "30 days"
+
+	// fundamentalChunkInterval показатели загружаются не чаще раза в день, чанк покрывает квартал
+	fundamentalChunkInterval = "90 days"
+)
+
+// legacyCandleTables таблицы старого формата хранения: свеча и четыре таблицы цен units/nano
+var legacyCandleTables = []string{"historic_candles", "highs", "lows", "closes", "opens"}
+
+// unspecifiedInterval интервал свечей, сохраненных до появления интервала в ключе
+const unspecifiedInterval = "CANDLE_INTERVAL_UNSPECIFIED"
+
+// candleSpacing шаг между соседними свечами интервала. Месячные свечи идут с шагом от 28 до 31 дня.
+var candleSpacing = []struct {
+	interval string
+	min, max string
+}{
+	{"CANDLE_INTERVAL_1_MIN", "1 minute", "1 minute"},
+	{"CANDLE_INTERVAL_2_MIN", "2 minutes", "2 minutes"},
+	{"CANDLE_INTERVAL_3_MIN", "3 minutes", "3 minutes"},
+	{"CANDLE_INTERVAL_5_MIN", "5 minutes", "5 minutes"},
+	{"CANDLE_INTERVAL_10_MIN", "10 minutes", "10 minutes"},
+	{"CANDLE_INTERVAL_15_MIN", "15 minutes", "15 minutes"},
+	{"CANDLE_INTERVAL_30_MIN", "30 minutes", "30 minutes"},
+	{"CANDLE_INTERVAL_HOUR", "1 hour", "1 hour"},
+	{"CANDLE_INTERVAL_2_HOUR", "2 hours", "2 hours"},
+	{"CANDLE_INTERVAL_4_HOUR", "4 hours", "4 hours"},
+	{"CANDLE_INTERVAL_DAY", "1 day", "1 day"},
+	{"CANDLE_INTERVAL_WEEK", "7 days", "7 days"},
+	{"CANDLE_INTERVAL_MONTH", "28 days", "31 days"},
+}
+
+// migrateCandleInterval добавляет interval в первичный ключ существующих таблиц свечей.
+// Интервал старых свечей определяется по шагу между ними (inferCandleInterval); свечи, для которых
+// шаг не определить, удаляются, а не остаются с CANDLE_INTERVAL_UNSPECIFIED, который никто не читает.
+// Покрытие и checkpoint'ы сбрасываются, чтобы удаленные и пропущенные свечи были загружены заново.
+func migrateCandleInterval(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(legacyCandleTables[0]) || migrator.HasColumn(legacyCandleTables[0], "interval") {
+		return nil
+	}
+
+	log.Println("Migrate candle tables to interval key")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range legacyCandleTables {
+			if !tx.Migrator().HasTable(table) {
+				continue
+			}
+
+			queries := []string{
+				fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS interval VARCHAR(50) NOT NULL DEFAULT '%s'`, table, unspecifiedInterval),
+				fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN interval DROP DEFAULT`, table),
+				fmt.Sprintf(`ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s_pkey CASCADE`, table, table),
+				fmt.Sprintf(`ALTER TABLE %s ADD PRIMARY KEY (instrument_id, interval, time)`, table),
+			}
+			for _, query := range queries {
+				if err := tx.Exec(query).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// Цены берут интервал своей свечи, чтобы legacy таблицы по-прежнему соединялись по ключу
+		if err := inferCandleInterval(tx, legacyCandleTables[0]); err != nil {
+			return err
+		}
+		for _, table := range legacyCandleTables[1:] {
+			if !tx.Migrator().HasTable(table) {
+				continue
+			}
+			err := tx.Exec(fmt.Sprintf(`UPDATE %[1]s p SET interval = c.interval FROM %[2]s c
+				WHERE p.instrument_id = c.instrument_id AND p.time = c.time AND p.interval = ?`, table, legacyCandleTables[0]),
+				unspecifiedInterval).Error
+			if err != nil {
+				return err
+			}
+			if err := deleteUnspecifiedCandles(tx, table); err != nil {
+				return err
+			}
+		}
+
+		for _, model := range []any{&models.CandleCoverage{}, &models.BackfillCheckpoint{}} {
+			if !tx.Migrator().HasTable(model) {
+				continue
+			}
+			if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateUnspecifiedCandles определяет интервал свечей CANDLE_INTERVAL_UNSPECIFIED, которые уже попали в candles
+// из legacy таблиц до inferCandleInterval, и удаляет те, для которых его не определить
+func migrateUnspecifiedCandles(db *gorm.DB) error {
+	var found bool
+	err := db.Raw(`SELECT EXISTS (SELECT 1 FROM candles WHERE interval = ?)`, unspecifiedInterval).Scan(&found).Error
+	if err != nil || !found {
+		return err
+	}
+
+	log.Println("Infer interval of unspecified candles")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		return inferCandleInterval(tx, "candles")
+	})
+}
+
+// inferCandleInterval проставляет свечам CANDLE_INTERVAL_UNSPECIFIED интервал по наименьшему шагу между
+// соседними свечами инструмента: пропуски торгов шаг только увеличивают. Свечи инструментов с одной свечой
+// или с шагом, не совпадающим ни с одним интервалом, удаляются. Раньше в ключе не было интервала,
+// поэтому у инструмента хранились свечи одного интервала, и шаг определяет его однозначно.
+func inferCandleInterval(tx *gorm.DB, table string) error {
+	inferred := "CASE"
+	for _, spacing := range candleSpacing {
+		inferred += fmt.Sprintf(` WHEN gap BETWEEN INTERVAL '%s' AND INTERVAL '%s' THEN '%s'`, spacing.min, spacing.max, spacing.interval)
+	}
+	inferred += " END"
+
+	result := tx.Exec(fmt.Sprintf(`
+		WITH spacing AS (
+			SELECT instrument_id, %[2]s AS interval
+			FROM (
+				SELECT instrument_id, MIN(gap) AS gap
+				FROM (
+					SELECT instrument_id, time - LAG(time) OVER (PARTITION BY instrument_id ORDER BY time) AS gap
+					FROM %[1]s WHERE interval = @unspecified
+				) gaps
+				WHERE gap > INTERVAL '0'
+				GROUP BY instrument_id
+			) steps
+		)
+		UPDATE %[1]s t SET interval = s.interval
+		FROM spacing s
+		WHERE t.instrument_id = s.instrument_id AND t.interval = @unspecified AND s.interval IS NOT NULL`, table, inferred),
+		map[string]any{"unspecified": unspecifiedInterval})
+	if result.Error != nil {
+		return result.Error
+	}
+	log.Printf("Inferred interval of %d candles in %s", result.RowsAffected, table)
+
+	return deleteUnspecifiedCandles(tx, table)
+}
+
+// deleteUnspecifiedCandles удаляет свечи, интервал которых не определен
+func deleteUnspecifiedCandles(tx *gorm.DB, table string) error {
+	result := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE interval = ?`, table), unspecifiedInterval)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Deleted %d candles of unknown interval from %s", result.RowsAffected, table)
+	}
+	return nil
+}
+
+// migrateRuleEvaluationKey добавляет interval в первичный ключ rule_evaluations: раньше решение
+// по другому интервалу того же инструмента перезаписывало предыдущее. AutoMigrate первичный ключ не меняет.
+func migrateRuleEvaluationKey(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.RuleEvaluation{}) {
+		return nil
+	}
+
+	var keyed bool
+	err := db.Raw(`SELECT EXISTS (
+		SELECT 1 FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indrelid = 'rule_evaluations'::regclass AND i.indisprimary AND a.attname = 'interval')`).
+		Scan(&keyed).Error
+	if err != nil || keyed {
+		return err
+	}
+
+	log.Println("Migrate rule_evaluations to interval key")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		queries := []string{
+			`UPDATE rule_evaluations SET interval = '' WHERE interval IS NULL`,
+			`ALTER TABLE rule_evaluations DROP CONSTRAINT IF EXISTS rule_evaluations_pkey`,
+			`ALTER TABLE rule_evaluations ADD PRIMARY KEY (strategy, instrument_id, interval)`,
+		}
+		for _, query := range queries {
+			if err := tx.Exec(query).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migratePlacementPrices переносит акции из placement_prices в каталог инструментов и удаляет
+// старые таблицы; номинал и шаг цены раньше хранились в отдельных таблицах nominals и min_price_increments
+func migratePlacementPrices(db *gorm.DB) error {
+	err := db.Migrator().DropTable("nominals", "min_price_increments")
+	if err != nil || !db.Migrator().HasTable("placement_prices") {
+		return err
+	}
+
+	log.Println("Migrate placement_prices to instruments catalogue")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		queries := []string{
+			`INSERT INTO instruments (uid, figi, ticker, class_code, isin, name, instrument_type, lot, currency, exchange,
+				country_of_risk, sector, trading_status, min_price_increment_units, min_price_increment_nano,
+				api_trade_available_flag, buy_available_flag, sell_available_flag, short_enabled_flag, for_iis_flag,
+				for_qual_investor_flag, asset_uid, position_uid, first1min_candle_date, first1day_candle_date, updated_at)
+			SELECT uid, figi, ticker, class_code, isin, name, 'share', lot, currency, exchange,
+				country_of_risk, sector, trading_status, min_price_increment_units, min_price_increment_nano,
+				api_trade_available_flag, buy_available_flag, sell_available_flag, short_enabled_flag, for_iis_flag,
+				for_qual_investor_flag, asset_uid, position_uid, first1min_candle_date, first1day_candle_date, NOW()
+			FROM placement_prices
+			ON CONFLICT DO NOTHING`,
+			`INSERT INTO share_details (uid, share_type, issue_size, issue_size_plan, nominal_currency, nominal_units,
+				nominal_nano, div_yield_flag)
+			SELECT uid, share_type, issue_size, issue_size_plan, nominal_currency, nominal_units, nominal_nano, div_yield_flag
+			FROM placement_prices
+			ON CONFLICT DO NOTHING`,
+			`DROP TABLE placement_prices CASCADE`,
+		}
+		for _, query := range queries {
+			if err := tx.Exec(query).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// createCandleHypertable превращает candles в hypertable TimescaleDB и включает сжатие старых чанков
+func createCandleHypertable(db *gorm.DB) error {
+	queries := []string{
+		`CREATE EXTENSION IF NOT EXISTS timescaledb`,
+		fmt.Sprintf(`SELECT create_hypertable('candles', 'time', chunk_time_interval => INTERVAL '%s', if_not_exists => TRUE, migrate_data => TRUE)`, candleChunkInterval),
+	}
+	for _, query := range queries {
+		if err := db.Exec(query).Error; err != nil {
+			return err
+		}
+	}
+
+	var compressionEnabled bool
+	err := db.Raw(`SELECT compression_enabled FROM timescaledb_information.hypertables WHERE hypertable_name = 'candles'`).
+		Scan(&compressionEnabled).Error
+	if err != nil {
+		return err
+	}
+
+	if !compressionEnabled {
+		err = db.Exec(`ALTER TABLE candles SET (timescaledb.compress, timescaledb.compress_segmentby = 'instrument_id, interval', timescaledb.compress_orderby = 'time DESC')`).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return db.Exec(fmt.Sprintf(`SELECT add_compression_policy('candles', INTERVAL '%s', if_not_exists => TRUE)`, candleCompressionAfter)).Error
+}
+
+// createFundamentalHypertable превращает asset_fundamentals в hypertable TimescaleDB по времени загрузки
+func createFundamentalHypertable(db *gorm.DB) error {
+	queries := []string{
+		`CREATE EXTENSION IF NOT EXISTS timescaledb`,
+		fmt.Sprintf(`SELECT create_hypertable('asset_fundamentals', 'time', chunk_time_interval => INTERVAL '%s', if_not_exists => TRUE, migrate_data => TRUE)`, fundamentalChunkInterval),
+	}
+	for _, query := range queries {
+		if err := db.Exec(query).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateLegacyCandles переносит свечи из таблиц старого формата в candles и удаляет старые таблицы
+func migrateLegacyCandles(db *gorm.DB) error {
+	for _, table := range legacyCandleTables {
+		if !db.Migrator().HasTable(table) {
+			return nil
+		}
+	}
+
+	log.Println("Migrate legacy candle tables to candles hypertable")
+
+	price := func(alias string) string {
+		return fmt.Sprintf(`COALESCE(NULLIF(%[1]s.units, '')::NUMERIC, 0) + %[1]s.nano::NUMERIC / 1000000000`, alias)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(fmt.Sprintf(`
+			INSERT INTO candles (instrument_id, interval, time, open, high, low, close, volume)
+			SELECT c.instrument_id, c.interval, c.time, %s, %s, %s, %s, COALESCE(NULLIF(c.volume, '')::BIGINT, 0)
+			FROM historic_candles c
+			JOIN opens o USING (instrument_id, interval, time)
+			JOIN highs h USING (instrument_id, interval, time)
+			JOIN lows l USING (instrument_id, interval, time)
+			JOIN closes cl USING (instrument_id, interval, time)
+			ON CONFLICT DO NOTHING`, price("o"), price("h"), price("l"), price("cl")))
+		if result.Error != nil {
+			return result.Error
+		}
+		log.Printf("Moved %d legacy candles", result.RowsAffected)
+
+		for i := len(legacyCandleTables) - 1; i >= 0; i-- {
+			if err := tx.Exec(fmt.Sprintf(`DROP TABLE %s CASCADE`, legacyCandleTables[i])).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
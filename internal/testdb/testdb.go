@@ -0,0 +1,69 @@
+//go:build integration
+
+// Package testdb база Postgres для интеграционных тестов. По умолчанию поднимается embedded-postgres
+// (скачивает бинарники при первом запуске); TEST_POSTGRES_DSN подключает уже запущенную базу.
+package testdb
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Run поднимает базу, передает ее в setup и прогоняет тесты пакета. Вызывается из TestMain:
+// os.Exit(testdb.Run(m, "upsert-pg", setup)). name — префикс временного каталога embedded-postgres.
+func Run(m *testing.M, name string, setup func(db *gorm.DB) error) int {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	var pg *embeddedpostgres.EmbeddedPostgres
+	if dsn == "" {
+		port, err := freePort()
+		if err != nil {
+			log.Fatalf("free port: %v", err)
+		}
+		runtime, err := os.MkdirTemp("", name)
+		if err != nil {
+			log.Fatalf("runtime dir: %v", err)
+		}
+		defer os.RemoveAll(runtime)
+
+		pg = embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+			Port(port).RuntimePath(runtime).StartTimeout(time.Minute))
+		if err := pg.Start(); err != nil {
+			log.Fatalf("start embedded postgres: %v", err)
+		}
+		defer func() {
+			if err := pg.Stop(); err != nil {
+				log.Printf("stop embedded postgres: %v", err)
+			}
+		}()
+		dsn = fmt.Sprintf("host=localhost port=%d user=postgres password=postgres dbname=postgres sslmode=disable", port)
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err == nil {
+		err = setup(db)
+	}
+	if err != nil {
+		log.Printf("open test database: %v", err)
+		return 1
+	}
+
+	return m.Run()
+}
+
+func freePort() (uint32, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return uint32(l.Addr().(*net.TCPAddr).Port), nil
+}
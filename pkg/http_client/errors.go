@@ -0,0 +1,145 @@
+package http_client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mamonolitmvp/internal/apperr"
+	"net/http"
+	"time"
+)
+
+// gRPC коды, которые Tinkoff возвращает в поле code тела ошибки
+const (
+	grpcInvalidArgument   = 3
+	grpcNotFound          = 5
+	grpcPermissionDenied  = 7
+	grpcResourceExhausted = 8
+	grpcInternal          = 13
+	grpcUnavailable       = 14
+	grpcUnauthenticated   = 16
+)
+
+// APIError ответ API со статусом, отличным от 200. Разворачивается в вид ошибки из apperr,
+// поэтому проверять можно через errors.Is.
+type APIError struct {
+	StatusCode int
+	// Code gRPC код ошибки из тела ответа
+	Code int
+	// TinkoffCode код ошибки Tinkoff, например "40003"
+	TinkoffCode string
+	Message     string
+	Method      string
+	// RetryAfter время до сброса квоты из заголовков ответа
+	RetryAfter time.Duration
+	kind       error
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s: status %d", e.kind, e.StatusCode)
+	if e.Method != "" {
+		msg = e.Method + ": " + msg
+	}
+	if e.TinkoffCode != "" {
+		msg += ", code " + e.TinkoffCode
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// newAPIError разбирает тело ошибки Tinkoff {"code": 16, "message": "...", "description": "40003"}
+func newAPIError(method string, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     method,
+		Message:    resp.Header.Get("message"),
+		RetryAfter: rateLimitReset(resp.Header),
+	}
+
+	var payload struct {
+		Code        int    `json:"code"`
+		Message     string `json:"message"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		apiErr.Code = payload.Code
+		if payload.Message != "" {
+			apiErr.Message = payload.Message
+		}
+		apiErr.TinkoffCode = payload.Description
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	apiErr.kind = errorKind(resp.StatusCode, apiErr.Code)
+	return apiErr
+}
+
+func errorKind(status, code int) error {
+	switch code {
+	case grpcInvalidArgument:
+		return apperr.ErrInvalidArgument
+	case grpcNotFound:
+		return apperr.ErrNotFound
+	case grpcPermissionDenied:
+		return apperr.ErrPermissionDenied
+	case grpcResourceExhausted:
+		return apperr.ErrRateLimited
+	case grpcUnauthenticated:
+		return apperr.ErrUnauthenticated
+	case grpcUnavailable:
+		return apperr.ErrUnavailable
+	case grpcInternal:
+		return apperr.ErrInternal
+	}
+
+	switch {
+	case status == http.StatusBadRequest:
+		return apperr.ErrInvalidArgument
+	case status == http.StatusUnauthorized:
+		return apperr.ErrUnauthenticated
+	case status == http.StatusForbidden:
+		return apperr.ErrPermissionDenied
+	case status == http.StatusNotFound:
+		return apperr.ErrNotFound
+	case status == http.StatusTooManyRequests:
+		return apperr.ErrRateLimited
+	case status == http.StatusServiceUnavailable || status == http.StatusBadGateway || status == http.StatusGatewayTimeout:
+		return apperr.ErrUnavailable
+	}
+	return apperr.ErrInternal
+}
+
+// StatusCode HTTP статус ошибки API или 0
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// RetryAfter время, через которое запрос можно повторить, или 0
+func RetryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// TinkoffCode код ошибки Tinkoff или пустая строка
+func TinkoffCode(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.TinkoffCode
+	}
+	return ""
+}
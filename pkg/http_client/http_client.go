@@ -2,33 +2,88 @@ package http_client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"path"
+	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
+	"mamonolitmvp/internal/apperr"
 )
 
 type HTTPClient struct {
 	Client *http.Client
+	Retry  RetryPolicy
+
+	// DefaultLimit лимит запросов в секунду на метод API, если метод не указан в MethodLimits; 0 — без ограничения
+	DefaultLimit rate.Limit
+	DefaultBurst int
+	MethodLimits map[string]rate.Limit
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	// blockedUntil время сброса квоты метода, если сервер сообщил, что она исчерпана
+	blockedUntil map[string]time.Time
 }
 
+// DefaultTimeout таймаут одной попытки запроса
+const DefaultTimeout = 10 * time.Second
+
 func NewHTTPClient() *HTTPClient {
 	return &HTTPClient{
 		Client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: DefaultTimeout,
 		},
+		Retry:        DefaultRetryPolicy(),
+		DefaultBurst: 1,
+		limiters:     make(map[string]*rate.Limiter),
+		blockedUntil: make(map[string]time.Time),
 	}
 }
 
-func (h *HTTPClient) Post(url string, headers map[string]string, body any) ([]byte, error) {
+func (h *HTTPClient) Post(ctx context.Context, url string, headers map[string]string, body any) ([]byte, error) {
 	data, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(data))
+	method := methodName(url)
+	for attempt := 0; ; attempt++ {
+		if err := h.wait(ctx, method); err != nil {
+			return nil, err
+		}
+
+		respBody, err := h.post(ctx, url, method, headers, data)
+		if err == nil {
+			return respBody, nil
+		}
+
+		if attempt >= h.Retry.MaxRetries || !retryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		delay := h.Retry.Backoff(attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+			delay = apiErr.RetryAfter
+		}
+
+		log.Printf("%s: retry %d/%d in %s: %v", method, attempt+1, h.Retry.MaxRetries, delay, err)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (h *HTTPClient) post(ctx context.Context, url, method string, headers map[string]string, data []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
@@ -48,13 +103,122 @@ func (h *HTTPClient) Post(url string, headers map[string]string, body any) ([]by
 		}
 	}(resp.Body)
 
-	Rbody, _ := io.ReadAll(resp.Body)
-	// fmt.Printf("Response body: %s\n", Rbody)
+	h.observe(method, resp)
+
+	Rbody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
 
 	if resp.StatusCode != http.StatusOK {
-		log.Println(resp.Status)
-		return nil, errors.New("non-200 status code received")
+		apiErr := newAPIError(method, resp, Rbody)
+		log.Println(apiErr)
+		return nil, apiErr
 	}
 
 	return Rbody, nil
 }
+
+// wait ждет токен метода и, если квота метода исчерпана, ее сброса
+func (h *HTTPClient) wait(ctx context.Context, method string) error {
+	h.mu.Lock()
+	until := h.blockedUntil[method]
+	limiter := h.limiter(method)
+	h.mu.Unlock()
+
+	if d := time.Until(until); d > 0 {
+		log.Printf("%s: rate limit exhausted, wait %s", method, d)
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	if limiter == nil {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+// limiter token bucket метода; вызывается под h.mu
+func (h *HTTPClient) limiter(method string) *rate.Limiter {
+	if h.limiters == nil {
+		h.limiters = make(map[string]*rate.Limiter)
+	}
+	if limiter, ok := h.limiters[method]; ok {
+		return limiter
+	}
+
+	limit, ok := h.MethodLimits[method]
+	if !ok {
+		limit = h.DefaultLimit
+	}
+	if limit <= 0 {
+		h.limiters[method] = nil
+		return nil
+	}
+
+	burst := h.DefaultBurst
+	if burst <= 0 {
+		burst = 1
+	}
+	h.limiters[method] = rate.NewLimiter(limit, burst)
+	return h.limiters[method]
+}
+
+// observe запоминает время сброса квоты, если по заголовкам ответа она исчерпана
+func (h *HTTPClient) observe(method string, resp *http.Response) {
+	remaining, ok := rateLimitRemaining(resp.Header)
+	if !ok || remaining > 0 {
+		return
+	}
+
+	reset := rateLimitReset(resp.Header)
+	if reset <= 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.blockedUntil == nil {
+		h.blockedUntil = make(map[string]time.Time)
+	}
+	h.blockedUntil[method] = time.Now().Add(reset)
+}
+
+// sleep пауза, прерываемая отменой контекста
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryable повторяются 429 и 5xx, сетевые ошибки и таймаут попытки; отмена контекста проверяется отдельно.
+// Ошибки построения запроса и прочие локальные ошибки не повторяются: повтор дал бы тот же результат.
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return errors.Is(err, apperr.ErrRateLimited) || errors.Is(err, apperr.ErrUnavailable) || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	// *url.Error сам реализует net.Error, поэтому смотрим на ошибку внутри него
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// methodName последний сегмент пути, например "GetCandles"
+func methodName(url string) string {
+	return path.Base(url)
+}
@@ -0,0 +1,316 @@
+package http_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+	"mamonolitmvp/internal/apperr"
+)
+
+type testResponse struct {
+	status int
+	header map[string]string
+	body   string
+}
+
+// testServer отвечает по очереди ответами из responses, затем 200; возвращает счетчик запросов
+func testServer(t *testing.T, responses ...testResponse) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		resp := testResponse{status: http.StatusOK, body: `{"ok":true}`}
+		if n < len(responses) {
+			resp = responses[n]
+		}
+		for k, v := range resp.header {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(resp.status)
+		_, _ = w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func fastClient(maxRetries int) *HTTPClient {
+	client := NewHTTPClient()
+	client.Retry = RetryPolicy{MaxRetries: maxRetries, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	return client
+}
+
+func TestPostRetriesServerErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		responses  []testResponse
+		maxRetries int
+		wantCalls  int32
+		wantErr    error
+	}{
+		{
+			name:       "5xx then success",
+			responses:  []testResponse{{status: http.StatusInternalServerError}, {status: http.StatusBadGateway}},
+			maxRetries: 3,
+			wantCalls:  3,
+		},
+		{
+			name:       "429 then success",
+			responses:  []testResponse{{status: http.StatusTooManyRequests}},
+			maxRetries: 3,
+			wantCalls:  2,
+		},
+		{
+			name: "gives up after max retries",
+			responses: []testResponse{
+				{status: http.StatusServiceUnavailable}, {status: http.StatusServiceUnavailable},
+				{status: http.StatusServiceUnavailable}, {status: http.StatusServiceUnavailable},
+			},
+			maxRetries: 2,
+			wantCalls:  3,
+			wantErr:    apperr.ErrUnavailable,
+		},
+		{
+			name:       "no retries",
+			responses:  []testResponse{{status: http.StatusInternalServerError}},
+			maxRetries: 0,
+			wantCalls:  1,
+			wantErr:    apperr.ErrInternal,
+		},
+		{
+			name:       "client error is not retried",
+			responses:  []testResponse{{status: http.StatusBadRequest, body: `{"code":3,"message":"bad figi","description":"30014"}`}},
+			maxRetries: 3,
+			wantCalls:  1,
+			wantErr:    apperr.ErrInvalidArgument,
+		},
+		{
+			name:       "not found is not retried",
+			responses:  []testResponse{{status: http.StatusNotFound}},
+			maxRetries: 3,
+			wantCalls:  1,
+			wantErr:    apperr.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := testServer(t, tt.responses...)
+			body, err := fastClient(tt.maxRetries).Post(context.Background(), server.URL+"/GetCandles", nil, struct{}{})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil || string(body) != `{"ok":true}` {
+				t.Fatalf("Post = %q, %v", body, err)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPostHonoursRetryAfter(t *testing.T) {
+	for _, header := range []string{headerRetryAfter, headerRateLimitReset} {
+		t.Run(header, func(t *testing.T) {
+			server, calls := testServer(t, testResponse{
+				status: http.StatusTooManyRequests,
+				header: map[string]string{header: "1"},
+			})
+
+			start := time.Now()
+			if _, err := fastClient(3).Post(context.Background(), server.URL+"/GetCandles", nil, struct{}{}); err != nil {
+				t.Fatalf("Post: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed < time.Second {
+				t.Errorf("retried after %s, want at least the 1s the server asked for", elapsed)
+			}
+			if calls.Load() != 2 {
+				t.Errorf("calls = %d, want 2", calls.Load())
+			}
+		})
+	}
+}
+
+func TestPostRetryAfterRespectsContext(t *testing.T) {
+	server, _ := testServer(t, testResponse{
+		status: http.StatusTooManyRequests,
+		header: map[string]string{headerRetryAfter: "30"},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := fastClient(3).Post(ctx, server.URL+"/GetCandles", nil, struct{}{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("waited %s after the context expired", elapsed)
+	}
+}
+
+func TestPostWaitsForExhaustedQuota(t *testing.T) {
+	server, calls := testServer(t, testResponse{
+		status: http.StatusOK,
+		header: map[string]string{headerRateLimitRemaining: "0", headerRateLimitReset: "1"},
+		body:   `{}`,
+	})
+	client := fastClient(0)
+
+	if _, err := client.Post(context.Background(), server.URL+"/GetCandles", nil, struct{}{}); err != nil {
+		t.Fatalf("first Post: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.Post(context.Background(), server.URL+"/GetLastPrices", nil, struct{}{}); err != nil {
+		t.Fatalf("other method: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("other method waited %s for GetCandles quota", elapsed)
+	}
+
+	start = time.Now()
+	if _, err := client.Post(context.Background(), server.URL+"/GetCandles", nil, struct{}{}); err != nil {
+		t.Fatalf("second Post: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("second GetCandles after %s, want to wait for the quota reset", elapsed)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestPostPerMethodTokenBuckets(t *testing.T) {
+	server, _ := testServer(t)
+	client := fastClient(0)
+	client.DefaultLimit = rate.Every(200 * time.Millisecond)
+	client.MethodLimits = map[string]rate.Limit{"GetLastPrices": rate.Inf}
+
+	post := func(method string) time.Duration {
+		start := time.Now()
+		if _, err := client.Post(context.Background(), server.URL+"/"+method, nil, struct{}{}); err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		return time.Since(start)
+	}
+
+	// Первый запрос метода берет токен из полного ведра, следующие ждут пополнения
+	post("GetCandles")
+	start := time.Now()
+	post("GetCandles")
+	post("GetCandles")
+	if elapsed := time.Since(start); elapsed < 350*time.Millisecond {
+		t.Errorf("three GetCandles in %s, want the default limit of 5 per second", elapsed)
+	}
+
+	// У другого метода свое ведро
+	if d := post("GetTradingStatus"); d > 100*time.Millisecond {
+		t.Errorf("GetTradingStatus waited %s for GetCandles bucket", d)
+	}
+
+	// Метод из MethodLimits не ограничивается лимитом по умолчанию
+	start = time.Now()
+	for range 5 {
+		post("GetLastPrices")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("five GetLastPrices in %s, want no limit", elapsed)
+	}
+}
+
+func TestPostTokenBucketSharedAcrossGoroutines(t *testing.T) {
+	server, calls := testServer(t)
+	client := fastClient(0)
+	client.DefaultLimit = rate.Every(100 * time.Millisecond)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Post(context.Background(), server.URL+"/GetCandles", nil, struct{}{}); err != nil {
+				t.Errorf("Post: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("four concurrent requests in %s, want them spread by the 10 per second limit", elapsed)
+	}
+	if calls.Load() != 4 {
+		t.Errorf("calls = %d, want 4", calls.Load())
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", &APIError{StatusCode: http.StatusInternalServerError, kind: apperr.ErrInternal}, true},
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests, kind: apperr.ErrRateLimited}, true},
+		{"bad request", &APIError{StatusCode: http.StatusBadRequest, kind: apperr.ErrInvalidArgument}, false},
+		{"connection refused", &url.Error{Op: "Post", URL: "http://x", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{"attempt deadline", context.DeadlineExceeded, true},
+		{"unsupported scheme", &url.Error{Op: "Post", URL: "ftp://x", Err: errors.New(`unsupported protocol scheme "ftp"`)}, false},
+		{"decode", &json.SyntaxError{}, false},
+		{"canceled", context.Canceled, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.err); got != tt.want {
+				t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostRetriesAttemptTimeout(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := fastClient(2)
+	client.Client.Timeout = 20 * time.Millisecond
+	if _, err := client.Post(context.Background(), server.URL+"/GetCandles", nil, struct{}{}); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want the timed out attempt retried once", calls.Load())
+	}
+}
+
+func TestPostDoesNotRetryLocalErrors(t *testing.T) {
+	client := fastClient(3)
+	_, err := client.Post(context.Background(), "ftp://localhost/GetCandles", nil, struct{}{})
+	if err == nil {
+		t.Fatal("Post to an unsupported scheme succeeded")
+	}
+
+	_, err = client.Post(context.Background(), "http://localhost/GetCandles", nil, map[string]any{"bad": func() {}})
+	var unsupported *json.UnsupportedTypeError
+	if !errors.As(err, &unsupported) {
+		t.Errorf("err = %v, want the marshal error", err)
+	}
+}
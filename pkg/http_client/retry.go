@@ -0,0 +1,68 @@
+package http_client
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Заголовки, в которых Tinkoff сообщает остаток квоты метода и время до ее сброса в секундах
+const (
+	headerRateLimitRemaining = "X-Ratelimit-Remaining"
+	headerRateLimitReset     = "X-Ratelimit-Reset"
+	headerRetryAfter         = "Retry-After"
+)
+
+// RetryPolicy повтор запросов с экспоненциальной задержкой и джиттером
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// Backoff задержка перед повтором attempt (с нуля): BaseDelay * 2^attempt, не больше MaxDelay,
+// случайная в верхней половине интервала
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 0; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+func rateLimitRemaining(header http.Header) (int, bool) {
+	value, err := strconv.Atoi(header.Get(headerRateLimitRemaining))
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// rateLimitReset время до сброса квоты из x-ratelimit-reset или Retry-After
+func rateLimitReset(header http.Header) time.Duration {
+	for _, key := range []string{headerRateLimitReset, headerRetryAfter} {
+		seconds, err := strconv.Atoi(header.Get(key))
+		if err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 0
+}
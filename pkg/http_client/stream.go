@@ -0,0 +1,85 @@
+package http_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+// streamItem элемент серверного потока REST API: {"result": {...}} или {"error": {...}}
+type streamItem struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Stream открывает серверный поток и передает handle каждое сообщение, пока поток не закроется,
+// не отменится ctx или handle не вернет ошибку. Закрытие потока сервером возвращает io.EOF.
+// Повторов нет: переподключение остается вызывающему, которому нужно заново оформить подписки.
+func (h *HTTPClient) Stream(ctx context.Context, url string, headers map[string]string, body any, handle func(json.RawMessage) error) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	method := methodName(url)
+	if err := h.wait(ctx, method); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	// Таймаут Client ограничивает весь ответ, а поток открыт долго: живость потока проверяет вызывающий
+	client := &http.Client{Transport: h.Client.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	h.observe(method, resp)
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		apiErr := newAPIError(method, resp, respBody)
+		log.Println(apiErr)
+		return apiErr
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var item streamItem
+		if err := decoder.Decode(&item); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if item.Error != nil {
+			payload, _ := json.Marshal(item.Error)
+			apiErr := newAPIError(method, resp, payload)
+			log.Println(apiErr)
+			return apiErr
+		}
+		if len(item.Result) == 0 {
+			return fmt.Errorf("%s: stream item without result", method)
+		}
+
+		if err := handle(item.Result); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// ErrForbiddenAddress адрес получателя во внутренней сети сервера
+var ErrForbiddenAddress = errors.New("address is not allowed")
+
+// Служебные сети, которые не покрывают методы netip.Addr
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// Guard ограничивает адреса, куда уходят webhook: адрес задает пользователь API, поэтому без явного
+// разрешения нельзя обращаться к loopback, link-local, частным и служебным сетям сервера.
+// Проверяется и адрес в URL, и каждый IP, в который разрешилось имя хоста при соединении.
+type Guard struct {
+	hosts    map[string]bool
+	prefixes []netip.Prefix
+	resolver *net.Resolver
+	dialer   *net.Dialer
+}
+
+// NewGuard allow — разрешенные хосты и подсети CIDR, например hooks.internal или 10.1.0.0/16
+func NewGuard(allow []string) (*Guard, error) {
+	g := &Guard{
+		hosts:    make(map[string]bool),
+		resolver: net.DefaultResolver,
+		dialer:   &net.Dialer{},
+	}
+	for _, item := range allow {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.Contains(item, "/") {
+			prefix, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowed network %q: %w", item, err)
+			}
+			g.prefixes = append(g.prefixes, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(item); err == nil {
+			g.prefixes = append(g.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		g.hosts[strings.ToLower(item)] = true
+	}
+	return g, nil
+}
+
+// CheckURL проверяет адрес без обращения к DNS: схему http(s), literal IP и localhost.
+// Имена хостов, которые разрешаются во внутренние адреса, отсекает DialContext.
+func (g *Guard) CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: must be an http(s) URL", ErrForbiddenAddress)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if g.hosts[host] {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !g.allowed(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+	}
+	return nil
+}
+
+// DialContext соединяется только с разрешенными адресами. Имя хоста разрешается здесь же, и соединение
+// идет на проверенный IP, чтобы повторный ответ DNS не подменил адрес после проверки.
+func (g *Guard) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if g.hosts[strings.ToLower(strings.TrimSuffix(host, "."))] {
+		return g.dialer.DialContext(ctx, network, address)
+	}
+
+	addrs, err := g.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, addr := range addrs {
+		if !g.allowed(addr) {
+			lastErr = fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, host, addr.Unmap())
+			continue
+		}
+		conn, err := g.dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("%s: no addresses", host)
+	}
+	return nil, lastErr
+}
+
+// allowed публичный адрес или адрес из разрешенных подсетей
+func (g *Guard) allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range g.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGuardCheckURL(t *testing.T) {
+	guard, err := NewGuard([]string{"hooks.internal", "10.1.0.0/16", "192.168.5.7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://example.com/hook", true},
+		{"http://93.184.216.34:8080/hook", true},
+		{"ftp://example.com/hook", false},
+		{"http:///hook", false},
+		{"http://localhost:8080/hook", false},
+		{"http://LOCALHOST./hook", false},
+		{"http://api.localhost/hook", false},
+		{"http://127.0.0.1/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://[::ffff:127.0.0.1]/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://[fe80::1]/hook", false},
+		{"http://10.0.0.5/hook", false},
+		{"http://172.16.3.4/hook", false},
+		{"http://192.168.1.1/hook", false},
+		{"http://100.64.1.1/hook", false},
+		{"http://0.0.0.0/hook", false},
+		{"http://[fd00::1]/hook", false},
+		{"http://10.1.2.3/hook", true},
+		{"http://192.168.5.7/hook", true},
+		{"http://hooks.internal/hook", true},
+	}
+	for _, tt := range tests {
+		err := guard.CheckURL(tt.url)
+		if tt.allowed && err != nil {
+			t.Errorf("CheckURL(%q) = %v, want allowed", tt.url, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("CheckURL(%q) = %v, want ErrForbiddenAddress", tt.url, err)
+		}
+	}
+}
+
+func TestNewGuardInvalidNetwork(t *testing.T) {
+	if _, err := NewGuard([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatal("expected error for invalid CIDR")
+	}
+}
+
+func TestWebhookRefusesLoopbackAtDial(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls.Add(1) }))
+	defer server.Close()
+
+	guard, _ := NewGuard(nil)
+	// Send не вызывает CheckURL: адрес проверяется при соединении, и для IP, и для имени хоста
+	for _, target := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		err := NewWebhook(guard).Send(context.Background(), target, Message{Text: "x"})
+		if !errors.Is(err, ErrForbiddenAddress) {
+			t.Fatalf("Send to %s = %v, want ErrForbiddenAddress", target, err)
+		}
+	}
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("server got %d requests", n)
+	}
+
+	allowed, _ := NewGuard([]string{"127.0.0.0/8"})
+	if err := NewWebhook(allowed).Send(context.Background(), server.URL, Message{Text: "x"}); err != nil {
+		t.Fatalf("allowed loopback: %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("server got %d requests, want 1", n)
+	}
+}
+
+func TestWebhookErrorOmitsResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("internal secret page"))
+	}))
+	defer server.Close()
+
+	err := (&Webhook{Client: server.Client()}).Send(context.Background(), server.URL, Message{Text: "x"})
+	var status *StatusError
+	if !errors.As(err, &status) || status.StatusCode != http.StatusForbidden {
+		t.Fatalf("Send = %v, want StatusError 403", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Fatalf("error echoes response body: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Package notify отправка уведомлений: webhook, Telegram Bot API и SMTP
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Message уведомление; Fields дополняют текст для получателей, которые разбирают JSON
+type Message struct {
+	Subject string         `json:"subject"`
+	Text    string         `json:"text"`
+	Time    time.Time      `json:"time"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// Sink доставляет сообщение получателю: URL для webhook, chat_id для Telegram, адрес для SMTP
+type Sink interface {
+	Send(ctx context.Context, target string, msg Message) error
+}
+
+// TargetChecker проверяет получателя до сохранения оповещения
+type TargetChecker interface {
+	CheckTarget(target string) error
+}
+
+// Webhook отправляет сообщение JSON-ом методом POST; любой ответ кроме 2xx — ошибка
+type Webhook struct {
+	Client *http.Client
+	// Guard проверяет адрес получателя; nil — без ограничений
+	Guard *Guard
+}
+
+// NewWebhook соединяется только с адресами, которые пропускает guard; прокси из окружения не используется,
+// иначе проверялся бы адрес прокси, а не получателя
+func NewWebhook(guard *Guard) *Webhook {
+	return &Webhook{
+		Guard: guard,
+		Client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext:         guard.DialContext,
+				TLSHandshakeTimeout: 10 * time.Second,
+				MaxIdleConns:        10,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}
+}
+
+func (w *Webhook) CheckTarget(target string) error {
+	if w.Guard == nil {
+		return nil
+	}
+	return w.Guard.CheckURL(target)
+}
+
+func (w *Webhook) Send(ctx context.Context, target string, msg Message) error {
+	return postJSON(ctx, w.Client, target, msg, nil)
+}
+
+// StatusError ответ с кодом не 2xx. Тело ответа в текст ошибки не попадает: ошибка доставки уходит
+// в ответ API, а адрес webhook задает пользователь
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: status %d", e.URL, e.StatusCode)
+}
+
+// postJSON отправляет body и, если out не nil, разбирает ответ в out: для кода не 2xx — если тело
+// разбирается, вместе с *StatusError
+func postJSON(ctx context.Context, client *http.Client, url string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if out != nil {
+			_ = json.Unmarshal(respBody, out)
+		}
+		return &StatusError{URL: req.URL.Redacted(), StatusCode: resp.StatusCode}
+	}
+	if out != nil {
+		return json.Unmarshal(respBody, out)
+	}
+	return nil
+}
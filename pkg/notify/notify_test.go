@@ -0,0 +1,223 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"mamonolitmvp/internal/fakesink"
+	"mamonolitmvp/pkg/notify"
+)
+
+func testMessage() notify.Message {
+	return notify.Message{
+		Subject: "SBER: режим trending -> turbulent",
+		Text:    "Hurst 0.62\nPrice 270.5",
+		Time:    time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	sink := fakesink.NewWebhook()
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	webhook := &notify.Webhook{Client: server.Client()}
+	if err := webhook.Send(context.Background(), server.URL+"/alerts/1", testMessage()); err != nil {
+		t.Fatal(err)
+	}
+
+	received := sink.Received()
+	if len(received) != 1 {
+		t.Fatalf("received %d messages, want 1", len(received))
+	}
+	got := received[0]
+	if got.To != "/alerts/1" || got.Subject != testMessage().Subject || got.Text != testMessage().Text {
+		t.Fatalf("received %+v", got)
+	}
+}
+
+func TestWebhookNon2xx(t *testing.T) {
+	sink := fakesink.NewWebhook()
+	sink.FailStatus = http.StatusServiceUnavailable
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	err := (&notify.Webhook{Client: server.Client()}).Send(context.Background(), server.URL, testMessage())
+	var status *notify.StatusError
+	if !errors.As(err, &status) || status.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Send = %v, want StatusError 503", err)
+	}
+	if len(sink.Received()) != 0 {
+		t.Fatal("failed request must not be received")
+	}
+}
+
+func newTelegram(t *testing.T, token string) (*fakesink.Telegram, string) {
+	t.Helper()
+	sink := fakesink.NewTelegram(token)
+	server := httptest.NewServer(sink)
+	t.Cleanup(server.Close)
+	return sink, server.URL
+}
+
+func TestTelegramSend(t *testing.T) {
+	sink, url := newTelegram(t, "123:secret")
+
+	if err := notify.NewTelegram(url+"/", "123:secret").Send(context.Background(), "-100500", testMessage()); err != nil {
+		t.Fatal(err)
+	}
+
+	received := sink.Received()
+	if len(received) != 1 {
+		t.Fatalf("received %d messages, want 1", len(received))
+	}
+	want := testMessage().Subject + "\n" + testMessage().Text
+	if received[0].To != "-100500" || received[0].Text != want {
+		t.Fatalf("received %+v, want text %q", received[0], want)
+	}
+}
+
+func TestTelegramErrors(t *testing.T) {
+	_, url := newTelegram(t, "123:secret")
+
+	tests := []struct {
+		name  string
+		token string
+		chat  string
+		want  string
+	}{
+		{"bad token", "123:wrong", "1", "telegram sendMessage: 401 Unauthorized"},
+		{"empty chat", "123:secret", "", "telegram sendMessage: 400 Bad Request: chat_id is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := notify.NewTelegram(url, tt.token).Send(context.Background(), tt.chat, testMessage())
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("Send = %v, want %q", err, tt.want)
+			}
+			if strings.Contains(err.Error(), tt.token) {
+				t.Fatalf("error leaks token: %v", err)
+			}
+		})
+	}
+}
+
+func TestTelegramOkFalse(t *testing.T) {
+	// Bot API может ответить 200 с ok:false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 5"}`))
+	}))
+	defer server.Close()
+
+	err := notify.NewTelegram(server.URL, "123:secret").Send(context.Background(), "1", testMessage())
+	if err == nil || err.Error() != "telegram sendMessage: 429 Too Many Requests: retry after 5" {
+		t.Fatalf("Send = %v", err)
+	}
+}
+
+func TestTelegramRedactsTokenInTransportError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + l.Addr().String()
+	l.Close()
+
+	err = notify.NewTelegram(url, "123:secret").Send(context.Background(), "1", testMessage())
+	if err == nil {
+		t.Fatal("expected connection error")
+	}
+	if strings.Contains(err.Error(), "123:secret") || !strings.Contains(err.Error(), "/bot***/sendMessage") {
+		t.Fatalf("token is not redacted: %v", err)
+	}
+}
+
+func newSMTP(t *testing.T, configure func(*fakesink.SMTP)) (*fakesink.SMTP, string) {
+	t.Helper()
+	sink := fakesink.NewSMTP()
+	if configure != nil {
+		configure(sink)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = sink.Serve(l) }()
+	t.Cleanup(func() { l.Close() })
+	return sink, l.Addr().String()
+}
+
+func TestSMTPSend(t *testing.T) {
+	sink, addr := newSMTP(t, func(s *fakesink.SMTP) {
+		s.Username, s.Password = "bot", "pass"
+	})
+
+	smtp := notify.NewSMTP(addr, "alerts@example.com", "bot", "pass")
+	if err := smtp.Send(context.Background(), "trader@example.com", testMessage()); err != nil {
+		t.Fatal(err)
+	}
+
+	received := sink.Received()
+	if len(received) != 1 {
+		t.Fatalf("received %d messages, want 1", len(received))
+	}
+	got := received[0]
+	if got.To != "trader@example.com" || got.Subject != testMessage().Subject || got.Text != testMessage().Text {
+		t.Fatalf("received %+v", got)
+	}
+}
+
+func TestSMTPErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*fakesink.SMTP)
+		username  string
+		password  string
+		step      string
+		code      int
+	}{
+		{
+			name:      "rcpt rejected",
+			configure: func(s *fakesink.SMTP) { s.RejectRecipients = []string{"trader@example.com"} },
+			step:      "smtp rcpt to",
+			code:      550,
+		},
+		{
+			name:      "bad password",
+			configure: func(s *fakesink.SMTP) { s.Username, s.Password = "bot", "pass" },
+			username:  "bot",
+			password:  "wrong",
+			step:      "smtp auth",
+			code:      535,
+		},
+		{
+			name:      "auth required",
+			configure: func(s *fakesink.SMTP) { s.Username, s.Password = "bot", "pass" },
+			step:      "smtp mail from",
+			code:      530,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, addr := newSMTP(t, tt.configure)
+
+			smtp := notify.NewSMTP(addr, "alerts@example.com", tt.username, tt.password)
+			err := smtp.Send(context.Background(), "trader@example.com", testMessage())
+			var reply *textproto.Error
+			if !errors.As(err, &reply) || reply.Code != tt.code || !strings.HasPrefix(err.Error(), tt.step+":") {
+				t.Fatalf("Send = %v, want %s: %d", err, tt.step, tt.code)
+			}
+			if len(sink.Received()) != 0 {
+				t.Fatal("rejected message must not be received")
+			}
+		})
+	}
+}
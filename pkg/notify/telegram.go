@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultTelegramURL = "https://api.telegram.org"
+
+// Telegram отправляет сообщение методом sendMessage Bot API: POST {BaseURL}/bot{Token}/sendMessage
+type Telegram struct {
+	BaseURL string
+	Token   string
+	Client  *http.Client
+}
+
+func NewTelegram(baseURL, token string) *Telegram {
+	if baseURL == "" {
+		baseURL = DefaultTelegramURL
+	}
+	return &Telegram{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Token:   token,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type telegramSendMessage struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+// telegramResponse ответ Bot API: {"ok": true, "result": ...} или {"ok": false, "description": ...}
+type telegramResponse struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+func (t *Telegram) Send(ctx context.Context, target string, msg Message) error {
+	text := msg.Text
+	if msg.Subject != "" {
+		text = msg.Subject + "\n" + msg.Text
+	}
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.BaseURL, t.Token)
+	var resp telegramResponse
+	err := postJSON(ctx, t.Client, url, telegramSendMessage{
+		ChatID:                target,
+		Text:                  text,
+		DisableWebPagePreview: true,
+	}, &resp)
+	if err != nil && resp.Description == "" {
+		// Токен входит в путь запроса: в ошибку попадает только метод
+		return fmt.Errorf("telegram sendMessage: %w", redact(err, t.Token))
+	}
+	if err != nil || !resp.Ok {
+		return fmt.Errorf("telegram sendMessage: %d %s", resp.ErrorCode, resp.Description)
+	}
+	return nil
+}
+
+// redact убирает секрет из текста ошибки
+func redact(err error, secret string) error {
+	if secret == "" || !strings.Contains(err.Error(), secret) {
+		return err
+	}
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), secret, "***"))
+}
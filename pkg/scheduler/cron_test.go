@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func at(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestCronNext(t *testing.T) {
+	// 1 января 2025 — среда
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{name: "every minute", spec: "* * * * *", from: at(2025, 1, 1, 10, 7).Add(30 * time.Second), want: at(2025, 1, 1, 10, 8)},
+		{name: "minute step", spec: "*/15 * * * *", from: at(2025, 1, 1, 10, 7), want: at(2025, 1, 1, 10, 15)},
+		{name: "strictly after", spec: "5,10 * * * *", from: at(2025, 1, 1, 10, 5), want: at(2025, 1, 1, 10, 10)},
+		{name: "range with step", spec: "0 9-17/4 * * *", from: at(2025, 1, 1, 10, 0), want: at(2025, 1, 1, 13, 0)},
+		{name: "range with step wraps to next day", spec: "0 9-17/4 * * *", from: at(2025, 1, 1, 17, 0), want: at(2025, 1, 2, 9, 0)},
+		{name: "month step", spec: "0 0 1 */3 *", from: at(2025, 2, 10, 0, 0), want: at(2025, 4, 1, 0, 0)},
+		{name: "weekdays from saturday", spec: "30 2 * * 1-5", from: at(2025, 1, 4, 12, 0), want: at(2025, 1, 6, 2, 30)},
+		{name: "sunday as 0", spec: "0 0 * * 0", from: at(2025, 1, 1, 0, 0), want: at(2025, 1, 5, 0, 0)},
+		{name: "sunday as 7", spec: "0 0 * * 7", from: at(2025, 1, 1, 0, 0), want: at(2025, 1, 5, 0, 0)},
+		{name: "day of month skips short months", spec: "0 0 31 * *", from: at(2025, 2, 1, 0, 0), want: at(2025, 3, 31, 0, 0)},
+		{name: "leap day", spec: "0 12 29 2 *", from: at(2025, 3, 1, 0, 0), want: at(2028, 2, 29, 12, 0)},
+		// Ограничены и день месяца, и день недели: подходит 13-е число или любая пятница
+		{name: "dom or dow: friday first", spec: "0 0 13 * 5", from: at(2025, 1, 1, 0, 0), want: at(2025, 1, 3, 0, 0)},
+		{name: "dom or dow: next friday", spec: "0 0 13 * 5", from: at(2025, 1, 3, 0, 0), want: at(2025, 1, 10, 0, 0)},
+		{name: "dom or dow: 13th on monday", spec: "0 0 13 * 5", from: at(2025, 1, 11, 0, 0), want: at(2025, 1, 13, 0, 0)},
+		{name: "never", spec: "0 0 31 2 *", from: at(2025, 1, 1, 0, 0), want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.spec, err)
+			}
+			if got := c.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextConvertsToUTC(t *testing.T) {
+	c, err := ParseCron("0 3 * * *")
+	if err != nil {
+		t.Fatalf("ParseCron: %v", err)
+	}
+	msk := time.FixedZone("MSK", 3*60*60)
+	// 05:00 MSK — это 02:00 UTC
+	if got := c.Next(time.Date(2025, 1, 1, 5, 0, 0, 0, msk)); !got.Equal(at(2025, 1, 1, 3, 0)) {
+		t.Errorf("Next = %s, want 03:00 UTC", got)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-3 * * * *",
+		"a * * * *",
+		"1-b * * * *",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q): expected error", spec)
+		}
+	}
+}